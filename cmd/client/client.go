@@ -0,0 +1,563 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// NewCommand groups the subcommands that operate on loans through the HTTP
+// API of a running server.
+func NewCommand() *cobra.Command {
+	var (
+		server  string
+		token   string
+		output  string
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "loan",
+		Short: "Operate on loans through a running server",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case "table", "json", "yaml":
+			default:
+				return fmt.Errorf("unknown output %q, want table, json or yaml", output)
+			}
+			// the arguments were fine, so failures from here on are the server's
+			cmd.SilenceUsage = true
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&server, "server", envOr("LOAN_SERVER", "http://localhost:1323"), "base URL of the server, or $LOAN_SERVER")
+	// read at run time so a token from the environment isn't shown in --help
+	cmd.PersistentFlags().StringVar(&token, "token", "", "bearer token, or $LOAN_TOKEN")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", "table", "table, json or yaml")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "how long to wait for each request")
+
+	// run calls fn with a client for the flags and prints what it returns
+	run := func(cmd *cobra.Command, fn func(ctx context.Context, c *client.Client) (any, error)) error {
+		if token == "" {
+			token = os.Getenv("LOAN_TOKEN")
+		}
+		c := client.New(server, token, &http.Client{Timeout: timeout})
+		result, err := fn(cmd.Context(), c)
+		// commands that print their own output, such as export, return nothing
+		if err != nil || result == nil {
+			return err
+		}
+		return write(cmd.OutOrStdout(), output, result)
+	}
+
+	cmd.AddCommand(
+		newCreateCommand(run),
+		newApproveCommand(run),
+		newInvestCommand(run),
+		newDisburseCommand(run),
+		newGetCommand(run),
+		newListCommand(run),
+		newExportCommand(run),
+		newImportCommand(run),
+		newImportStatusCommand(run),
+	)
+	return cmd
+}
+
+type runFunc func(cmd *cobra.Command, fn func(ctx context.Context, c *client.Client) (any, error)) error
+
+func newCreateCommand(run runFunc) *cobra.Command {
+	var in client.CreateLoan
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Propose a loan",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				return c.CreateLoan(ctx, in)
+			})
+		},
+	}
+	cmd.Flags().Float64Var(&in.Principal, "principal", 0, "amount lent")
+	cmd.Flags().Float64Var(&in.Rate, "rate", 0, "interest rate paid by the borrower")
+	cmd.Flags().Float64Var(&in.ROI, "roi", 0, "return on investment paid to investors")
+	cmd.Flags().StringVar(&in.AgreementLink, "agreement-link", "", "link to the loan agreement")
+	cmd.Flags().IntVar(&in.TenorMonths, "tenor-months", 0, "repayment period, 12 when not set")
+	cmd.Flags().Int64Var(&in.BorrowerID, "borrower-id", 0, "borrower to propose for, when acting as an admin")
+	cmd.Flags().StringVar(&in.IdempotencyKey, "idempotency-key", "", "key that makes retries of this request safe")
+	for _, name := range []string{"principal", "rate", "roi", "agreement-link"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+func newApproveCommand(run runFunc) *cobra.Command {
+	var (
+		in         client.ApproveLoan
+		approvedAt string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "approve <id>",
+		Short: "Approve a proposed loan",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			if in.ApprovedAt, err = parseTime("approved-at", approvedAt); err != nil {
+				return err
+			}
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				return c.ApproveLoan(ctx, id, in)
+			})
+		},
+	}
+	cmd.Flags().Int64Var(&in.ProofDocumentID, "proof-document-id", 0, "uploaded approval proof")
+	cmd.Flags().StringVar(&approvedAt, "approved-at", "", "RFC 3339 approval time, now when not set")
+	cmd.Flags().StringVar(&in.IdempotencyKey, "idempotency-key", "", "key that makes retries of this request safe")
+	_ = cmd.MarkFlagRequired("proof-document-id")
+	return cmd
+}
+
+func newInvestCommand(run runFunc) *cobra.Command {
+	var in client.AddInvestment
+
+	cmd := &cobra.Command{
+		Use:   "invest <id>",
+		Short: "Invest in an approved loan",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				return c.AddInvestment(ctx, id, in)
+			})
+		},
+	}
+	cmd.Flags().Float64Var(&in.Amount, "amount", 0, "amount invested")
+	cmd.Flags().Int64Var(&in.InvestorID, "investor-id", 0, "investor to invest for, when acting as an admin")
+	cmd.Flags().StringVar(&in.IdempotencyKey, "idempotency-key", "", "key that makes retries of this request safe")
+	_ = cmd.MarkFlagRequired("amount")
+	return cmd
+}
+
+func newDisburseCommand(run runFunc) *cobra.Command {
+	var (
+		in          client.DisburseLoan
+		disbursedAt string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "disburse <id>",
+		Short: "Disburse a fully invested loan",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			if in.DisbursedAt, err = parseTime("disbursed-at", disbursedAt); err != nil {
+				return err
+			}
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				return c.DisburseLoan(ctx, id, in)
+			})
+		},
+	}
+	cmd.Flags().Int64Var(&in.AgreementDocumentID, "agreement-document-id", 0, "uploaded signed agreement")
+	cmd.Flags().StringVar(&disbursedAt, "disbursed-at", "", "RFC 3339 disbursement time, now when not set")
+	cmd.Flags().StringVar(&in.IdempotencyKey, "idempotency-key", "", "key that makes retries of this request safe")
+	_ = cmd.MarkFlagRequired("agreement-document-id")
+	return cmd
+}
+
+func newGetCommand(run runFunc) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <id>",
+		Short: "Show a loan",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				return c.GetLoan(ctx, id)
+			})
+		},
+	}
+}
+
+// loanList is what list prints: the loans and, unless every page was
+// fetched, the cursor of the next page.
+type loanList struct {
+	Loans      []*model.Loan `json:"loans"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+func newListCommand(run runFunc) *cobra.Command {
+	var (
+		in      client.ListLoans
+		filters filterFlags
+		all     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List loans",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := filters.apply(&in); err != nil {
+				return err
+			}
+
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				list := &loanList{Loans: []*model.Loan{}}
+				for {
+					page, err := c.ListLoans(ctx, in)
+					if err != nil {
+						return nil, err
+					}
+					list.Loans = append(list.Loans, page.Loans...)
+					list.NextCursor = page.NextCursor
+					if !all || page.NextCursor == "" {
+						return list, nil
+					}
+					in.Cursor = page.NextCursor
+				}
+			})
+		},
+	}
+	filters.register(cmd, &in)
+	cmd.Flags().StringVar(&in.Cursor, "cursor", "", "next_cursor of the previous page")
+	cmd.Flags().IntVar(&in.Limit, "limit", 0, "loans per page, at most 100")
+	cmd.Flags().BoolVar(&all, "all", false, "fetch every page")
+	return cmd
+}
+
+func newExportCommand(run runFunc) *cobra.Command {
+	var (
+		in      client.ExportLoans
+		filters filterFlags
+		format  string
+		out     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Download the loan book as CSV, NDJSON or Parquet",
+		Long: `Download every loan matching the filters: csv has a row per investment,
+ndjson a loan per line and parquet a row per loan with its investments as a
+list. The file is written to --out, or to standard output. A large loan book
+may take longer than --timeout; pass --timeout 0 to wait for all of it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in.Format = model.ExportFormat(format)
+			switch in.Format {
+			case model.ExportCSV, model.ExportNDJSON, model.ExportParquet:
+			default:
+				return fmt.Errorf("unknown format %q, want csv, ndjson or parquet", format)
+			}
+			if err := filters.apply(&in.ListLoans); err != nil {
+				return err
+			}
+
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				if out == "" {
+					return nil, c.ExportLoans(ctx, in, cmd.OutOrStdout())
+				}
+
+				file, err := os.Create(out)
+				if err != nil {
+					return nil, err
+				}
+				err = c.ExportLoans(ctx, in, file)
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+				if err != nil {
+					// don't leave a truncated export behind to be mistaken for a whole one
+					os.Remove(out)
+				}
+				return nil, err
+			})
+		},
+	}
+	filters.register(cmd, &in.ListLoans)
+	cmd.Flags().StringVar(&format, "format", string(model.ExportCSV), "csv, ndjson or parquet")
+	cmd.Flags().StringVar(&out, "out", "", "file to write, standard output when not set")
+	return cmd
+}
+
+// filterFlags select the loans that list and export operate on, and their
+// order.
+type filterFlags struct {
+	state                  string
+	createdFrom, createdTo string
+}
+
+func (f *filterFlags) register(cmd *cobra.Command, in *client.ListLoans) {
+	cmd.Flags().StringVar(&f.state, "state", "", "PROPOSED, APPROVED, INVESTED or DISBURSED")
+	cmd.Flags().Int64Var(&in.BorrowerID, "borrower-id", 0, "only loans of this borrower")
+	cmd.Flags().Int64Var(&in.InvestorID, "investor-id", 0, "only loans this investor invested in")
+	cmd.Flags().Float64Var(&in.MinPrincipal, "min-principal", 0, "smallest principal")
+	cmd.Flags().Float64Var(&in.MaxPrincipal, "max-principal", 0, "largest principal")
+	cmd.Flags().StringVar(&f.createdFrom, "created-from", "", "RFC 3339 time the loans were created at or after")
+	cmd.Flags().StringVar(&f.createdTo, "created-to", "", "RFC 3339 time the loans were created before")
+	cmd.Flags().StringVar(&in.Sort, "sort", "", "id, principal or created_at, prefixed with - for descending")
+}
+
+// apply sets the filters parsed from flags on in.
+func (f *filterFlags) apply(in *client.ListLoans) error {
+	in.State = model.LoanState(f.state)
+	from, err := parseTime("created-from", f.createdFrom)
+	if err != nil {
+		return err
+	}
+	if from != nil {
+		in.CreatedFrom = *from
+	}
+	to, err := parseTime("created-to", f.createdTo)
+	if err != nil {
+		return err
+	}
+	if to != nil {
+		in.CreatedTo = *to
+	}
+	return nil
+}
+
+func newImportCommand(run runFunc) *cobra.Command {
+	var (
+		in   client.ImportLoans
+		mode string
+		wait waitFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <file.csv>",
+		Short: "Propose loans from a CSV file",
+		Long: `Propose loans from a CSV file whose header names the columns, any of
+borrower_id, principal, rate, roi, agreement_link and tenor_months. The loans
+are created in the background; pass --wait to poll until they are, or check
+later with import-status.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in.Mode = model.ImportMode(mode)
+			switch in.Mode {
+			case model.ImportAllOrNothing, model.ImportBestEffort:
+			default:
+				return fmt.Errorf("unknown mode %q, want all_or_nothing or best_effort", mode)
+			}
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			in.FileName, in.File = filepath.Base(args[0]), file
+
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				job, err := c.ImportLoans(ctx, in)
+				if err != nil {
+					return nil, err
+				}
+				return wait.poll(ctx, c, job)
+			})
+		},
+	}
+	cmd.Flags().StringVar(&mode, "mode", string(model.ImportAllOrNothing), "all_or_nothing creates no loans unless every row is valid, best_effort creates the valid ones")
+	cmd.Flags().StringVar(&in.IdempotencyKey, "idempotency-key", "", "key that makes retries of this request safe")
+	wait.register(cmd)
+	return cmd
+}
+
+func newImportStatusCommand(run runFunc) *cobra.Command {
+	var wait waitFlags
+
+	cmd := &cobra.Command{
+		Use:   "import-status <id>",
+		Short: "Show the progress and row results of an import",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid import id %q", args[0])
+			}
+			return run(cmd, func(ctx context.Context, c *client.Client) (any, error) {
+				job, err := c.GetImport(ctx, id)
+				if err != nil {
+					return nil, err
+				}
+				return wait.poll(ctx, c, job)
+			})
+		},
+	}
+	wait.register(cmd)
+	return cmd
+}
+
+// waitFlags let a command poll an import until it has finished.
+type waitFlags struct {
+	wait     bool
+	interval time.Duration
+}
+
+func (w *waitFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&w.wait, "wait", false, "poll until the import has finished")
+	cmd.Flags().DurationVar(&w.interval, "poll-interval", time.Second, "how often --wait polls")
+}
+
+func (w *waitFlags) poll(ctx context.Context, c *client.Client, job *model.ImportJob) (*model.ImportJob, error) {
+	for w.wait && !job.Finished() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(w.interval):
+		}
+
+		var err error
+		if job, err = c.GetImport(ctx, job.ID); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// write prints v, a *model.Loan, *loanList or *model.ImportJob, in the
+// output format.
+func write(w io.Writer, output string, v any) error {
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case "yaml":
+		return writeYAML(w, v)
+	}
+
+	if job, ok := v.(*model.ImportJob); ok {
+		return writeImport(w, job)
+	}
+
+	var loans []*model.Loan
+	var nextCursor string
+	switch v := v.(type) {
+	case *model.Loan:
+		loans = []*model.Loan{v}
+	case *loanList:
+		loans, nextCursor = v.Loans, v.NextCursor
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSTATE\tBORROWER\tPRINCIPAL\tINVESTED\tRATE\tROI\tTENOR\tCREATED")
+	for _, loan := range loans {
+		invested := 0.0
+		for _, investment := range loan.Investments {
+			invested += investment.Amount
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%.2f\t%.2f\t%g\t%g\t%d\t%s\n",
+			loan.ID, loan.State, loan.BorrowerID, loan.Principal, invested, loan.Rate, loan.ROI, loan.TenorMonths, loan.CreatedAt.Format(time.RFC3339))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if nextCursor != "" {
+		_, err := fmt.Fprintf(w, "\nmore loans: --cursor %s\n", nextCursor)
+		return err
+	}
+	return nil
+}
+
+func writeImport(w io.Writer, job *model.ImportJob) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IMPORT\tMODE\tSTATUS\tTOTAL\tCREATED\tFAILED")
+	fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\n", job.ID, job.Mode, job.Status, job.Total, job.Created, job.Failed)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tSTATUS\tLOAN\tERROR")
+	for _, row := range job.Rows {
+		loanID := ""
+		if row.LoanID != 0 {
+			loanID = strconv.FormatInt(row.LoanID, 10)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", row.Line, row.Status, loanID, row.Error)
+	}
+	return tw.Flush()
+}
+
+// writeYAML prints v as YAML with the same field names and order as its
+// JSON. JSON is YAML, so it is parsed as such and re-encoded in block style.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func parseID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid loan id %q", arg)
+	}
+	return id, nil
+}
+
+// parseTime parses an RFC 3339 flag value, returning nil when it is empty.
+func parseTime(flag, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flag, err)
+	}
+	return &t, nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,222 @@
+package client_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"loan_system/cmd/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const loanJSON = `{"id":1990966857712013312,"borrower_id":123,"principal":100000,"rate":0.05,"roi":0.07,"state":"APPROVED",` +
+	`"investments":[{"investor_id":789,"amount":40000,"invested_at":"2025-01-03T10:00:00Z"}],"tenor_months":12,"created_at":"2025-01-02T10:00:00Z"}`
+
+func TestCommand(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	// polls counts import status requests; the import finishes on the second
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests, bodies = append(requests, r), append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/loans" && r.Method == http.MethodGet && r.URL.Query().Get("cursor") == "":
+			io.WriteString(w, `{"status":200,"data":{"loans":[`+loanJSON+`]},"next_cursor":"page2"}`)
+		case r.URL.Path == "/loans" && r.Method == http.MethodGet:
+			io.WriteString(w, `{"status":200,"data":{"loans":[{"id":2,"state":"PROPOSED"}]}}`)
+		case r.URL.Path == "/loans/import":
+			w.WriteHeader(http.StatusAccepted)
+			io.WriteString(w, `{"status":202,"data":{"import":{"id":9,"mode":"best_effort","status":"pending","total":2,"created":0,"failed":1,"rows":[]}}}`)
+		case r.URL.Path == "/loans/import/9":
+			polls++
+			status := "running"
+			if polls > 1 {
+				status = "completed"
+			}
+			io.WriteString(w, `{"status":200,"data":{"import":{"id":9,"mode":"best_effort","status":"`+status+`","total":2,"created":1,"failed":1,`+
+				`"rows":[{"line":2,"status":"created","loan_id":7},{"line":3,"status":"failed","error":"rate is required"}]}}}`)
+		case r.URL.Path == "/loans/export" && r.URL.Query().Get("state") == "PROPOSED":
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"message":"boom"}`)
+		case r.URL.Path == "/loans/export":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			io.WriteString(w, loanJSON+"\n")
+		case r.URL.Path == "/loans/404":
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, `{"message":"loan not found"}`)
+		default:
+			io.WriteString(w, `{"status":200,"data":{"loan":`+loanJSON+`}}`)
+		}
+	}))
+	defer server.Close()
+
+	execute := func(args ...string) (string, error) {
+		requests, bodies, polls = nil, nil, 0
+		cmd := client.NewCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append(args, "--server", server.URL))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("create prints a table", func(t *testing.T) {
+		t.Setenv("LOAN_TOKEN", "env-token")
+		out, err := execute("create", "--principal", "100000", "--rate", "0.05", "--roi", "0.07", "--agreement-link", "https://example.com/a", "--idempotency-key", "k1")
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "Bearer env-token", requests[0].Header.Get("Authorization"))
+		assert.Equal(t, "k1", requests[0].Header.Get("Idempotency-Key"))
+		assert.JSONEq(t, `{"principal":100000,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/a"}`, bodies[0])
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, []string{"ID", "STATE", "BORROWER", "PRINCIPAL", "INVESTED", "RATE", "ROI", "TENOR", "CREATED"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"1990966857712013312", "APPROVED", "123", "100000.00", "40000.00", "0.05", "0.07", "12", "2025-01-02T10:00:00Z"}, strings.Fields(lines[1]))
+	})
+
+	t.Run("create without required flags", func(t *testing.T) {
+		_, err := execute("create", "--principal", "100000")
+		assert.ErrorContains(t, err, "required flag(s)")
+		assert.Empty(t, requests)
+	})
+
+	t.Run("approve sends the flags", func(t *testing.T) {
+		_, err := execute("approve", "1990966857712013312", "--proof-document-id", "5", "--approved-at", "2025-01-02T10:00:00Z", "--token", "flag-token")
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPut, requests[0].Method)
+		assert.Equal(t, "/loans/1990966857712013312/approve", requests[0].URL.Path)
+		assert.Equal(t, "Bearer flag-token", requests[0].Header.Get("Authorization"))
+		assert.JSONEq(t, `{"proof_document_id":5,"approved_at":"2025-01-02T10:00:00Z"}`, bodies[0])
+
+		_, err = execute("approve", "1", "--proof-document-id", "5", "--approved-at", "yesterday")
+		assert.ErrorContains(t, err, "invalid --approved-at")
+	})
+
+	t.Run("invest and disburse", func(t *testing.T) {
+		_, err := execute("invest", "7", "--amount", "500", "--investor-id", "789")
+		require.NoError(t, err)
+		assert.Equal(t, "/loans/7/invest", requests[0].URL.Path)
+		assert.JSONEq(t, `{"investor_id":789,"amount":500}`, bodies[0])
+
+		_, err = execute("disburse", "7", "--agreement-document-id", "9")
+		require.NoError(t, err)
+		assert.Equal(t, "/loans/7/disburse", requests[0].URL.Path)
+		assert.JSONEq(t, `{"agreement_document_id":9}`, bodies[0])
+
+		_, err = execute("invest", "seven", "--amount", "500")
+		assert.ErrorContains(t, err, `invalid loan id "seven"`)
+	})
+
+	t.Run("get prints JSON", func(t *testing.T) {
+		out, err := execute("get", "7", "-o", "json")
+		require.NoError(t, err)
+		assert.JSONEq(t, loanJSON, out)
+	})
+
+	t.Run("get reports API errors", func(t *testing.T) {
+		_, err := execute("get", "404")
+		assert.EqualError(t, err, "500 Internal Server Error: loan not found")
+	})
+
+	t.Run("list prints YAML", func(t *testing.T) {
+		out, err := execute("list", "--state", "APPROVED", "--limit", "1", "-o", "yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "limit=1&state=APPROVED", requests[0].URL.RawQuery)
+		assert.True(t, strings.HasPrefix(out, "loans:\n  - id: 1990966857712013312\n    borrower_id: 123\n"), out)
+		assert.Contains(t, out, "    created_at: \"2025-01-02T10:00:00Z\"\n")
+		assert.True(t, strings.HasSuffix(out, "next_cursor: page2\n"), out)
+	})
+
+	t.Run("list follows every page", func(t *testing.T) {
+		out, err := execute("list", "--all")
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		assert.Equal(t, "cursor=page2", requests[1].URL.RawQuery)
+		assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 3)
+		assert.NotContains(t, out, "--cursor")
+	})
+
+	t.Run("list prints the next cursor", func(t *testing.T) {
+		out, err := execute("list")
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(out, "\nmore loans: --cursor page2\n"), out)
+	})
+
+	t.Run("unknown output", func(t *testing.T) {
+		_, err := execute("get", "7", "-o", "xml")
+		assert.ErrorContains(t, err, `unknown output "xml"`)
+		assert.Empty(t, requests)
+	})
+	t.Run("import waits for the job", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "loans.csv")
+		require.NoError(t, os.WriteFile(file, []byte("principal,rate,roi,agreement_link\n1000,0.05,0.07,a\n1000,0,0.07,b\n"), 0o600))
+
+		out, err := execute("import", file, "--mode", "best_effort", "--wait", "--poll-interval", "1ms")
+		require.NoError(t, err)
+		require.Len(t, requests, 3)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Contains(t, bodies[0], `filename="loans.csv"`)
+		assert.Contains(t, bodies[0], "1000,0,0.07,b")
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 6)
+		assert.Equal(t, []string{"9", "best_effort", "completed", "2", "1", "1"}, strings.Fields(lines[1]))
+		assert.Equal(t, []string{"2", "created", "7"}, strings.Fields(lines[4]))
+		assert.Equal(t, []string{"3", "failed", "rate", "is", "required"}, strings.Fields(lines[5]))
+	})
+
+	t.Run("import rejects an unknown mode", func(t *testing.T) {
+		_, err := execute("import", "loans.csv", "--mode", "sometimes")
+		assert.ErrorContains(t, err, `unknown mode "sometimes"`)
+		assert.Empty(t, requests)
+	})
+
+	t.Run("import-status prints JSON", func(t *testing.T) {
+		out, err := execute("import-status", "9", "-o", "json")
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Contains(t, out, `"status": "running"`)
+	})
+
+	t.Run("export writes to standard output", func(t *testing.T) {
+		out, err := execute("export", "--format", "ndjson", "--state", "DISBURSED", "--created-from", "2025-01-01T00:00:00Z", "--sort", "-principal")
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "created_from=2025-01-01T00%3A00%3A00Z&format=ndjson&sort=-principal&state=DISBURSED", requests[0].URL.RawQuery)
+		assert.Equal(t, loanJSON+"\n", out)
+	})
+
+	t.Run("export writes to a file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "loans.ndjson")
+		out, err := execute("export", "--format", "ndjson", "--out", file)
+		require.NoError(t, err)
+		assert.Empty(t, out)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, loanJSON+"\n", string(data))
+	})
+
+	t.Run("export removes the file on error", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "loans.csv")
+		_, err := execute("export", "--state", "PROPOSED", "--out", file)
+		assert.EqualError(t, err, "500 Internal Server Error: boom")
+		assert.NoFileExists(t, file)
+	})
+
+	t.Run("export rejects an unknown format", func(t *testing.T) {
+		_, err := execute("export", "--format", "xlsx")
+		assert.ErrorContains(t, err, `unknown format "xlsx"`)
+		assert.Empty(t, requests)
+	})
+}
@@ -2,37 +2,116 @@ package loan
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	grpcHandler "loan_system/internal/delivery/grpc"
+	"loan_system/internal/delivery/grpc/loanpb"
 	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/delivery/http/openapi"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
 	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/idempotency"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/metrics"
+	"loan_system/internal/pkg/migration"
+	"loan_system/internal/pkg/ratelimit"
+	"loan_system/internal/pkg/tracing"
+	agreementRepository "loan_system/internal/repository/agreement"
+	documentRepository "loan_system/internal/repository/document"
+	importRepository "loan_system/internal/repository/importjob"
 	loanRepository "loan_system/internal/repository/loan"
+	partnerRepository "loan_system/internal/repository/partner"
 	"loan_system/internal/repository/pubsub"
+	"loan_system/internal/repository/storage"
+	webhookRepository "loan_system/internal/repository/webhook"
 
+	agreementUsecase "loan_system/internal/usecase/agreement"
+	borrowerUsecase "loan_system/internal/usecase/borrower"
+	documentUsecase "loan_system/internal/usecase/document"
+	exportUsecase "loan_system/internal/usecase/export"
+	importUsecase "loan_system/internal/usecase/importjob"
+	investorUsecase "loan_system/internal/usecase/investor"
 	loanUsecase "loan_system/internal/usecase/loan"
+	partnerUsecase "loan_system/internal/usecase/partner"
+	statsUsecase "loan_system/internal/usecase/stats"
+	streamUsecase "loan_system/internal/usecase/stream"
+	webhookUsecase "loan_system/internal/usecase/webhook"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
 type application struct {
+	// cfg holds the configuration, reloaded on SIGHUP.
+	cfg *config.Store
+	// logLevel is the level of log, changed by reloads.
+	logLevel *slog.LevelVar
+	log      *slog.Logger
+	db       *sql.DB
+	metrics  *metrics.Prometheus
+	auth     *auth.Authenticator
+	// partnerAuth verifies requests signed with partner API keys.
+	partnerAuth *auth.PartnerAuthenticator
+	// spec is the OpenAPI document served at /openapi.json.
+	spec *openapi3.T
+	// loanServer serves the loan lifecycle over gRPC on the HTTP port.
+	loanServer *grpcHandler.LoanServer
+	// webhooks delivers loan events to partner webhooks in the background.
+	webhooks webhookUsecase.Usecase
+	// streams pushes loan events to server-sent event clients.
+	streams streamUsecase.Usecase
+	// imports creates the loans of uploaded CSV files in the background.
+	imports importUsecase.Usecase
+	// shutdownTracing flushes spans still buffered by the exporter.
+	shutdownTracing func(context.Context) error
+
 	httpHandler.LoanHandler
+	httpHandler.AgreementHandler
+	httpHandler.DocumentHandler
+	httpHandler.InvestorHandler
+	httpHandler.BorrowerHandler
+	httpHandler.StatsHandler
+	httpHandler.PartnerHandler
+	httpHandler.WebhookHandler
+	httpHandler.StreamHandler
+	httpHandler.ImportHandler
+	httpHandler.ExportHandler
 }
 
-func newApplication() application {
-	return application{}
+func newApplication(cfg *config.Store) application {
+	return application{cfg: cfg}
 }
 
 func (a application) config() application {
-	config.Load()
+	a.logLevel = new(slog.LevelVar)
+	log, err := logger.New(os.Stdout, a.cfg.Current().Log, a.logLevel)
+	if err != nil {
+		panic(err)
+	}
+	slog.SetDefault(log)
+	a.log = log
+
+	a.cfg.OnReload(func(cfg *config.Config) {
+		// validated by the reload, so the level always parses
+		_ = a.logLevel.UnmarshalText([]byte(cfg.Log.Level))
+	})
 	return a
 }
 
@@ -44,12 +123,24 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
 }
 
-func (a application) serveHTTP() {
+// router builds the echo instance with every middleware and route served.
+func (a application) router() *echo.Echo {
 	e := echo.New()
+	e.HideBanner = true
 
 	e.Validator = &CustomValidator{validator: validator.New()}
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	e.Use(logger.RequestID())
+	e.Use(logger.Requests(a.log))
+	e.Use(a.metrics.Middleware())
+	e.Use(tracing.Middleware())
+	// recover panics in every middleware below, and add CORS headers before
+	// anything can reject the request, so browsers can read 401s and 429s
+	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			a.log.ErrorContext(c.Request().Context(), "panic recovered", "error", err, "stack", string(stack))
+			return err
+		},
+	}))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowCredentials: true,
 		AllowOrigins:     []string{},
@@ -58,6 +149,49 @@ func (a application) serveHTTP() {
 		},
 		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete, http.MethodOptions},
 	}))
+	rateLimitStore := ratelimit.NewMemoryStore()
+	// limits are read per request so that reloads apply to them
+	rateLimit := func(name string, limit func(config.RateLimit) ratelimit.Limit, key func(echo.Context) string) echo.MiddlewareFunc {
+		return ratelimit.Middleware(ratelimit.Config{Store: rateLimitStore, Name: name, Key: key, LimitFunc: func() ratelimit.Limit {
+			return limit(a.cfg.Current().RateLimit)
+		}})
+	}
+	ipLimit := func(l config.RateLimit) ratelimit.Limit { return l.IP }
+	loansLimit := func(l config.RateLimit) ratelimit.Limit { return l.Loans }
+	investLimit := func(l config.RateLimit) ratelimit.Limit { return l.Invest }
+	reportsLimit := func(l config.RateLimit) ratelimit.Limit { return l.Reports }
+	partnersLimit := func(l config.RateLimit) ratelimit.Limit { return l.Partners }
+	webhooksLimit := func(l config.RateLimit) ratelimit.Limit { return l.Webhooks }
+	importsLimit := func(l config.RateLimit) ratelimit.Limit { return l.Imports }
+	exportsLimit := func(l config.RateLimit) ratelimit.Limit { return l.Exports }
+
+	// count every request by IP before authentication so bad credentials are limited too
+	e.Use(rateLimit("ip", ipLimit, ratelimit.IPKey))
+	e.Use(auth.Middleware(auth.Config{
+		Tokens:   a.auth,
+		Partners: a.partnerAuth,
+		// browsers open streams with EventSource, which can't set headers
+		StreamToken: func(c echo.Context) bool {
+			return c.Path() == "/loans/stream" || c.Path() == "/loans/:id/stream"
+		},
+		Skipper: func(c echo.Context) bool {
+			switch c.Path() {
+			case "/healthcheck", "/metrics", "/openapi.json", "/docs/*":
+				return true
+			}
+			return c.Request().Method == http.MethodOptions
+		},
+	}))
+	// keys are scoped per caller so two users can't collide on the same key
+	e.Use(idempotency.Middleware(idempotency.Config{
+		Store:       idempotency.NewMemoryStore(),
+		TTL:         a.cfg.Current().Idempotency.TTL,
+		MaxBodySize: a.cfg.Current().Idempotency.MaxBodySize,
+		Scope: func(c echo.Context) string {
+			principal, _ := auth.FromContext(c.Request().Context())
+			return fmt.Sprintf("%s:%d", principal.Role, principal.ID)
+		},
+	}))
 
 	e.GET("/healthcheck", func(c echo.Context) error {
 		response := map[string]string{
@@ -66,27 +200,136 @@ func (a application) serveHTTP() {
 		return c.JSON(http.StatusOK, response)
 	})
 
-	loanGroup := e.Group("/loans")
+	e.GET("/metrics", echo.WrapHandler(a.metrics.Handler()))
 
-	loanGroup.POST("", a.CreateLoan)
-	loanGroup.PUT("/:id/approve", a.ApproveLoan)
-	loanGroup.POST("/:id/invest", a.AddInvestment)
-	loanGroup.PUT("/:id/disburse", a.DisburseLoan)
+	e.GET("/openapi.json", openapi.Handler(a.spec))
+	e.GET("/docs/*", echo.WrapHandler(openapi.Docs("/openapi.json", "/docs/")))
+
+	loanGroup := e.Group("/loans", rateLimit("loans", loansLimit, nil))
+
+	loanGroup.POST("", a.CreateLoan, auth.Require(auth.RoleBorrower, auth.RolePartner), auth.RequireScope(model.PartnerScopeLoansCreate))
+	loanGroup.POST("/import", a.ImportLoans, auth.Require(auth.RoleBorrower, auth.RolePartner), auth.RequireScope(model.PartnerScopeLoansCreate),
+		rateLimit("imports", importsLimit, nil))
+	loanGroup.GET("/import/:id", a.GetImport, auth.Require(auth.RoleBorrower, auth.RolePartner))
+	loanGroup.GET("/export", a.ExportLoans, auth.Require(auth.RoleAdmin), rateLimit("exports", exportsLimit, nil))
+	loanGroup.PUT("/:id/approve", a.ApproveLoan, auth.Require(auth.RoleFieldValidator))
+	loanGroup.PUT("/approve", a.ApproveLoans, auth.Require(auth.RoleFieldValidator))
+	loanGroup.POST("/:id/invest", a.AddInvestment, auth.Require(auth.RoleInvestor, auth.RolePartner), auth.RequireScope(model.PartnerScopeLoansInvest),
+		rateLimit("invest", investLimit, nil))
+	loanGroup.PUT("/:id/disburse", a.DisburseLoan, auth.Require(auth.RoleFieldOfficer))
+	loanGroup.PUT("/disburse", a.DisburseLoans, auth.Require(auth.RoleFieldOfficer))
 
 	loanGroup.GET("/:id", a.GetLoan)
 	loanGroup.GET("", a.GetLoans)
+	loanGroup.GET("/stream", a.StreamLoans)
+	loanGroup.POST("/stream/token", a.CreateStreamToken, auth.Require(auth.Roles...))
+	loanGroup.GET("/:id/stream", a.StreamLoan)
+	loanGroup.GET("/:id/agreements/:investorID", a.GetAgreement,
+		auth.Require(auth.RoleInvestor, auth.RoleFieldOfficer), auth.Owner(auth.RoleInvestor, "investorID"))
+	loanGroup.POST("/:id/documents", a.UploadDocument, auth.Require(auth.RoleFieldValidator, auth.RoleFieldOfficer))
+
+	investorGroup := e.Group("/investors", rateLimit("reports", reportsLimit, nil))
+
+	investorGroup.GET("/:id/portfolio", a.GetPortfolio, auth.Require(auth.RoleInvestor), auth.Owner(auth.RoleInvestor, "id"))
 
-	h2s := &http2.Server{}
+	borrowerGroup := e.Group("/borrowers", rateLimit("reports", reportsLimit, nil))
+
+	borrowerGroup.GET("/:id/loans", a.GetBorrowerLoans, auth.Require(auth.RoleBorrower), auth.Owner(auth.RoleBorrower, "id"))
+
+	partnerGroup := e.Group("/partners", auth.Require(auth.RoleAdmin), rateLimit("partners", partnersLimit, nil))
+
+	partnerGroup.POST("", a.CreatePartner)
+	partnerGroup.POST("/:id/keys", a.IssuePartnerKey)
+	partnerGroup.GET("/:id/keys", a.GetPartnerKeys)
+	partnerGroup.DELETE("/:id/keys/:keyID", a.RevokePartnerKey)
+
+	statsGroup := e.Group("/stats", auth.Require(auth.RoleAdmin), rateLimit("reports", reportsLimit, nil))
+
+	statsGroup.GET("/loans", a.GetLoanStats)
+
+	webhookGroup := e.Group("/webhooks", auth.Require(auth.RolePartner), auth.RequireScope(model.PartnerScopeWebhooks),
+		rateLimit("webhooks", webhooksLimit, nil))
+
+	webhookGroup.POST("", a.CreateWebhook)
+	webhookGroup.GET("", a.GetWebhooks)
+	webhookGroup.GET("/deliveries", a.GetWebhookDeliveries)
+	webhookGroup.GET("/deliveries/:id", a.GetWebhookDelivery)
+	webhookGroup.POST("/deliveries/:id/redeliver", a.RedeliverWebhook)
+	webhookGroup.GET("/:id", a.GetWebhook)
+	webhookGroup.DELETE("/:id", a.DeleteWebhook)
+
+	return e
+}
+
+// grpcServer builds the gRPC server, checking the same roles per call as the
+// matching HTTP routes. Streaming calls, such as server reflection, need a
+// bearer token too.
+func (a application) grpcServer() *grpc.Server {
+	roles := map[string][]auth.Role{
+		loanpb.LoanService_CreateLoan_FullMethodName:    {auth.RoleBorrower},
+		loanpb.LoanService_ApproveLoan_FullMethodName:   {auth.RoleFieldValidator},
+		loanpb.LoanService_AddInvestment_FullMethodName: {auth.RoleInvestor},
+		loanpb.LoanService_DisburseLoan_FullMethodName:  {auth.RoleFieldOfficer},
+	}
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.UnaryServerInterceptor(a.auth, roles)),
+		grpc.StreamInterceptor(auth.StreamServerInterceptor(a.auth, roles)),
+	)
+	loanpb.RegisterLoanServiceServer(s, a.loanServer)
+	reflection.Register(s)
+	return s
+}
+
+// handler serves gRPC calls and HTTP requests on the same port, over HTTP/2
+// without TLS, telling them apart by content type.
+func (a application) handler() http.Handler {
+	e := a.router()
+	s := a.grpcServer()
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get(echo.HeaderContentType), "application/grpc") {
+			s.ServeHTTP(w, r)
+			return
+		}
+		e.ServeHTTP(w, r)
+	}), &http2.Server{})
+}
+
+// server builds the HTTP server. Shutting it down also ends open loan
+// streams, which would otherwise keep it waiting.
+func (a application) server() *http.Server {
 	h1s := &http.Server{
-		Addr:    ":" + config.Instance().App.ServerPort,
-		Handler: h2c.NewHandler(e, h2s),
+		Addr:              ":" + a.cfg.Current().App.ServerPort,
+		Handler:           a.handler(),
+		ReadHeaderTimeout: a.cfg.Current().App.ReadHeaderTimeout,
 	}
+	h1s.RegisterOnShutdown(a.streams.Close)
+	return h1s
+}
+
+func (a application) serveHTTP() {
+	h1s := a.server()
+
+	// Start the webhook worker, stopped once the server has drained
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	go a.webhooks.Run(workerCtx)
 
 	// Start server
 	go func() {
-		fmt.Println("Server Started at:", config.Instance().App.ServerPort)
+		a.log.Info("server started", "port", a.cfg.Current().App.ServerPort)
 		if err := h1s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			e.Logger.Fatal("shutting down the server")
+			a.log.Error("server failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	// Reload the configuration on SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	go func() {
+		for range hangup {
+			a.reload()
 		}
 	}()
 
@@ -98,29 +341,188 @@ func (a application) serveHTTP() {
 	<-quit
 
 	// Context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Current().App.ShutdownTimeout)
 	defer cancel()
 
-	fmt.Println("Shutting down server...")
+	a.log.Info("shutting down server")
 	// Attempt to gracefully shut down the server
 	if err := h1s.Shutdown(ctx); err != nil {
-		e.Logger.Fatal(err)
+		a.log.Error("shutdown failed", "error", err)
+		os.Exit(1)
+	}
+	stopWorker()
+	// let running imports finish creating their loans before the database closes
+	a.imports.Wait()
+	if err := a.shutdownTracing(ctx); err != nil {
+		a.log.Error("flush traces failed", "error", err)
+	}
+	if a.db != nil {
+		if err := a.db.Close(); err != nil {
+			a.log.Error("close database failed", "error", err)
+		}
+	}
+	a.log.Info("server gracefully stopped")
+}
+
+// reload loads the configuration again, keeping the running one when the
+// new one is invalid.
+func (a application) reload() {
+	pending, err := a.cfg.Reload()
+	if err != nil {
+		a.log.Error("reload config failed, keeping the running config", "error", err)
+		return
+	}
+	a.log.Info("config reloaded")
+	if len(pending) > 0 {
+		a.log.Warn("changed settings take effect after a restart", "settings", pending)
+	}
+}
+
+// repositories are the stores that persist to the configured backend.
+type repositories struct {
+	loans      loanRepository.Repository
+	documents  documentRepository.Repository
+	agreements agreementRepository.Repository
+	partners   partnerRepository.Repository
+	webhooks   webhookRepository.Repository
+}
+
+// newRepositories builds the repositories for the configured backend,
+// returning the opened database handle when the backend needs one.
+func newRepositories(cfg config.Database, log *slog.Logger) (repositories, *sql.DB, error) {
+	switch cfg.Backend {
+	case "memory":
+		return repositories{
+			loans:      loanRepository.NewRepository(log),
+			documents:  documentRepository.NewRepository(log),
+			agreements: agreementRepository.NewRepository(),
+			partners:   partnerRepository.NewRepository(log),
+			webhooks:   webhookRepository.NewRepository(log),
+		}, nil, nil
+	case "sqlite":
+		db, err := database.Open(cfg.SQLitePath)
+		if err != nil {
+			return repositories{}, nil, err
+		}
+		migrator, err := migration.NewMigrator(db)
+		if err != nil {
+			db.Close()
+			return repositories{}, nil, err
+		}
+		// refuse to serve against a schema older than this binary expects
+		if err := migrator.EnsureLatest(context.Background()); err != nil {
+			db.Close()
+			return repositories{}, nil, err
+		}
+		return repositories{
+			loans:      loanRepository.NewSQLRepository(db, log),
+			documents:  documentRepository.NewSQLRepository(db, log),
+			agreements: agreementRepository.NewSQLRepository(db),
+			partners:   partnerRepository.NewSQLRepository(db, log),
+			webhooks:   webhookRepository.NewSQLRepository(db, log),
+		}, db, nil
+	default:
+		return repositories{}, nil, fmt.Errorf("unknown database backend %q", cfg.Backend)
 	}
-	fmt.Println("Server gracefully stopped")
 }
 
 func (a application) init() application {
+	cfg := a.cfg.Current()
+
+	// init tracing, a no-op unless an exporter is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		panic(err)
+	}
+	a.shutdownTracing = shutdownTracing
+
+	// init bearer token verification
+	a.auth = auth.NewAuthenticator(cfg.Auth.SigningKey)
+
+	// init the API document, failing fast if the bundled one is invalid
+	a.spec, err = openapi.Load()
+	if err != nil {
+		panic(err)
+	}
+
 	// init repo
-	loanRepository := loanRepository.NewRepository()
-	// init pubsub mock
-	pubsubMock := pubsub.NewMock()
+	repos, db, err := newRepositories(cfg.Database, a.log)
+	if err != nil {
+		panic(err)
+	}
+	a.db = db
+	loanRepo := loanRepository.WithTracing(repos.loans)
+	documentRepository := repos.documents
+	partnerRepository := repos.partners
+	a.partnerAuth = auth.NewPartnerAuthenticator(partnerRepository, auth.NewMemoryNonceStore(), cfg.Auth.PartnerClockSkew, cfg.Auth.PartnerMaxBodySize)
+	// init blob storage for generated and uploaded documents
+	blobStore, err := storage.NewLocalBlobStore(cfg.Storage.BlobDir)
+	if err != nil {
+		panic(err)
+	}
+	// init metrics and count failed publishes of the pubsub mock
+	a.metrics = metrics.NewPrometheus()
+	broker := pubsub.NewBroker(pubsub.NewMock(a.log))
+	pubsubMock := pubsub.WithTracing(pubsub.WithMetrics(broker, a.metrics))
 
-	loanUsecase := loanUsecase.NewUsecase(loanRepository, pubsubMock)
+	agreementUsecase := agreementUsecase.NewUsecase(repos.agreements, loanRepo, blobStore)
+	documentUsecase := documentUsecase.NewUsecase(documentRepository, loanRepo, blobStore, cfg.Storage.MaxUploadSize)
+	loanUsecase := loanUsecase.NewUsecase(loanRepo, documentRepository, pubsubMock, agreementUsecase, a.metrics, a.log)
+	investorUsecase := investorUsecase.NewUsecase(loanRepo)
+	borrowerUsecase := borrowerUsecase.NewUsecase(loanRepo)
+	statsUsecase := statsUsecase.NewUsecase(loanRepo)
+	partnerUsecase := partnerUsecase.NewUsecase(partnerRepository)
+	webhookConfig := cfg.Webhook
+	a.webhooks = webhookUsecase.NewUsecase(repos.webhooks, webhookRepository.NewHTTPSender(webhookConfig.Timeout, webhookConfig.AllowInsecure), webhookUsecase.Options{
+		MaxAttempts:   webhookConfig.MaxAttempts,
+		Backoff:       webhookConfig.Backoff,
+		MaxBackoff:    webhookConfig.MaxBackoff,
+		PollInterval:  webhookConfig.PollInterval,
+		Concurrency:   webhookConfig.Concurrency,
+		AllowInsecure: webhookConfig.AllowInsecure,
+	}, a.log)
+	a.imports = importUsecase.NewUsecase(importRepository.NewRepository(a.log), loanUsecase, cfg.Import.Retention, a.log)
+	a.streams = streamUsecase.NewUsecase(loanRepo, cfg.Stream.ReplaySize)
+	// push loan events to stream clients and queue them for partner
+	// webhooks, which the worker sends
+	broker.Subscribe(model.TopicLoanEvents, func(ctx context.Context, data []byte, _ map[string]string) {
+		var event model.LoanEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			a.log.ErrorContext(ctx, "decode loan event failed", "error", err)
+			return
+		}
+		a.streams.Publish(event)
+		if err := a.webhooks.Enqueue(ctx, event); err != nil {
+			a.log.ErrorContext(ctx, "queue webhook deliveries failed", "event_id", event.ID, "error", err)
+		}
+	})
+	a.metrics.RegisterLoanBook(func(ctx context.Context) (*model.LoanStats, error) {
+		return statsUsecase.LoanStats(ctx, time.Time{}, time.Time{}, "")
+	}, cfg.Metrics.LoanBookTTL)
 
 	a.LoanHandler = *httpHandler.NewLoanHandler(loanUsecase)
+	a.AgreementHandler = *httpHandler.NewAgreementHandler(agreementUsecase)
+	a.DocumentHandler = *httpHandler.NewDocumentHandler(documentUsecase, cfg.Storage.MaxUploadSize)
+	a.InvestorHandler = *httpHandler.NewInvestorHandler(investorUsecase)
+	a.BorrowerHandler = *httpHandler.NewBorrowerHandler(borrowerUsecase)
+	a.StatsHandler = *httpHandler.NewStatsHandler(statsUsecase)
+	a.PartnerHandler = *httpHandler.NewPartnerHandler(partnerUsecase)
+	a.WebhookHandler = *httpHandler.NewWebhookHandler(a.webhooks)
+	a.StreamHandler = *httpHandler.NewStreamHandler(a.streams, cfg.Stream.Heartbeat, a.auth, cfg.Stream.TokenTTL)
+	a.ImportHandler = *httpHandler.NewImportHandler(a.imports, func() int { return a.cfg.Current().Import.MaxRows }, cfg.Storage.MaxUploadSize)
+	a.ExportHandler = *httpHandler.NewExportHandler(exportUsecase.NewUsecase(loanRepo), a.log)
+	a.loanServer = grpcHandler.NewLoanServer(loanUsecase)
 	return a
 }
 
-func Execute() {
-	newApplication().config().init().serveHTTP()
+// Execute serves with the configuration loaded from sources, failing before
+// anything starts when it is invalid.
+func Execute(sources config.Sources) error {
+	cfg, err := config.Load(sources)
+	if err != nil {
+		return err
+	}
+
+	newApplication(config.NewStore(cfg, sources)).config().init().serveHTTP()
+	return nil
 }
@@ -0,0 +1,705 @@
+package loan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"loan_system/internal/delivery/grpc/loanpb"
+	"loan_system/internal/delivery/http/openapi"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/migration"
+	loanRepository "loan_system/internal/repository/loan"
+	webhookUsecase "loan_system/internal/usecase/webhook"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+func newTestApplication(t *testing.T, overrides ...string) application {
+	return newTestApplicationFrom(t, config.Sources{Overrides: overrides})
+}
+
+// newTestApplicationFrom builds the application from sources on top of test
+// settings, rather than from this process's environment.
+func newTestApplicationFrom(t *testing.T, sources config.Sources) application {
+	sources.Environ = append([]string{
+		"STORAGE_BLOB_DIR=" + t.TempDir(),
+		"DATABASE_BACKEND=memory",
+		"TRACING_EXPORTER=none",
+		"LOG_LEVEL=error",
+		"AUTH_SIGNING_KEY=" + strings.Repeat("k", 32),
+	}, sources.Environ...)
+	cfg, err := config.Load(sources)
+	require.NoError(t, err)
+	return newApplication(config.NewStore(cfg, sources)).config().init()
+}
+
+// TestReload changes a rate limit and the log level in the config file and
+// checks that SIGHUP's reload applies them to the running server, while the
+// port waits for a restart.
+func TestReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+	write("rate_limit:\n  stats: 0\n")
+	_, err := config.Load(config.Sources{File: file})
+	require.ErrorContains(t, err, "config.yaml:2: unknown setting rate_limit.stats")
+
+	write("rate_limit:\n  reports: 1/1h\n")
+	a := newTestApplicationFrom(t, config.Sources{File: file})
+	e := a.router()
+	admin, err := a.auth.Issue(auth.Principal{ID: 1, Role: auth.RoleAdmin}, time.Hour)
+	require.NoError(t, err)
+	stats := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/stats/loans", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+admin)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, stats())
+	assert.Equal(t, http.StatusTooManyRequests, stats())
+
+	write("app:\n  server_port: \"8080\"\nrate_limit:\n  reports: 0\nlog:\n  level: debug\n")
+	pending, err := a.cfg.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.server_port (APP_SERVER_PORT)"}, pending)
+	assert.Equal(t, http.StatusOK, stats())
+	assert.Equal(t, slog.LevelError, a.logLevel.Level(), "LOG_LEVEL in the environment wins over the file")
+	assert.Equal(t, "1323", a.cfg.Current().App.ServerPort)
+
+	write("rate_limit:\n  reports: lots\n")
+	_, err = a.cfg.Reload()
+	assert.ErrorContains(t, err, "rate_limit.reports (RATE_LIMIT_REPORTS)")
+	assert.False(t, a.cfg.Current().RateLimit.Reports.Enabled())
+}
+
+// TestRoutesDocumented fails when a route is served without being in the
+// OpenAPI document, or documented without being served.
+// TestRejectionsCarryCORS checks that requests turned away by the rate limit
+// or authentication still carry CORS headers, so browsers can read why.
+func TestRejectionsCarryCORS(t *testing.T) {
+	e := newTestApplication(t, "rate_limit.ip=1/1h").router()
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/loans", nil)
+		req.Header.Set(echo.HeaderOrigin, "https://app.example.com")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, want := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		rec := get()
+		assert.Equal(t, want, rec.Code)
+		assert.Equal(t, "https://app.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	}
+}
+
+// TestSQLiteSurvivesRestart checks that what the SQLite backend stores is
+// still there for a new application on the same database.
+func TestSQLiteSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loan.db")
+	db, err := database.Open(path)
+	require.NoError(t, err)
+	migrator, err := migration.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	// a loan funded while its agreements could not be generated
+	funded := &model.Loan{BorrowerID: 7, Principal: 1000, ROI: 0.1, State: model.StateInvested, Investments: []model.Investment{{InvestorID: 10, Amount: 1000}}}
+	require.NoError(t, loanRepository.NewSQLRepository(db, logger.Discard()).Save(context.Background(), funded))
+	require.NoError(t, db.Close())
+	agreementPath := fmt.Sprintf("/loans/%d/agreements/10", funded.ID)
+
+	blobDir := t.TempDir()
+	var officer string
+	start := func() (*echo.Echo, string) {
+		a := newTestApplication(t, "database.backend=sqlite", "database.sqlite_path="+path, "storage.blob_dir="+blobDir)
+		t.Cleanup(func() { a.db.Close() })
+		admin, err := a.auth.Issue(auth.Principal{ID: 1, Role: auth.RoleAdmin}, time.Hour)
+		require.NoError(t, err)
+		officer, err = a.auth.Issue(auth.Principal{ID: 2, Role: auth.RoleFieldOfficer}, time.Hour)
+		require.NoError(t, err)
+		return a.router(), admin
+	}
+	do := func(e *echo.Echo, token, method, target, body string, out any) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		if out != nil {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+		}
+	}
+
+	e, admin := start()
+	var partner struct {
+		Data struct {
+			Partner model.Partner `json:"partner"`
+		} `json:"data"`
+	}
+	do(e, admin, http.MethodPost, "/partners", `{"name":"Koperasi Maju"}`, &partner)
+	keysPath := fmt.Sprintf("/partners/%d/keys", partner.Data.Partner.ID)
+	do(e, admin, http.MethodPost, keysPath, `{"scopes":["loans:create"]}`, nil)
+	do(e, admin, http.MethodPost, "/webhooks", fmt.Sprintf(`{"partner_id":%d,"url":"https://partner.example/hooks","event_types":["loan.approved"]}`, partner.Data.Partner.ID), nil)
+	type agreementResponse struct {
+		Data struct {
+			Agreement model.Agreement `json:"agreement"`
+		} `json:"data"`
+	}
+	var generated agreementResponse
+	do(e, officer, http.MethodGet, agreementPath, "", &generated)
+
+	e, admin = start()
+	var stored agreementResponse
+	do(e, officer, http.MethodGet, agreementPath, "", &stored)
+	assert.Equal(t, generated, stored, "the agreement is not generated again")
+	do(e, officer, http.MethodGet, agreementPath+"?format=pdf", "", nil)
+	var keys struct {
+		Data struct {
+			Keys []model.PartnerKey `json:"keys"`
+		} `json:"data"`
+	}
+	do(e, admin, http.MethodGet, keysPath, "", &keys)
+	if assert.Len(t, keys.Data.Keys, 1) {
+		assert.Equal(t, []model.PartnerScope{model.PartnerScopeLoansCreate}, keys.Data.Keys[0].Scopes)
+	}
+	var webhooks struct {
+		Data struct {
+			Webhooks []model.WebhookSubscription `json:"webhooks"`
+		} `json:"data"`
+	}
+	do(e, admin, http.MethodGet, "/webhooks", "", &webhooks)
+	if assert.Len(t, webhooks.Data.Webhooks, 1) {
+		assert.Equal(t, "https://partner.example/hooks", webhooks.Data.Webhooks[0].URL)
+	}
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	a := newTestApplication(t)
+	e := a.router()
+
+	served := map[string]bool{}
+	for _, route := range e.Routes() {
+		// Swagger UI assets are served under a wildcard the document can't express
+		if route.Method == echo.RouteNotFound || route.Path == "/docs/*" {
+			continue
+		}
+		path := route.Path
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				path = strings.Replace(path, segment, "{"+segment[1:]+"}", 1)
+			}
+		}
+		served[route.Method+" "+path] = true
+	}
+
+	documented := map[string]bool{}
+	for path, item := range a.spec.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	assert.Equal(t, documented, served)
+}
+
+// TestAPIMatchesSpec runs a loan through its whole lifecycle, plus the
+// bulk, import, export, reports, partner and webhook routes, with every request and response
+// checked against the OpenAPI document.
+func TestAPIMatchesSpec(t *testing.T) {
+	a := newTestApplication(t)
+	e := a.router()
+	validator, err := openapi.Validator(openapi.ValidatorConfig{
+		Spec: a.spec,
+		OnError: func(c echo.Context, err error) {
+			t.Errorf("%v", err)
+		},
+	})
+	require.NoError(t, err)
+	e.Use(validator)
+
+	token := func(role auth.Role, id int64) string {
+		token, err := a.auth.Issue(auth.Principal{ID: id, Role: role}, time.Hour)
+		require.NoError(t, err)
+		return token
+	}
+	admin := token(auth.RoleAdmin, 1)
+	borrower := token(auth.RoleBorrower, 123)
+	validatorToken := token(auth.RoleFieldValidator, 456)
+	investor := token(auth.RoleInvestor, 789)
+	officer := token(auth.RoleFieldOfficer, 101)
+
+	do := func(method, target, token, contentType string, body []byte, wantStatus int) []byte {
+		t.Helper()
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		if contentType != "" {
+			req.Header.Set(echo.HeaderContentType, contentType)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, wantStatus, rec.Code, rec.Body.String())
+		return rec.Body.Bytes()
+	}
+	doJSON := func(method, target, token, body string, wantStatus int) []byte {
+		t.Helper()
+		return do(method, target, token, echo.MIMEApplicationJSON, []byte(body), wantStatus)
+	}
+	upload := func(target, token, kind, fileName, partType string, content []byte) model.Document {
+		t.Helper()
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		require.NoError(t, w.WriteField("kind", kind))
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, fileName))
+		header.Set(echo.HeaderContentType, partType)
+		part, err := w.CreatePart(header)
+		require.NoError(t, err)
+		part.Write(content)
+		require.NoError(t, w.Close())
+
+		var response struct {
+			Data struct {
+				Document model.Document `json:"document"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(do(http.MethodPost, target, token, w.FormDataContentType(), buf.Bytes(), http.StatusOK), &response))
+		return response.Data.Document
+	}
+	loanOf := func(body []byte) model.Loan {
+		t.Helper()
+		var response struct {
+			Data struct {
+				Loan model.Loan `json:"loan"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &response))
+		return response.Data.Loan
+	}
+
+	do(http.MethodGet, "/healthcheck", "", "", nil, http.StatusOK)
+	do(http.MethodGet, "/openapi.json", "", "", nil, http.StatusOK)
+
+	doJSON(http.MethodPost, "/loans", borrower, `{"principal":100000,"rate":0.05}`, http.StatusBadRequest)
+	doJSON(http.MethodPost, "/loans", borrower, `{"principal":100000,"borrower_id":7,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/a"}`, http.StatusForbidden)
+	loan := loanOf(doJSON(http.MethodPost, "/loans", borrower,
+		`{"principal":100000,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/agreement","tenor_months":12}`, http.StatusOK))
+	loanPath := fmt.Sprintf("/loans/%d", loan.ID)
+
+	proof := upload(loanPath+"/documents", validatorToken, "approval_proof", "proof.jpg", "image/jpeg",
+		append([]byte{0xff, 0xd8, 0xff, 0xe0}, make([]byte, 64)...))
+	doJSON(http.MethodPut, loanPath+"/approve", validatorToken,
+		fmt.Sprintf(`{"proof_document_id":%d,"approved_at":"2025-01-02T10:00:00Z"}`, proof.ID), http.StatusOK)
+
+	doJSON(http.MethodPost, loanPath+"/invest", investor, `{"amount":0}`, http.StatusBadRequest)
+	loan = loanOf(doJSON(http.MethodPost, loanPath+"/invest", investor, `{"amount":100000}`, http.StatusOK))
+	assert.Equal(t, model.StateInvested, loan.State)
+
+	agreement := upload(loanPath+"/documents", officer, "signed_agreement", "agreement.pdf", "application/pdf",
+		[]byte("%PDF-1.4\n%signed agreement\n"))
+	loan = loanOf(doJSON(http.MethodPut, loanPath+"/disburse", officer,
+		fmt.Sprintf(`{"agreement_document_id":%d}`, agreement.ID), http.StatusOK))
+	assert.Equal(t, model.StateDisbursed, loan.State)
+
+	// a village group approved together, each loan with its own proof
+	var group, groupProofs []int64
+	for range 2 {
+		id := loanOf(doJSON(http.MethodPost, "/loans", borrower,
+			`{"principal":5000,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/agreement"}`, http.StatusOK)).ID
+		group = append(group, id)
+		groupProofs = append(groupProofs, upload(fmt.Sprintf("/loans/%d/documents", id), validatorToken, "approval_proof", "group.jpg", "image/jpeg",
+			append([]byte{0xff, 0xd8, 0xff, 0xe0}, make([]byte, 64)...)).ID)
+	}
+	var batch struct {
+		Data struct {
+			Results []model.BatchResult `json:"results"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(doJSON(http.MethodPut, "/loans/approve", validatorToken,
+		fmt.Sprintf(`{"loans":[{"loan_id":%d,"proof_document_id":%d},{"loan_id":%d,"proof_document_id":%d},{"loan_id":%d,"proof_document_id":%d}],"atomic":true}`,
+			group[0], groupProofs[0], group[1], groupProofs[1], loan.ID, proof.ID), http.StatusOK), &batch))
+	assert.Equal(t, []model.BatchStatus{model.BatchSkipped, model.BatchSkipped, model.BatchFailed},
+		[]model.BatchStatus{batch.Data.Results[0].Status, batch.Data.Results[1].Status, batch.Data.Results[2].Status})
+	// one loan's proof does not approve the other
+	require.NoError(t, json.Unmarshal(doJSON(http.MethodPut, "/loans/approve", validatorToken,
+		fmt.Sprintf(`{"loans":[{"loan_id":%d,"proof_document_id":%d},{"loan_id":%d,"proof_document_id":%d}],"atomic":true}`,
+			group[0], groupProofs[0], group[1], groupProofs[0]), http.StatusOK), &batch))
+	assert.Equal(t, model.BatchFailed, batch.Data.Results[1].Status)
+	require.NoError(t, json.Unmarshal(doJSON(http.MethodPut, "/loans/approve", validatorToken,
+		fmt.Sprintf(`{"loans":[{"loan_id":%d,"proof_document_id":%d},{"loan_id":%d,"proof_document_id":%d}],"atomic":true}`,
+			group[0], groupProofs[0], group[1], groupProofs[1]), http.StatusOK), &batch))
+	for _, result := range batch.Data.Results {
+		assert.Equal(t, model.BatchSucceeded, result.Status)
+		assert.Equal(t, model.StateApproved, result.Loan.State)
+	}
+	doJSON(http.MethodPut, "/loans/disburse", officer,
+		fmt.Sprintf(`{"loans":[{"loan_id":%d,"agreement_document_id":%d}]}`, loan.ID, agreement.ID), http.StatusOK)
+	doJSON(http.MethodPut, "/loans/approve", officer,
+		fmt.Sprintf(`{"loans":[{"loan_id":%d,"proof_document_id":%d}]}`, group[0], groupProofs[0]), http.StatusForbidden)
+
+	do(http.MethodGet, loanPath, borrower, "", nil, http.StatusOK)
+	do(http.MethodGet, "/loans?state=DISBURSED&sort=-principal&limit=10", investor, "", nil, http.StatusOK)
+	do(http.MethodGet, "/loans?state=CLOSED", investor, "", nil, http.StatusBadRequest)
+	do(http.MethodGet, loanPath+"/agreements/789", investor, "", nil, http.StatusOK)
+	do(http.MethodGet, loanPath+"/agreements/789?format=html", investor, "", nil, http.StatusOK)
+	do(http.MethodGet, loanPath+"/agreements/789?format=pdf", officer, "", nil, http.StatusOK)
+	do(http.MethodGet, "/investors/789/portfolio", investor, "", nil, http.StatusOK)
+	do(http.MethodGet, "/borrowers/123/loans", borrower, "", nil, http.StatusOK)
+	do(http.MethodGet, "/stats/loans?group_by=month", admin, "", nil, http.StatusOK)
+
+	var csvBody bytes.Buffer
+	w := multipart.NewWriter(&csvBody)
+	require.NoError(t, w.WriteField("mode", "best_effort"))
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="loans.csv"`)
+	header.Set(echo.HeaderContentType, "text/csv")
+	part, err := w.CreatePart(header)
+	require.NoError(t, err)
+	part.Write([]byte("principal,rate,roi,agreement_link,tenor_months\n5000,0.05,0.07,https://example.com/a,6\n5000,0,0.07,https://example.com/b,\n"))
+	require.NoError(t, w.Close())
+	var imported struct {
+		Data struct {
+			Import model.ImportJob `json:"import"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(do(http.MethodPost, "/loans/import", borrower, w.FormDataContentType(), csvBody.Bytes(), http.StatusAccepted), &imported))
+	a.imports.Wait()
+	importPath := fmt.Sprintf("/loans/import/%d", imported.Data.Import.ID)
+	require.NoError(t, json.Unmarshal(do(http.MethodGet, importPath, borrower, "", nil, http.StatusOK), &imported))
+	assert.Equal(t, model.ImportCompleted, imported.Data.Import.Status)
+	assert.Equal(t, 1, imported.Data.Import.Created)
+	assert.Equal(t, 1, imported.Data.Import.Failed)
+	do(http.MethodGet, importPath, token(auth.RoleBorrower, 124), "", nil, http.StatusForbidden)
+	do(http.MethodGet, "/stats/loans", borrower, "", nil, http.StatusForbidden)
+	exported := do(http.MethodGet, "/loans/export?format=ndjson&borrower_id=123&sort=-created_at", admin, "", nil, http.StatusOK)
+	lines := bytes.Split(bytes.TrimSpace(exported), []byte("\n"))
+	assert.Len(t, lines, 4)
+	for _, line := range lines {
+		var loan model.Loan
+		require.NoError(t, json.Unmarshal(line, &loan))
+		assert.Equal(t, int64(123), loan.BorrowerID)
+	}
+	do(http.MethodGet, "/loans/export?format=parquet", admin, "", nil, http.StatusOK)
+	do(http.MethodGet, "/loans/export?format=xlsx", admin, "", nil, http.StatusBadRequest)
+	do(http.MethodGet, "/loans/export", investor, "", nil, http.StatusForbidden)
+	do(http.MethodPost, "/loans/stream/token", investor, "", nil, http.StatusOK)
+
+	var partner struct {
+		Data struct {
+			Partner model.Partner `json:"partner"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(doJSON(http.MethodPost, "/partners", admin, `{"name":"Koperasi Maju"}`, http.StatusOK), &partner))
+	keysPath := fmt.Sprintf("/partners/%d/keys", partner.Data.Partner.ID)
+	var key struct {
+		Data struct {
+			Key model.PartnerKey `json:"key"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(doJSON(http.MethodPost, keysPath, admin, `{"scopes":["loans:invest"]}`, http.StatusOK), &key))
+	do(http.MethodGet, keysPath, admin, "", nil, http.StatusOK)
+	do(http.MethodDelete, keysPath+"/"+key.Data.Key.ID, admin, "", nil, http.StatusOK)
+
+	doJSON(http.MethodPost, "/webhooks", admin, `{"url":"https://partner.example/hooks","event_types":["loan.approved"]}`, http.StatusBadRequest)
+	var webhook struct {
+		Data struct {
+			Webhook model.WebhookSubscription `json:"webhook"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(doJSON(http.MethodPost, "/webhooks", admin,
+		fmt.Sprintf(`{"partner_id":%d,"url":"https://partner.example/hooks","event_types":["loan.approved","loan.disbursed"]}`, partner.Data.Partner.ID), http.StatusOK), &webhook))
+	webhookPath := fmt.Sprintf("/webhooks/%d", webhook.Data.Webhook.ID)
+	do(http.MethodGet, "/webhooks", admin, "", nil, http.StatusOK)
+	do(http.MethodGet, webhookPath, admin, "", nil, http.StatusOK)
+	do(http.MethodGet, "/webhooks/deliveries?status=failed", admin, "", nil, http.StatusOK)
+	do(http.MethodGet, "/webhooks", investor, "", nil, http.StatusForbidden)
+	do(http.MethodDelete, webhookPath, admin, "", nil, http.StatusOK)
+}
+
+// TestWebhooksDeliverLoanEvents has a partner subscribe, propose a loan and
+// receive the signed event at its own endpoint, which is local so insecure
+// webhooks are allowed.
+func TestWebhooksDeliverLoanEvents(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	a := newTestApplication(t, "webhook.allow_insecure=true")
+	e := a.router()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.webhooks.Run(ctx)
+
+	admin, err := a.auth.Issue(auth.Principal{ID: 1, Role: auth.RoleAdmin}, time.Hour)
+	require.NoError(t, err)
+
+	do := func(method, target string, header http.Header, body string, out any) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for name := range header {
+			req.Header.Set(name, header.Get(name))
+		}
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		if out != nil {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+		}
+	}
+	bearer := http.Header{echo.HeaderAuthorization: {"Bearer " + admin}}
+
+	var partner struct {
+		Data struct {
+			Partner model.Partner `json:"partner"`
+		} `json:"data"`
+	}
+	do(http.MethodPost, "/partners", bearer, `{"name":"Koperasi Maju"}`, &partner)
+	var key struct {
+		Data struct {
+			Key model.PartnerKey `json:"key"`
+		} `json:"data"`
+	}
+	do(http.MethodPost, fmt.Sprintf("/partners/%d/keys", partner.Data.Partner.ID), bearer, `{"scopes":["loans:create","webhooks:manage"]}`, &key)
+
+	nonce := 0
+	signed := func(method, path, body string) http.Header {
+		nonce++
+		timestamp := time.Now().Unix()
+		return http.Header{
+			auth.HeaderPartnerKey:       {key.Data.Key.ID},
+			auth.HeaderPartnerTimestamp: {strconv.FormatInt(timestamp, 10)},
+			auth.HeaderPartnerNonce:     {strconv.Itoa(nonce)},
+			auth.HeaderPartnerSignature: {auth.Sign(key.Data.Key.Secret, method, path, timestamp, strconv.Itoa(nonce), []byte(body))},
+		}
+	}
+
+	subscribe := fmt.Sprintf(`{"url":%q,"event_types":["loan.proposed"]}`, receiver.URL)
+	var webhook struct {
+		Data struct {
+			Webhook model.WebhookSubscription `json:"webhook"`
+		} `json:"data"`
+	}
+	do(http.MethodPost, "/webhooks", signed(http.MethodPost, "/webhooks", subscribe), subscribe, &webhook)
+	assert.Equal(t, partner.Data.Partner.ID, webhook.Data.Webhook.PartnerID)
+
+	propose := `{"borrower_id":123,"principal":100000,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/a"}`
+	do(http.MethodPost, "/loans", signed(http.MethodPost, "/loans", propose), propose, nil)
+
+	var r *http.Request
+	select {
+	case r = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	body := <-bodies
+	timestamp, err := strconv.ParseInt(r.Header.Get(webhookUsecase.HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, webhookUsecase.Sign(webhook.Data.Webhook.Secret, timestamp, body), r.Header.Get(webhookUsecase.HeaderSignature))
+	var event model.LoanEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, model.LoanEventProposed, event.Type)
+	assert.Equal(t, int64(123), event.Loan.BorrowerID)
+
+	var deliveries struct {
+		Data struct {
+			Deliveries []model.WebhookDelivery `json:"deliveries"`
+		} `json:"data"`
+	}
+	assert.Eventually(t, func() bool {
+		do(http.MethodGet, "/webhooks/deliveries", signed(http.MethodGet, "/webhooks/deliveries", ""), "", &deliveries)
+		return len(deliveries.Data.Deliveries) == 1 && deliveries.Data.Deliveries[0].Status == model.WebhookDeliverySucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestLoanStreamTakesStreamToken opens a stream the way a browser must, with
+// a stream token in the query instead of an Authorization header.
+func TestLoanStreamTakesStreamToken(t *testing.T) {
+	a := newTestApplication(t)
+	server := httptest.NewServer(a.handler())
+	defer server.Close()
+
+	bearer, err := a.auth.Issue(auth.Principal{ID: 789, Role: auth.RoleInvestor}, time.Hour)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/loans/stream/token", nil)
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+bearer)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	var token struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	open := func(path, token string) int {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path+"?access_token="+token, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, open("/loans/stream", token.Data.Token))
+	assert.Equal(t, http.StatusUnauthorized, open("/loans/stream", bearer), "bearer tokens are refused in the query")
+	assert.Equal(t, http.StatusUnauthorized, open("/loans", token.Data.Token), "stream tokens only open streams")
+}
+
+// TestGRPCSharesPort calls the gRPC service and the HTTP API on the same
+// listener and checks they see the same loans.
+func TestGRPCSharesPort(t *testing.T) {
+	a := newTestApplication(t)
+	server := httptest.NewServer(a.handler())
+	defer server.Close()
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(server.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := loanpb.NewLoanServiceClient(conn)
+
+	withToken := func(role auth.Role, id int64) context.Context {
+		token, err := a.auth.Issue(auth.Principal{ID: id, Role: role}, time.Hour)
+		require.NoError(t, err)
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	_, err = client.CreateLoan(context.Background(), &loanpb.CreateLoanRequest{Principal: 1000, Rate: 0.05, Roi: 0.07, AgreementLink: "https://example.com/a"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.CreateLoan(withToken(auth.RoleInvestor, 789), &loanpb.CreateLoanRequest{Principal: 1000, Rate: 0.05, Roi: 0.07, AgreementLink: "https://example.com/a"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	loan, err := client.CreateLoan(withToken(auth.RoleBorrower, 123), &loanpb.CreateLoanRequest{Principal: 1000, Rate: 0.05, Roi: 0.07, AgreementLink: "https://example.com/a"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(123), loan.GetBorrowerId())
+	assert.Equal(t, loanpb.LoanState_LOAN_STATE_PROPOSED, loan.GetState())
+
+	page, err := client.ListLoans(withToken(auth.RoleInvestor, 789), &loanpb.ListLoansRequest{State: loanpb.LoanState_LOAN_STATE_PROPOSED})
+	require.NoError(t, err)
+	require.Len(t, page.GetLoans(), 1)
+	assert.Equal(t, loan.GetId(), page.GetLoans()[0].GetId())
+
+	listServices := func(ctx context.Context) (*reflectionpb.ServerReflectionResponse, error) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		if err := stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+			return nil, err
+		}
+		return stream.Recv()
+	}
+	_, err = listServices(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "reflection needs a token")
+	services, err := listServices(withToken(auth.RoleInvestor, 789))
+	require.NoError(t, err)
+	var names []string
+	for _, service := range services.GetListServicesResponse().GetService() {
+		names = append(names, service.GetName())
+	}
+	assert.Contains(t, names, "loan.v1.LoanService")
+
+	token, err := a.auth.Issue(auth.Principal{ID: 123, Role: auth.RoleBorrower}, time.Hour)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/loans/%d", server.URL, loan.GetId()), nil)
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestLoanStreamEndsOnShutdown follows a loan over server-sent events and
+// checks a graceful shutdown closes the stream instead of waiting on it.
+func TestLoanStreamEndsOnShutdown(t *testing.T) {
+	a := newTestApplication(t)
+	server := a.server()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(listener)
+	url := "http://" + listener.Addr().String()
+
+	token := func(role auth.Role, id int64) string {
+		token, err := a.auth.Issue(auth.Principal{ID: id, Role: role}, time.Hour)
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	// bounds the test should the stream outlive the shutdown
+	streamCtx, cancelStream := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelStream()
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, url+"/loans/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAuthorization, token(auth.RoleInvestor, 789))
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+
+	req, err = http.NewRequest(http.MethodPost, url+"/loans", strings.NewReader(`{"principal":1000,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/a"}`))
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAuthorization, token(auth.RoleBorrower, 123))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	r := bufio.NewReader(stream.Body)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "id: "), line)
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: loan.proposed\n", line)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, server.Shutdown(ctx))
+	_, err = io.ReadAll(r)
+	assert.NoError(t, err)
+}
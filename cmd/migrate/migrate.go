@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/migration"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	var configFlags config.Flags
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the SQL database schema",
+	}
+	configFlags.Register(cmd.PersistentFlags())
+	run := func(ctx context.Context, fn func(ctx context.Context, m migration.Migrator) error) error {
+		return migrate(ctx, configFlags.Sources(), fn)
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Context(), func(ctx context.Context, m migration.Migrator) error {
+				applied, err := m.Up(ctx)
+				for _, migration := range applied {
+					fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+				}
+				if err == nil && len(applied) == 0 {
+					fmt.Println("schema is up to date")
+				}
+				return err
+			})
+		},
+	})
+
+	var steps int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the most recently applied migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Context(), func(ctx context.Context, m migration.Migrator) error {
+				rolledBack, err := m.Down(ctx, steps)
+				for _, migration := range rolledBack {
+					fmt.Printf("rolled back %d_%s\n", migration.Version, migration.Name)
+				}
+				return err
+			})
+		},
+	}
+	down.Flags().IntVar(&steps, "steps", 1, "number of migrations to roll back")
+	cmd.AddCommand(down)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status",
+		Short: "Show which migrations have been applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Context(), func(ctx context.Context, m migration.Migrator) error {
+				statuses, err := m.Status(ctx)
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+				for _, status := range statuses {
+					appliedAt := "pending"
+					if status.AppliedAt != nil {
+						appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+				}
+				return w.Flush()
+			})
+		},
+	})
+
+	return cmd
+}
+
+func migrate(ctx context.Context, sources config.Sources, fn func(ctx context.Context, m migration.Migrator) error) error {
+	cfg, err := config.Load(sources)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(cfg.Database.SQLitePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	m, err := migration.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return fn(ctx, m)
+}
@@ -0,0 +1,302 @@
+// Package grpc serves the loan lifecycle over gRPC. Requests are checked
+// against the same request structs, and errors mapped to the same outcomes,
+// as the HTTP handlers: invalid input is InvalidArgument, acting for someone
+// else is PermissionDenied and usecase failures are Internal.
+package grpc
+
+//go:generate protoc -I . --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative loanpb/loan.proto
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"loan_system/internal/delivery/grpc/loanpb"
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/usecase/loan"
+
+	"github.com/go-playground/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type LoanServer struct {
+	loanpb.UnimplementedLoanServiceServer
+	uc       loan.Usecase
+	validate *validator.Validate
+}
+
+func NewLoanServer(uc loan.Usecase) *LoanServer {
+	return &LoanServer{uc: uc, validate: validator.New()}
+}
+
+func (s *LoanServer) CreateLoan(ctx context.Context, in *loanpb.CreateLoanRequest) (*loanpb.Loan, error) {
+	req := request.CreateLoanRequest{
+		Principal:     in.GetPrincipal(),
+		BorrowerID:    in.GetBorrowerId(),
+		Rate:          in.GetRate(),
+		ROI:           in.GetRoi(),
+		AgreementLink: in.GetAgreementLink(),
+		TenorMonths:   int(in.GetTenorMonths()),
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	principal, err := authenticated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	borrowerID, err := onBehalfOf(principal, auth.RoleBorrower, req.BorrowerID, "borrower_id")
+	if err != nil {
+		return nil, err
+	}
+
+	loan := &model.Loan{
+		BorrowerID:    borrowerID,
+		Principal:     req.Principal,
+		Rate:          req.Rate,
+		ROI:           req.ROI,
+		AgreementLink: req.AgreementLink,
+		TenorMonths:   req.TenorMonths,
+	}
+	if err := s.uc.CreateLoan(ctx, loan); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toLoan(loan), nil
+}
+
+func (s *LoanServer) ApproveLoan(ctx context.Context, in *loanpb.ApproveLoanRequest) (*loanpb.Loan, error) {
+	req := request.ApproveLoanRequest{
+		ID:              in.GetId(),
+		ProofDocumentID: in.GetProofDocumentId(),
+		ApprovedAt:      fromTimestamp(in.GetApprovedAt()),
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	principal, err := authenticated(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	loan, err := s.uc.ApproveLoan(ctx, req.ID, model.Approval{
+		ValidatorID:     principal.ID,
+		ProofDocumentID: req.ProofDocumentID,
+		ApprovedAt:      req.ApprovedAt,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toLoan(loan), nil
+}
+
+func (s *LoanServer) AddInvestment(ctx context.Context, in *loanpb.AddInvestmentRequest) (*loanpb.Loan, error) {
+	req := request.InvestLoanRequest{
+		ID:         in.GetId(),
+		InvestorID: in.GetInvestorId(),
+		Amount:     in.GetAmount(),
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	principal, err := authenticated(ctx)
+	if err != nil {
+		return nil, err
+	}
+	investorID, err := onBehalfOf(principal, auth.RoleInvestor, req.InvestorID, "investor_id")
+	if err != nil {
+		return nil, err
+	}
+
+	loan, err := s.uc.AddInvestment(ctx, req.ID, model.Investment{
+		InvestorID: investorID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toLoan(loan), nil
+}
+
+func (s *LoanServer) DisburseLoan(ctx context.Context, in *loanpb.DisburseLoanRequest) (*loanpb.Loan, error) {
+	req := request.DisburseLoanRequest{
+		ID:                  in.GetId(),
+		AgreementDocumentID: in.GetAgreementDocumentId(),
+		DisbursedAt:         fromTimestamp(in.GetDisbursedAt()),
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	principal, err := authenticated(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	loan, err := s.uc.DisburseLoan(ctx, req.ID, model.Disbursement{
+		OfficerID:           principal.ID,
+		AgreementDocumentID: req.AgreementDocumentID,
+		DisbursedAt:         req.DisbursedAt,
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toLoan(loan), nil
+}
+
+func (s *LoanServer) GetLoan(ctx context.Context, in *loanpb.GetLoanRequest) (*loanpb.Loan, error) {
+	req := request.GetLoanRequest{ID: in.GetId()}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	loan, err := s.uc.FindByID(ctx, req.ID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toLoan(loan), nil
+}
+
+func (s *LoanServer) ListLoans(ctx context.Context, in *loanpb.ListLoansRequest) (*loanpb.ListLoansResponse, error) {
+	req := request.GetLoansRequest{
+		State:        fromLoanState(in.GetState()),
+		BorrowerID:   in.GetBorrowerId(),
+		InvestorID:   in.GetInvestorId(),
+		MinPrincipal: in.GetMinPrincipal(),
+		MaxPrincipal: in.GetMaxPrincipal(),
+		CreatedFrom:  fromTimestamp(in.GetCreatedFrom()),
+		CreatedTo:    fromTimestamp(in.GetCreatedTo()),
+		Sort:         in.GetSort(),
+		Cursor:       in.GetCursor(),
+		Limit:        int(in.GetLimit()),
+	}
+	if err := s.validate.Struct(req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	query := model.LoanQuery{
+		State:        model.LoanState(req.State),
+		BorrowerID:   req.BorrowerID,
+		InvestorID:   req.InvestorID,
+		MinPrincipal: req.MinPrincipal,
+		MaxPrincipal: req.MaxPrincipal,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+		SortBy:       model.LoanSortField(strings.TrimPrefix(req.Sort, "-")),
+		Descending:   strings.HasPrefix(req.Sort, "-"),
+		Limit:        req.Limit,
+	}
+	if req.Cursor != "" {
+		after, err := model.DecodeLoanCursor(req.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		query.After = after
+	}
+
+	page, err := s.uc.Find(ctx, query)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	loans := make([]*loanpb.Loan, 0, len(page.Loans))
+	for _, loan := range page.Loans {
+		loans = append(loans, toLoan(loan))
+	}
+	return &loanpb.ListLoansResponse{Loans: loans, NextCursor: page.NextCursor}, nil
+}
+
+// authenticated returns the caller attached by auth.UnaryServerInterceptor.
+func authenticated(ctx context.Context) (auth.Principal, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Principal{}, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	return principal, nil
+}
+
+// onBehalfOf answers the errors of auth.OnBehalfOf with PermissionDenied or
+// InvalidArgument.
+func onBehalfOf(principal auth.Principal, role auth.Role, id int64, field string) (int64, error) {
+	id, err := auth.OnBehalfOf(principal, role, id, field)
+	switch {
+	case errors.Is(err, auth.ErrOnlySelf):
+		return 0, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return id, nil
+}
+
+func toLoan(loan *model.Loan) *loanpb.Loan {
+	out := &loanpb.Loan{
+		Id:            loan.ID,
+		BorrowerId:    loan.BorrowerID,
+		Principal:     loan.Principal,
+		Rate:          loan.Rate,
+		Roi:           loan.ROI,
+		State:         toLoanState(loan.State),
+		AgreementLink: loan.AgreementLink,
+		TenorMonths:   int32(loan.TenorMonths),
+		CreatedAt:     toTimestamp(loan.CreatedAt),
+		PartnerId:     loan.PartnerID,
+	}
+	if loan.Approval != nil {
+		out.Approval = &loanpb.Approval{
+			ValidatorId:     loan.Approval.ValidatorID,
+			ProofDocumentId: loan.Approval.ProofDocumentID,
+			ApprovedAt:      toTimestamp(loan.Approval.ApprovedAt),
+		}
+	}
+	for _, investment := range loan.Investments {
+		out.Investments = append(out.Investments, &loanpb.Investment{
+			InvestorId: investment.InvestorID,
+			Amount:     investment.Amount,
+			InvestedAt: toTimestamp(investment.InvestedAt),
+			PartnerId:  investment.PartnerID,
+		})
+	}
+	if loan.Disbursement != nil {
+		out.Disbursement = &loanpb.Disbursement{
+			OfficerId:           loan.Disbursement.OfficerID,
+			AgreementDocumentId: loan.Disbursement.AgreementDocumentID,
+			DisbursedAt:         toTimestamp(loan.Disbursement.DisbursedAt),
+		}
+	}
+	return out
+}
+
+func toLoanState(state model.LoanState) loanpb.LoanState {
+	return loanpb.LoanState(loanpb.LoanState_value["LOAN_STATE_"+string(state)])
+}
+
+// fromLoanState returns "" for an unspecified state, matching any state.
+func fromLoanState(state loanpb.LoanState) string {
+	if state == loanpb.LoanState_LOAN_STATE_UNSPECIFIED {
+		return ""
+	}
+	return strings.TrimPrefix(state.String(), "LOAN_STATE_")
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromTimestamp returns the zero time for an unset timestamp, which the
+// usecase treats as now or as no bound.
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
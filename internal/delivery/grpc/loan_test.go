@@ -0,0 +1,268 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	grpcHandler "loan_system/internal/delivery/grpc"
+	"loan_system/internal/delivery/grpc/loanpb"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
+	loanmock "loan_system/internal/usecase/loan/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// withPrincipal authenticates ctx as auth.UnaryServerInterceptor would.
+func withPrincipal(role auth.Role, id int64) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{ID: id, Role: role})
+}
+
+func TestCreateLoan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	server := grpcHandler.NewLoanServer(mockUsecase)
+
+	valid := func() *loanpb.CreateLoanRequest {
+		return &loanpb.CreateLoanRequest{Principal: 10000, Rate: 5, Roi: 6, AgreementLink: "https://example.com/agreement.pdf", TenorMonths: 6}
+	}
+
+	t.Run("successful creation", func(t *testing.T) {
+		mockUsecase.EXPECT().CreateLoan(gomock.Any(), &model.Loan{
+			BorrowerID:    1234,
+			Principal:     10000,
+			Rate:          5,
+			ROI:           6,
+			AgreementLink: "https://example.com/agreement.pdf",
+			TenorMonths:   6,
+		}).DoAndReturn(func(_ context.Context, loan *model.Loan) error {
+			loan.ID = 1
+			loan.State = model.StateProposed
+			loan.CreatedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			return nil
+		})
+
+		loan, err := server.CreateLoan(withPrincipal(auth.RoleBorrower, 1234), valid())
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), loan.GetId())
+		assert.Equal(t, loanpb.LoanState_LOAN_STATE_PROPOSED, loan.GetState())
+		assert.Equal(t, "2025-01-01T00:00:00Z", loan.GetCreatedAt().AsTime().Format(time.RFC3339))
+		assert.Nil(t, loan.GetApproval())
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		req := valid()
+		req.Principal = 0
+
+		_, err := server.CreateLoan(withPrincipal(auth.RoleBorrower, 1234), req)
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("borrower proposing for someone else", func(t *testing.T) {
+		req := valid()
+		req.BorrowerId = 99
+
+		_, err := server.CreateLoan(withPrincipal(auth.RoleBorrower, 1234), req)
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("admin without borrower", func(t *testing.T) {
+		_, err := server.CreateLoan(withPrincipal(auth.RoleAdmin, 1), valid())
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.ErrorContains(t, err, "borrower_id is required")
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := server.CreateLoan(context.Background(), valid())
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		mockUsecase.EXPECT().CreateLoan(gomock.Any(), gomock.Any()).Return(errors.New("loan already exists"))
+
+		_, err := server.CreateLoan(withPrincipal(auth.RoleBorrower, 1234), valid())
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestApproveLoan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	server := grpcHandler.NewLoanServer(mockUsecase)
+	approvedAt := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	t.Run("validator comes from the token", func(t *testing.T) {
+		mockUsecase.EXPECT().ApproveLoan(gomock.Any(), int64(1), model.Approval{
+			ValidatorID:     456,
+			ProofDocumentID: 10,
+			ApprovedAt:      approvedAt,
+		}).Return(&model.Loan{ID: 1, State: model.StateApproved, Approval: &model.Approval{ValidatorID: 456, ProofDocumentID: 10, ApprovedAt: approvedAt}}, nil)
+
+		loan, err := server.ApproveLoan(withPrincipal(auth.RoleFieldValidator, 456), &loanpb.ApproveLoanRequest{
+			Id:              1,
+			ProofDocumentId: 10,
+			ApprovedAt:      timestamppb.New(approvedAt),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(456), loan.GetApproval().GetValidatorId())
+		assert.Equal(t, loanpb.LoanState_LOAN_STATE_APPROVED, loan.GetState())
+	})
+
+	t.Run("missing proof", func(t *testing.T) {
+		_, err := server.ApproveLoan(withPrincipal(auth.RoleFieldValidator, 456), &loanpb.ApproveLoanRequest{Id: 1})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		mockUsecase.EXPECT().ApproveLoan(gomock.Any(), int64(1), gomock.Any()).Return(nil, errors.New("can only approve when loan is proposed"))
+
+		_, err := server.ApproveLoan(withPrincipal(auth.RoleFieldValidator, 456), &loanpb.ApproveLoanRequest{Id: 1, ProofDocumentId: 10})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestAddInvestment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	server := grpcHandler.NewLoanServer(mockUsecase)
+
+	t.Run("investor comes from the token", func(t *testing.T) {
+		mockUsecase.EXPECT().AddInvestment(gomock.Any(), int64(1), model.Investment{InvestorID: 789, Amount: 500}).
+			Return(&model.Loan{ID: 1, State: model.StateApproved, Investments: []model.Investment{{InvestorID: 789, Amount: 500}}}, nil)
+
+		loan, err := server.AddInvestment(withPrincipal(auth.RoleInvestor, 789), &loanpb.AddInvestmentRequest{Id: 1, Amount: 500})
+
+		require.NoError(t, err)
+		require.Len(t, loan.GetInvestments(), 1)
+		assert.Equal(t, int64(789), loan.GetInvestments()[0].GetInvestorId())
+		assert.Nil(t, loan.GetInvestments()[0].GetInvestedAt())
+	})
+
+	t.Run("admin on behalf of an investor", func(t *testing.T) {
+		mockUsecase.EXPECT().AddInvestment(gomock.Any(), int64(1), model.Investment{InvestorID: 42, Amount: 500}).
+			Return(&model.Loan{ID: 1}, nil)
+
+		_, err := server.AddInvestment(withPrincipal(auth.RoleAdmin, 1), &loanpb.AddInvestmentRequest{Id: 1, InvestorId: 42, Amount: 500})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("investor investing for someone else", func(t *testing.T) {
+		_, err := server.AddInvestment(withPrincipal(auth.RoleInvestor, 789), &loanpb.AddInvestmentRequest{Id: 1, InvestorId: 42, Amount: 500})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		_, err := server.AddInvestment(withPrincipal(auth.RoleInvestor, 789), &loanpb.AddInvestmentRequest{Id: 1, Amount: -1})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestDisburseLoan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	server := grpcHandler.NewLoanServer(mockUsecase)
+
+	t.Run("officer comes from the token", func(t *testing.T) {
+		mockUsecase.EXPECT().DisburseLoan(gomock.Any(), int64(1), model.Disbursement{OfficerID: 101, AgreementDocumentID: 20}).
+			Return(&model.Loan{ID: 1, State: model.StateDisbursed, Disbursement: &model.Disbursement{OfficerID: 101, AgreementDocumentID: 20}}, nil)
+
+		loan, err := server.DisburseLoan(withPrincipal(auth.RoleFieldOfficer, 101), &loanpb.DisburseLoanRequest{Id: 1, AgreementDocumentId: 20})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(101), loan.GetDisbursement().GetOfficerId())
+		assert.Equal(t, loanpb.LoanState_LOAN_STATE_DISBURSED, loan.GetState())
+	})
+
+	t.Run("missing agreement", func(t *testing.T) {
+		_, err := server.DisburseLoan(withPrincipal(auth.RoleFieldOfficer, 101), &loanpb.DisburseLoanRequest{Id: 1})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestGetLoan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	server := grpcHandler.NewLoanServer(mockUsecase)
+
+	t.Run("found", func(t *testing.T) {
+		mockUsecase.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateInvested}, nil)
+
+		loan, err := server.GetLoan(withPrincipal(auth.RoleBorrower, 1234), &loanpb.GetLoanRequest{Id: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, loanpb.LoanState_LOAN_STATE_INVESTED, loan.GetState())
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		_, err := server.GetLoan(withPrincipal(auth.RoleBorrower, 1234), &loanpb.GetLoanRequest{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		mockUsecase.EXPECT().FindByID(gomock.Any(), int64(2)).Return(nil, errors.New("loan not found"))
+
+		_, err := server.GetLoan(withPrincipal(auth.RoleBorrower, 1234), &loanpb.GetLoanRequest{Id: 2})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestListLoans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	server := grpcHandler.NewLoanServer(mockUsecase)
+
+	t.Run("filters and sorts", func(t *testing.T) {
+		mockUsecase.EXPECT().Find(gomock.Any(), model.LoanQuery{
+			State:        model.StateApproved,
+			MinPrincipal: 1000,
+			SortBy:       model.LoanSortPrincipal,
+			Descending:   true,
+			Limit:        20,
+		}).Return(model.LoanPage{Loans: []*model.Loan{{ID: 1}, {ID: 2}}, NextCursor: "next"}, nil)
+
+		resp, err := server.ListLoans(withPrincipal(auth.RoleInvestor, 789), &loanpb.ListLoansRequest{
+			State:        loanpb.LoanState_LOAN_STATE_APPROVED,
+			MinPrincipal: 1000,
+			Sort:         "-principal",
+			Limit:        20,
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, resp.GetLoans(), 2)
+		assert.Equal(t, "next", resp.GetNextCursor())
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		_, err := server.ListLoans(withPrincipal(auth.RoleInvestor, 789), &loanpb.ListLoansRequest{Sort: "rate"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := server.ListLoans(withPrincipal(auth.RoleInvestor, 789), &loanpb.ListLoansRequest{Cursor: "not a cursor"})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,1083 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: loanpb/loan.proto
+
+package loanpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LoanState int32
+
+const (
+	LoanState_LOAN_STATE_UNSPECIFIED LoanState = 0
+	LoanState_LOAN_STATE_PROPOSED    LoanState = 1
+	LoanState_LOAN_STATE_APPROVED    LoanState = 2
+	LoanState_LOAN_STATE_INVESTED    LoanState = 3
+	LoanState_LOAN_STATE_DISBURSED   LoanState = 4
+)
+
+// Enum value maps for LoanState.
+var (
+	LoanState_name = map[int32]string{
+		0: "LOAN_STATE_UNSPECIFIED",
+		1: "LOAN_STATE_PROPOSED",
+		2: "LOAN_STATE_APPROVED",
+		3: "LOAN_STATE_INVESTED",
+		4: "LOAN_STATE_DISBURSED",
+	}
+	LoanState_value = map[string]int32{
+		"LOAN_STATE_UNSPECIFIED": 0,
+		"LOAN_STATE_PROPOSED":    1,
+		"LOAN_STATE_APPROVED":    2,
+		"LOAN_STATE_INVESTED":    3,
+		"LOAN_STATE_DISBURSED":   4,
+	}
+)
+
+func (x LoanState) Enum() *LoanState {
+	p := new(LoanState)
+	*p = x
+	return p
+}
+
+func (x LoanState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LoanState) Descriptor() protoreflect.EnumDescriptor {
+	return file_loanpb_loan_proto_enumTypes[0].Descriptor()
+}
+
+func (LoanState) Type() protoreflect.EnumType {
+	return &file_loanpb_loan_proto_enumTypes[0]
+}
+
+func (x LoanState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LoanState.Descriptor instead.
+func (LoanState) EnumDescriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{0}
+}
+
+type Loan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	BorrowerId    int64                  `protobuf:"varint,2,opt,name=borrower_id,json=borrowerId,proto3" json:"borrower_id,omitempty"`
+	Principal     float64                `protobuf:"fixed64,3,opt,name=principal,proto3" json:"principal,omitempty"`
+	Rate          float64                `protobuf:"fixed64,4,opt,name=rate,proto3" json:"rate,omitempty"`
+	Roi           float64                `protobuf:"fixed64,5,opt,name=roi,proto3" json:"roi,omitempty"`
+	State         LoanState              `protobuf:"varint,6,opt,name=state,proto3,enum=loan.v1.LoanState" json:"state,omitempty"`
+	Approval      *Approval              `protobuf:"bytes,7,opt,name=approval,proto3" json:"approval,omitempty"`
+	Investments   []*Investment          `protobuf:"bytes,8,rep,name=investments,proto3" json:"investments,omitempty"`
+	Disbursement  *Disbursement          `protobuf:"bytes,9,opt,name=disbursement,proto3" json:"disbursement,omitempty"`
+	AgreementLink string                 `protobuf:"bytes,10,opt,name=agreement_link,json=agreementLink,proto3" json:"agreement_link,omitempty"`
+	TenorMonths   int32                  `protobuf:"varint,11,opt,name=tenor_months,json=tenorMonths,proto3" json:"tenor_months,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Set when a partner proposed the loan.
+	PartnerId     int64 `protobuf:"varint,13,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Loan) Reset() {
+	*x = Loan{}
+	mi := &file_loanpb_loan_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Loan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Loan) ProtoMessage() {}
+
+func (x *Loan) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Loan.ProtoReflect.Descriptor instead.
+func (*Loan) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Loan) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Loan) GetBorrowerId() int64 {
+	if x != nil {
+		return x.BorrowerId
+	}
+	return 0
+}
+
+func (x *Loan) GetPrincipal() float64 {
+	if x != nil {
+		return x.Principal
+	}
+	return 0
+}
+
+func (x *Loan) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *Loan) GetRoi() float64 {
+	if x != nil {
+		return x.Roi
+	}
+	return 0
+}
+
+func (x *Loan) GetState() LoanState {
+	if x != nil {
+		return x.State
+	}
+	return LoanState_LOAN_STATE_UNSPECIFIED
+}
+
+func (x *Loan) GetApproval() *Approval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+func (x *Loan) GetInvestments() []*Investment {
+	if x != nil {
+		return x.Investments
+	}
+	return nil
+}
+
+func (x *Loan) GetDisbursement() *Disbursement {
+	if x != nil {
+		return x.Disbursement
+	}
+	return nil
+}
+
+func (x *Loan) GetAgreementLink() string {
+	if x != nil {
+		return x.AgreementLink
+	}
+	return ""
+}
+
+func (x *Loan) GetTenorMonths() int32 {
+	if x != nil {
+		return x.TenorMonths
+	}
+	return 0
+}
+
+func (x *Loan) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Loan) GetPartnerId() int64 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+type Approval struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ValidatorId     int64                  `protobuf:"varint,1,opt,name=validator_id,json=validatorId,proto3" json:"validator_id,omitempty"`
+	ProofDocumentId int64                  `protobuf:"varint,2,opt,name=proof_document_id,json=proofDocumentId,proto3" json:"proof_document_id,omitempty"`
+	ApprovedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=approved_at,json=approvedAt,proto3" json:"approved_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Approval) Reset() {
+	*x = Approval{}
+	mi := &file_loanpb_loan_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Approval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Approval) ProtoMessage() {}
+
+func (x *Approval) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Approval.ProtoReflect.Descriptor instead.
+func (*Approval) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Approval) GetValidatorId() int64 {
+	if x != nil {
+		return x.ValidatorId
+	}
+	return 0
+}
+
+func (x *Approval) GetProofDocumentId() int64 {
+	if x != nil {
+		return x.ProofDocumentId
+	}
+	return 0
+}
+
+func (x *Approval) GetApprovedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ApprovedAt
+	}
+	return nil
+}
+
+type Investment struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	InvestorId int64                  `protobuf:"varint,1,opt,name=investor_id,json=investorId,proto3" json:"investor_id,omitempty"`
+	Amount     float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	InvestedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=invested_at,json=investedAt,proto3" json:"invested_at,omitempty"`
+	// Set when a partner made the investment.
+	PartnerId     int64 `protobuf:"varint,4,opt,name=partner_id,json=partnerId,proto3" json:"partner_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Investment) Reset() {
+	*x = Investment{}
+	mi := &file_loanpb_loan_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Investment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Investment) ProtoMessage() {}
+
+func (x *Investment) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Investment.ProtoReflect.Descriptor instead.
+func (*Investment) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Investment) GetInvestorId() int64 {
+	if x != nil {
+		return x.InvestorId
+	}
+	return 0
+}
+
+func (x *Investment) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Investment) GetInvestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.InvestedAt
+	}
+	return nil
+}
+
+func (x *Investment) GetPartnerId() int64 {
+	if x != nil {
+		return x.PartnerId
+	}
+	return 0
+}
+
+type Disbursement struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	OfficerId           int64                  `protobuf:"varint,1,opt,name=officer_id,json=officerId,proto3" json:"officer_id,omitempty"`
+	AgreementDocumentId int64                  `protobuf:"varint,2,opt,name=agreement_document_id,json=agreementDocumentId,proto3" json:"agreement_document_id,omitempty"`
+	DisbursedAt         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=disbursed_at,json=disbursedAt,proto3" json:"disbursed_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Disbursement) Reset() {
+	*x = Disbursement{}
+	mi := &file_loanpb_loan_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Disbursement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Disbursement) ProtoMessage() {}
+
+func (x *Disbursement) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Disbursement.ProtoReflect.Descriptor instead.
+func (*Disbursement) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Disbursement) GetOfficerId() int64 {
+	if x != nil {
+		return x.OfficerId
+	}
+	return 0
+}
+
+func (x *Disbursement) GetAgreementDocumentId() int64 {
+	if x != nil {
+		return x.AgreementDocumentId
+	}
+	return 0
+}
+
+func (x *Disbursement) GetDisbursedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DisbursedAt
+	}
+	return nil
+}
+
+type CreateLoanRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Principal float64                `protobuf:"fixed64,1,opt,name=principal,proto3" json:"principal,omitempty"`
+	// Required for admins, taken from the token for borrowers.
+	BorrowerId    int64   `protobuf:"varint,2,opt,name=borrower_id,json=borrowerId,proto3" json:"borrower_id,omitempty"`
+	Rate          float64 `protobuf:"fixed64,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	Roi           float64 `protobuf:"fixed64,4,opt,name=roi,proto3" json:"roi,omitempty"`
+	AgreementLink string  `protobuf:"bytes,5,opt,name=agreement_link,json=agreementLink,proto3" json:"agreement_link,omitempty"`
+	// Defaults to 12.
+	TenorMonths   int32 `protobuf:"varint,6,opt,name=tenor_months,json=tenorMonths,proto3" json:"tenor_months,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLoanRequest) Reset() {
+	*x = CreateLoanRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLoanRequest) ProtoMessage() {}
+
+func (x *CreateLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLoanRequest.ProtoReflect.Descriptor instead.
+func (*CreateLoanRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateLoanRequest) GetPrincipal() float64 {
+	if x != nil {
+		return x.Principal
+	}
+	return 0
+}
+
+func (x *CreateLoanRequest) GetBorrowerId() int64 {
+	if x != nil {
+		return x.BorrowerId
+	}
+	return 0
+}
+
+func (x *CreateLoanRequest) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *CreateLoanRequest) GetRoi() float64 {
+	if x != nil {
+		return x.Roi
+	}
+	return 0
+}
+
+func (x *CreateLoanRequest) GetAgreementLink() string {
+	if x != nil {
+		return x.AgreementLink
+	}
+	return ""
+}
+
+func (x *CreateLoanRequest) GetTenorMonths() int32 {
+	if x != nil {
+		return x.TenorMonths
+	}
+	return 0
+}
+
+// The validator is the field validator in the token.
+type ApproveLoanRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProofDocumentId int64                  `protobuf:"varint,2,opt,name=proof_document_id,json=proofDocumentId,proto3" json:"proof_document_id,omitempty"`
+	// Defaults to now.
+	ApprovedAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=approved_at,json=approvedAt,proto3" json:"approved_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveLoanRequest) Reset() {
+	*x = ApproveLoanRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveLoanRequest) ProtoMessage() {}
+
+func (x *ApproveLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveLoanRequest.ProtoReflect.Descriptor instead.
+func (*ApproveLoanRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ApproveLoanRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ApproveLoanRequest) GetProofDocumentId() int64 {
+	if x != nil {
+		return x.ProofDocumentId
+	}
+	return 0
+}
+
+func (x *ApproveLoanRequest) GetApprovedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ApprovedAt
+	}
+	return nil
+}
+
+type AddInvestmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Required for admins, taken from the token for investors.
+	InvestorId    int64   `protobuf:"varint,2,opt,name=investor_id,json=investorId,proto3" json:"investor_id,omitempty"`
+	Amount        float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddInvestmentRequest) Reset() {
+	*x = AddInvestmentRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddInvestmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddInvestmentRequest) ProtoMessage() {}
+
+func (x *AddInvestmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddInvestmentRequest.ProtoReflect.Descriptor instead.
+func (*AddInvestmentRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddInvestmentRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AddInvestmentRequest) GetInvestorId() int64 {
+	if x != nil {
+		return x.InvestorId
+	}
+	return 0
+}
+
+func (x *AddInvestmentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+// The officer is the field officer in the token.
+type DisburseLoanRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AgreementDocumentId int64                  `protobuf:"varint,2,opt,name=agreement_document_id,json=agreementDocumentId,proto3" json:"agreement_document_id,omitempty"`
+	// Defaults to now.
+	DisbursedAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=disbursed_at,json=disbursedAt,proto3" json:"disbursed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisburseLoanRequest) Reset() {
+	*x = DisburseLoanRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisburseLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisburseLoanRequest) ProtoMessage() {}
+
+func (x *DisburseLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisburseLoanRequest.ProtoReflect.Descriptor instead.
+func (*DisburseLoanRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DisburseLoanRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DisburseLoanRequest) GetAgreementDocumentId() int64 {
+	if x != nil {
+		return x.AgreementDocumentId
+	}
+	return 0
+}
+
+func (x *DisburseLoanRequest) GetDisbursedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DisbursedAt
+	}
+	return nil
+}
+
+type GetLoanRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLoanRequest) Reset() {
+	*x = GetLoanRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLoanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLoanRequest) ProtoMessage() {}
+
+func (x *GetLoanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLoanRequest.ProtoReflect.Descriptor instead.
+func (*GetLoanRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetLoanRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListLoansRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	State        LoanState              `protobuf:"varint,1,opt,name=state,proto3,enum=loan.v1.LoanState" json:"state,omitempty"`
+	BorrowerId   int64                  `protobuf:"varint,2,opt,name=borrower_id,json=borrowerId,proto3" json:"borrower_id,omitempty"`
+	InvestorId   int64                  `protobuf:"varint,3,opt,name=investor_id,json=investorId,proto3" json:"investor_id,omitempty"`
+	MinPrincipal float64                `protobuf:"fixed64,4,opt,name=min_principal,json=minPrincipal,proto3" json:"min_principal,omitempty"`
+	MaxPrincipal float64                `protobuf:"fixed64,5,opt,name=max_principal,json=maxPrincipal,proto3" json:"max_principal,omitempty"`
+	CreatedFrom  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`
+	CreatedTo    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`
+	// One of id, principal or created_at, prefixed with "-" for descending
+	// order.
+	Sort string `protobuf:"bytes,8,opt,name=sort,proto3" json:"sort,omitempty"`
+	// The next_cursor of the previous page.
+	Cursor        string `protobuf:"bytes,9,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLoansRequest) Reset() {
+	*x = ListLoansRequest{}
+	mi := &file_loanpb_loan_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLoansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLoansRequest) ProtoMessage() {}
+
+func (x *ListLoansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLoansRequest.ProtoReflect.Descriptor instead.
+func (*ListLoansRequest) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListLoansRequest) GetState() LoanState {
+	if x != nil {
+		return x.State
+	}
+	return LoanState_LOAN_STATE_UNSPECIFIED
+}
+
+func (x *ListLoansRequest) GetBorrowerId() int64 {
+	if x != nil {
+		return x.BorrowerId
+	}
+	return 0
+}
+
+func (x *ListLoansRequest) GetInvestorId() int64 {
+	if x != nil {
+		return x.InvestorId
+	}
+	return 0
+}
+
+func (x *ListLoansRequest) GetMinPrincipal() float64 {
+	if x != nil {
+		return x.MinPrincipal
+	}
+	return 0
+}
+
+func (x *ListLoansRequest) GetMaxPrincipal() float64 {
+	if x != nil {
+		return x.MaxPrincipal
+	}
+	return 0
+}
+
+func (x *ListLoansRequest) GetCreatedFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return nil
+}
+
+func (x *ListLoansRequest) GetCreatedTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return nil
+}
+
+func (x *ListLoansRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListLoansRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListLoansRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListLoansResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Loans []*Loan                `protobuf:"bytes,1,rep,name=loans,proto3" json:"loans,omitempty"`
+	// Empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLoansResponse) Reset() {
+	*x = ListLoansResponse{}
+	mi := &file_loanpb_loan_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLoansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLoansResponse) ProtoMessage() {}
+
+func (x *ListLoansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_loanpb_loan_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLoansResponse.ProtoReflect.Descriptor instead.
+func (*ListLoansResponse) Descriptor() ([]byte, []int) {
+	return file_loanpb_loan_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListLoansResponse) GetLoans() []*Loan {
+	if x != nil {
+		return x.Loans
+	}
+	return nil
+}
+
+func (x *ListLoansResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+var File_loanpb_loan_proto protoreflect.FileDescriptor
+
+const file_loanpb_loan_proto_rawDesc = "" +
+	"\n" +
+	"\x11loanpb/loan.proto\x12\aloan.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xea\x03\n" +
+	"\x04Loan\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\vborrower_id\x18\x02 \x01(\x03R\n" +
+	"borrowerId\x12\x1c\n" +
+	"\tprincipal\x18\x03 \x01(\x01R\tprincipal\x12\x12\n" +
+	"\x04rate\x18\x04 \x01(\x01R\x04rate\x12\x10\n" +
+	"\x03roi\x18\x05 \x01(\x01R\x03roi\x12(\n" +
+	"\x05state\x18\x06 \x01(\x0e2\x12.loan.v1.LoanStateR\x05state\x12-\n" +
+	"\bapproval\x18\a \x01(\v2\x11.loan.v1.ApprovalR\bapproval\x125\n" +
+	"\vinvestments\x18\b \x03(\v2\x13.loan.v1.InvestmentR\vinvestments\x129\n" +
+	"\fdisbursement\x18\t \x01(\v2\x15.loan.v1.DisbursementR\fdisbursement\x12%\n" +
+	"\x0eagreement_link\x18\n" +
+	" \x01(\tR\ragreementLink\x12!\n" +
+	"\ftenor_months\x18\v \x01(\x05R\vtenorMonths\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\r \x01(\x03R\tpartnerId\"\x96\x01\n" +
+	"\bApproval\x12!\n" +
+	"\fvalidator_id\x18\x01 \x01(\x03R\vvalidatorId\x12*\n" +
+	"\x11proof_document_id\x18\x02 \x01(\x03R\x0fproofDocumentId\x12;\n" +
+	"\vapproved_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"approvedAt\"\xa1\x01\n" +
+	"\n" +
+	"Investment\x12\x1f\n" +
+	"\vinvestor_id\x18\x01 \x01(\x03R\n" +
+	"investorId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12;\n" +
+	"\vinvested_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"investedAt\x12\x1d\n" +
+	"\n" +
+	"partner_id\x18\x04 \x01(\x03R\tpartnerId\"\xa0\x01\n" +
+	"\fDisbursement\x12\x1d\n" +
+	"\n" +
+	"officer_id\x18\x01 \x01(\x03R\tofficerId\x122\n" +
+	"\x15agreement_document_id\x18\x02 \x01(\x03R\x13agreementDocumentId\x12=\n" +
+	"\fdisbursed_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vdisbursedAt\"\xc2\x01\n" +
+	"\x11CreateLoanRequest\x12\x1c\n" +
+	"\tprincipal\x18\x01 \x01(\x01R\tprincipal\x12\x1f\n" +
+	"\vborrower_id\x18\x02 \x01(\x03R\n" +
+	"borrowerId\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\x01R\x04rate\x12\x10\n" +
+	"\x03roi\x18\x04 \x01(\x01R\x03roi\x12%\n" +
+	"\x0eagreement_link\x18\x05 \x01(\tR\ragreementLink\x12!\n" +
+	"\ftenor_months\x18\x06 \x01(\x05R\vtenorMonths\"\x8d\x01\n" +
+	"\x12ApproveLoanRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12*\n" +
+	"\x11proof_document_id\x18\x02 \x01(\x03R\x0fproofDocumentId\x12;\n" +
+	"\vapproved_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"approvedAt\"_\n" +
+	"\x14AddInvestmentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\vinvestor_id\x18\x02 \x01(\x03R\n" +
+	"investorId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\"\x98\x01\n" +
+	"\x13DisburseLoanRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x122\n" +
+	"\x15agreement_document_id\x18\x02 \x01(\x03R\x13agreementDocumentId\x12=\n" +
+	"\fdisbursed_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vdisbursedAt\" \n" +
+	"\x0eGetLoanRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x84\x03\n" +
+	"\x10ListLoansRequest\x12(\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x12.loan.v1.LoanStateR\x05state\x12\x1f\n" +
+	"\vborrower_id\x18\x02 \x01(\x03R\n" +
+	"borrowerId\x12\x1f\n" +
+	"\vinvestor_id\x18\x03 \x01(\x03R\n" +
+	"investorId\x12#\n" +
+	"\rmin_principal\x18\x04 \x01(\x01R\fminPrincipal\x12#\n" +
+	"\rmax_principal\x18\x05 \x01(\x01R\fmaxPrincipal\x12=\n" +
+	"\fcreated_from\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcreatedFrom\x129\n" +
+	"\n" +
+	"created_to\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedTo\x12\x12\n" +
+	"\x04sort\x18\b \x01(\tR\x04sort\x12\x16\n" +
+	"\x06cursor\x18\t \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\"Y\n" +
+	"\x11ListLoansResponse\x12#\n" +
+	"\x05loans\x18\x01 \x03(\v2\r.loan.v1.LoanR\x05loans\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor*\x8c\x01\n" +
+	"\tLoanState\x12\x1a\n" +
+	"\x16LOAN_STATE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13LOAN_STATE_PROPOSED\x10\x01\x12\x17\n" +
+	"\x13LOAN_STATE_APPROVED\x10\x02\x12\x17\n" +
+	"\x13LOAN_STATE_INVESTED\x10\x03\x12\x18\n" +
+	"\x14LOAN_STATE_DISBURSED\x10\x042\xf4\x02\n" +
+	"\vLoanService\x127\n" +
+	"\n" +
+	"CreateLoan\x12\x1a.loan.v1.CreateLoanRequest\x1a\r.loan.v1.Loan\x129\n" +
+	"\vApproveLoan\x12\x1b.loan.v1.ApproveLoanRequest\x1a\r.loan.v1.Loan\x12=\n" +
+	"\rAddInvestment\x12\x1d.loan.v1.AddInvestmentRequest\x1a\r.loan.v1.Loan\x12;\n" +
+	"\fDisburseLoan\x12\x1c.loan.v1.DisburseLoanRequest\x1a\r.loan.v1.Loan\x121\n" +
+	"\aGetLoan\x12\x17.loan.v1.GetLoanRequest\x1a\r.loan.v1.Loan\x12B\n" +
+	"\tListLoans\x12\x19.loan.v1.ListLoansRequest\x1a\x1a.loan.v1.ListLoansResponseB+Z)loan_system/internal/delivery/grpc/loanpbb\x06proto3"
+
+var (
+	file_loanpb_loan_proto_rawDescOnce sync.Once
+	file_loanpb_loan_proto_rawDescData []byte
+)
+
+func file_loanpb_loan_proto_rawDescGZIP() []byte {
+	file_loanpb_loan_proto_rawDescOnce.Do(func() {
+		file_loanpb_loan_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_loanpb_loan_proto_rawDesc), len(file_loanpb_loan_proto_rawDesc)))
+	})
+	return file_loanpb_loan_proto_rawDescData
+}
+
+var file_loanpb_loan_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_loanpb_loan_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_loanpb_loan_proto_goTypes = []any{
+	(LoanState)(0),                // 0: loan.v1.LoanState
+	(*Loan)(nil),                  // 1: loan.v1.Loan
+	(*Approval)(nil),              // 2: loan.v1.Approval
+	(*Investment)(nil),            // 3: loan.v1.Investment
+	(*Disbursement)(nil),          // 4: loan.v1.Disbursement
+	(*CreateLoanRequest)(nil),     // 5: loan.v1.CreateLoanRequest
+	(*ApproveLoanRequest)(nil),    // 6: loan.v1.ApproveLoanRequest
+	(*AddInvestmentRequest)(nil),  // 7: loan.v1.AddInvestmentRequest
+	(*DisburseLoanRequest)(nil),   // 8: loan.v1.DisburseLoanRequest
+	(*GetLoanRequest)(nil),        // 9: loan.v1.GetLoanRequest
+	(*ListLoansRequest)(nil),      // 10: loan.v1.ListLoansRequest
+	(*ListLoansResponse)(nil),     // 11: loan.v1.ListLoansResponse
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_loanpb_loan_proto_depIdxs = []int32{
+	0,  // 0: loan.v1.Loan.state:type_name -> loan.v1.LoanState
+	2,  // 1: loan.v1.Loan.approval:type_name -> loan.v1.Approval
+	3,  // 2: loan.v1.Loan.investments:type_name -> loan.v1.Investment
+	4,  // 3: loan.v1.Loan.disbursement:type_name -> loan.v1.Disbursement
+	12, // 4: loan.v1.Loan.created_at:type_name -> google.protobuf.Timestamp
+	12, // 5: loan.v1.Approval.approved_at:type_name -> google.protobuf.Timestamp
+	12, // 6: loan.v1.Investment.invested_at:type_name -> google.protobuf.Timestamp
+	12, // 7: loan.v1.Disbursement.disbursed_at:type_name -> google.protobuf.Timestamp
+	12, // 8: loan.v1.ApproveLoanRequest.approved_at:type_name -> google.protobuf.Timestamp
+	12, // 9: loan.v1.DisburseLoanRequest.disbursed_at:type_name -> google.protobuf.Timestamp
+	0,  // 10: loan.v1.ListLoansRequest.state:type_name -> loan.v1.LoanState
+	12, // 11: loan.v1.ListLoansRequest.created_from:type_name -> google.protobuf.Timestamp
+	12, // 12: loan.v1.ListLoansRequest.created_to:type_name -> google.protobuf.Timestamp
+	1,  // 13: loan.v1.ListLoansResponse.loans:type_name -> loan.v1.Loan
+	5,  // 14: loan.v1.LoanService.CreateLoan:input_type -> loan.v1.CreateLoanRequest
+	6,  // 15: loan.v1.LoanService.ApproveLoan:input_type -> loan.v1.ApproveLoanRequest
+	7,  // 16: loan.v1.LoanService.AddInvestment:input_type -> loan.v1.AddInvestmentRequest
+	8,  // 17: loan.v1.LoanService.DisburseLoan:input_type -> loan.v1.DisburseLoanRequest
+	9,  // 18: loan.v1.LoanService.GetLoan:input_type -> loan.v1.GetLoanRequest
+	10, // 19: loan.v1.LoanService.ListLoans:input_type -> loan.v1.ListLoansRequest
+	1,  // 20: loan.v1.LoanService.CreateLoan:output_type -> loan.v1.Loan
+	1,  // 21: loan.v1.LoanService.ApproveLoan:output_type -> loan.v1.Loan
+	1,  // 22: loan.v1.LoanService.AddInvestment:output_type -> loan.v1.Loan
+	1,  // 23: loan.v1.LoanService.DisburseLoan:output_type -> loan.v1.Loan
+	1,  // 24: loan.v1.LoanService.GetLoan:output_type -> loan.v1.Loan
+	11, // 25: loan.v1.LoanService.ListLoans:output_type -> loan.v1.ListLoansResponse
+	20, // [20:26] is the sub-list for method output_type
+	14, // [14:20] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_loanpb_loan_proto_init() }
+func file_loanpb_loan_proto_init() {
+	if File_loanpb_loan_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_loanpb_loan_proto_rawDesc), len(file_loanpb_loan_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_loanpb_loan_proto_goTypes,
+		DependencyIndexes: file_loanpb_loan_proto_depIdxs,
+		EnumInfos:         file_loanpb_loan_proto_enumTypes,
+		MessageInfos:      file_loanpb_loan_proto_msgTypes,
+	}.Build()
+	File_loanpb_loan_proto = out.File
+	file_loanpb_loan_proto_goTypes = nil
+	file_loanpb_loan_proto_depIdxs = nil
+}
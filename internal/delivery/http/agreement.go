@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/agreement"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AgreementHandler struct {
+	uc agreement.Usecase
+}
+
+func NewAgreementHandler(uc agreement.Usecase) *AgreementHandler {
+	return &AgreementHandler{uc: uc}
+}
+
+// GetAgreement returns the agreement metadata as JSON, or the rendered
+// document itself when a format is requested.
+func (h *AgreementHandler) GetAgreement(c echo.Context) error {
+	req := new(request.GetAgreementRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	agreement, err := h.uc.FindByInvestor(c.Request().Context(), req.ID, req.InvestorID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if req.Format == "" {
+		return Success(c, http.StatusOK, map[string]interface{}{
+			"agreement": agreement,
+		})
+	}
+
+	format := model.AgreementFormat(req.Format)
+	document, ok := agreement.Document(format)
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "agreement document not found")
+	}
+
+	content, err := h.uc.OpenDocument(c.Request().Context(), agreement, format)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	defer content.Close()
+
+	c.Response().Header().Set("ETag", `"`+document.ContentHash+`"`)
+	return c.Stream(http.StatusOK, document.ContentType, content)
+}
@@ -0,0 +1,91 @@
+package http_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	agreementmock "loan_system/internal/usecase/agreement/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAgreementHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := agreementmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewAgreementHandler(mockUsecase)
+
+	agreement := &model.Agreement{
+		LoanID:     1,
+		InvestorID: 2,
+		Documents: []model.AgreementDocument{
+			{Format: model.AgreementFormatHTML, ContentType: "text/html; charset=utf-8", ContentHash: "abc", BlobKey: "agreements/1/2.html"},
+		},
+	}
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/1/agreements/2"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans/:id/agreements/:investorID")
+		c.SetParamNames("id", "investorID")
+		c.SetParamValues("1", "2")
+		return c, rec
+	}
+
+	t.Run("metadata", func(t *testing.T) {
+		mockUsecase.EXPECT().FindByInvestor(gomock.Any(), int64(1), int64(2)).Return(agreement, nil)
+
+		c, rec := newContext("")
+		assert.NoError(t, handler.GetAgreement(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"content_hash":"abc"`)
+	})
+
+	t.Run("document", func(t *testing.T) {
+		mockUsecase.EXPECT().FindByInvestor(gomock.Any(), int64(1), int64(2)).Return(agreement, nil)
+		mockUsecase.EXPECT().OpenDocument(gomock.Any(), agreement, model.AgreementFormatHTML).
+			Return(io.NopCloser(strings.NewReader("<html></html>")), nil)
+
+		c, rec := newContext("?format=html")
+		assert.NoError(t, handler.GetAgreement(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<html></html>", rec.Body.String())
+		assert.Equal(t, `"abc"`, rec.Header().Get("ETag"))
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	})
+
+	t.Run("missing format", func(t *testing.T) {
+		mockUsecase.EXPECT().FindByInvestor(gomock.Any(), int64(1), int64(2)).Return(agreement, nil)
+
+		c, _ := newContext("?format=pdf")
+		err := handler.GetAgreement(c)
+		assert.ErrorContains(t, err, "agreement document not found")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		c, _ := newContext("?format=docx")
+		err := handler.GetAgreement(c)
+		assert.ErrorContains(t, err, "oneof")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockUsecase.EXPECT().FindByInvestor(gomock.Any(), int64(1), int64(2)).Return(nil, errors.New("agreement not found"))
+
+		c, _ := newContext("")
+		err := handler.GetAgreement(c)
+		assert.ErrorContains(t, err, "agreement not found")
+	})
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/borrower"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BorrowerHandler struct {
+	uc borrower.Usecase
+}
+
+func NewBorrowerHandler(uc borrower.Usecase) *BorrowerHandler {
+	return &BorrowerHandler{uc: uc}
+}
+
+func (h *BorrowerHandler) GetBorrowerLoans(c echo.Context) error {
+	req := new(request.GetBorrowerLoansRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	dashboard, err := h.uc.Dashboard(c.Request().Context(), req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"dashboard": dashboard,
+	})
+}
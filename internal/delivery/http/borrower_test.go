@@ -0,0 +1,66 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	borrowermock "loan_system/internal/usecase/borrower/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetBorrowerLoansHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := borrowermock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewBorrowerHandler(mockUsecase)
+
+	newContext := func(id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/borrowers/"+id+"/loans", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/borrowers/:id/loans")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		dashboard := &model.BorrowerDashboard{
+			BorrowerID: 5,
+			Active:     []model.BorrowerLoan{{Loan: &model.Loan{ID: 1}, Outstanding: 100}},
+			Summary:    model.BorrowerSummary{TotalBorrowed: 1000, TotalOutstanding: 100, LoanCycles: 1},
+		}
+		mockUsecase.EXPECT().Dashboard(gomock.Any(), int64(5)).Return(dashboard, nil)
+
+		c, rec := newContext("5")
+		assert.NoError(t, handler.GetBorrowerLoans(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"loan_cycles":1`)
+		assert.Contains(t, rec.Body.String(), `"total_outstanding":100`)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, _ := newContext("abc")
+		err := handler.GetBorrowerLoans(c)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		mockUsecase.EXPECT().Dashboard(gomock.Any(), int64(5)).Return(nil, errors.New("boom"))
+
+		c, _ := newContext("5")
+		err := handler.GetBorrowerLoans(c)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+	})
+}
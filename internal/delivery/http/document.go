@@ -0,0 +1,80 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/document"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DocumentHandler struct {
+	uc            document.Usecase
+	maxUploadSize int64
+}
+
+func NewDocumentHandler(uc document.Usecase, maxUploadSize int64) *DocumentHandler {
+	return &DocumentHandler{uc: uc, maxUploadSize: maxUploadSize}
+}
+
+func (h *DocumentHandler) UploadDocument(c echo.Context) error {
+	limitUpload(c, h.maxUploadSize)
+
+	req := new(request.UploadDocumentRequest)
+	if err := c.Bind(req); err != nil {
+		return formError(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return formError(err)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	defer file.Close()
+
+	doc := &model.Document{
+		LoanID:   req.ID,
+		Kind:     model.DocumentKind(req.Kind),
+		FileName: fileHeader.Filename,
+		Size:     fileHeader.Size,
+	}
+
+	if err := h.uc.Upload(c.Request().Context(), doc, file); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"document": doc,
+	})
+}
+
+// formOverhead allows for the part headers, boundaries and other fields sent
+// along with an uploaded file.
+const formOverhead = 64 << 10
+
+// limitUpload caps the request body at a file of maxSize and its form, so an
+// oversized upload is refused while the form is parsed rather than after it
+// has been spooled to disk.
+func limitUpload(c echo.Context, maxSize int64) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSize+formOverhead)
+}
+
+// formError answers a form that could not be read, with 413 when it was
+// larger than limitUpload allows.
+func formError(err error) error {
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("an upload may be at most %d bytes", tooLarge.Limit-formOverhead))
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, err)
+}
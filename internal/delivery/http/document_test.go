@@ -0,0 +1,92 @@
+package http_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	documentmock "loan_system/internal/usecase/document/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUploadDocumentHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := documentmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewDocumentHandler(mockUsecase, 1<<10)
+
+	pdf := []byte("%PDF-1.4")
+	newContext := func(kind string, file []byte) (echo.Context, *httptest.ResponseRecorder) {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		_ = writer.WriteField("kind", kind)
+		if file != nil {
+			part, _ := writer.CreateFormFile("file", "proof.pdf")
+			_, _ = part.Write(file)
+		}
+		_ = writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/documents", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans/:id/documents")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		return c, rec
+	}
+
+	t.Run("successful upload", func(t *testing.T) {
+		mockUsecase.EXPECT().Upload(gomock.Any(), &model.Document{
+			LoanID:   1,
+			Kind:     model.DocumentKindApprovalProof,
+			FileName: "proof.pdf",
+			Size:     8,
+		}, gomock.Any()).Return(nil)
+
+		c, rec := newContext("approval_proof", pdf)
+		assert.NoError(t, handler.UploadDocument(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid kind", func(t *testing.T) {
+		c, _ := newContext("selfie", pdf)
+		err := handler.UploadDocument(c)
+		assert.ErrorContains(t, err, "oneof")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		c, _ := newContext("approval_proof", nil)
+		err := handler.UploadDocument(c)
+		assert.ErrorContains(t, err, "no such file")
+	})
+
+	t.Run("file over the limit", func(t *testing.T) {
+		c, _ := newContext("approval_proof", bytes.Repeat([]byte("x"), 128<<10))
+		err := handler.UploadDocument(c)
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, httpErr.Code)
+		assert.ErrorContains(t, err, "at most 1024 bytes")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockUsecase.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("unsupported document type"))
+
+		c, _ := newContext("signed_agreement", pdf)
+		err := handler.UploadDocument(c)
+		assert.ErrorContains(t, err, "unsupported document type")
+	})
+}
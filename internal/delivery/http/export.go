@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/export"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	uc  export.Usecase
+	log *slog.Logger
+}
+
+func NewExportHandler(uc export.Usecase, log *slog.Logger) *ExportHandler {
+	return &ExportHandler{uc: uc, log: log}
+}
+
+// ExportLoans streams every loan matching the filters as a CSV, NDJSON or
+// Parquet download. Errors before the first bytes are written are returned
+// as usual. After that the status has been sent, so the connection is
+// aborted instead, for clients to see a failed download rather than a
+// truncated file that looks whole.
+func (h *ExportHandler) ExportLoans(c echo.Context) error {
+	req := new(request.ExportLoansRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	format := model.ExportFormat(req.Format)
+	if format == "" {
+		format = model.ExportCSV
+	}
+	query := model.LoanQuery{
+		State:        model.LoanState(req.State),
+		BorrowerID:   req.BorrowerID,
+		InvestorID:   req.InvestorID,
+		MinPrincipal: req.MinPrincipal,
+		MaxPrincipal: req.MaxPrincipal,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+		SortBy:       model.LoanSortField(strings.TrimPrefix(req.Sort, "-")),
+		Descending:   strings.HasPrefix(req.Sort, "-"),
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, format.ContentType())
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="loans.%s"`, format))
+	ctx := c.Request().Context()
+	if err := h.uc.Export(ctx, query, format, c.Response()); err != nil {
+		if !c.Response().Committed {
+			header.Del(echo.HeaderContentDisposition)
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		// the request log is skipped by the abort, so the error is logged here
+		h.log.ErrorContext(ctx, "export loans failed", "error", err)
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	exportmock "loan_system/internal/usecase/export/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportLoansHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := exportmock.NewMockUsecase(ctrl)
+	logs := new(bytes.Buffer)
+	handler := httpHandler.NewExportHandler(mockUsecase, slog.New(slog.NewTextHandler(logs, nil)))
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/export"+query, nil)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		query := model.LoanQuery{State: model.StateDisbursed, MinPrincipal: 1000, SortBy: model.LoanSortPrincipal, Descending: true}
+		mockUsecase.EXPECT().Export(gomock.Any(), query, model.ExportNDJSON, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.LoanQuery, _ model.ExportFormat, w io.Writer) error {
+				_, err := io.WriteString(w, `{"id":1}`+"\n")
+				return err
+			})
+
+		c, rec := newContext("?format=ndjson&state=DISBURSED&min_principal=1000&sort=-principal")
+		assert.NoError(t, handler.ExportLoans(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="loans.ndjson"`, rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, `{"id":1}`+"\n", rec.Body.String())
+	})
+
+	t.Run("defaults to CSV", func(t *testing.T) {
+		mockUsecase.EXPECT().Export(gomock.Any(), model.LoanQuery{}, model.ExportCSV, gomock.Any()).Return(nil)
+
+		c, rec := newContext("")
+		assert.NoError(t, handler.ExportLoans(c))
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		c, _ := newContext("?format=xlsx")
+		err := handler.ExportLoans(c)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("usecase error before writing", func(t *testing.T) {
+		mockUsecase.EXPECT().Export(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+
+		c, rec := newContext("?format=parquet")
+		err := handler.ExportLoans(c)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("usecase error while writing aborts", func(t *testing.T) {
+		mockUsecase.EXPECT().Export(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ model.LoanQuery, _ model.ExportFormat, w io.Writer) error {
+				io.WriteString(w, "loan_id\n")
+				return errors.New("boom")
+			})
+
+		c, rec := newContext("")
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { handler.ExportLoans(c) })
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, logs.String(), `msg="export loans failed" error=boom`)
+	})
+}
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"loan_system/internal/model"
 	"loan_system/internal/model/request"
+	"loan_system/internal/pkg/auth"
 	"loan_system/internal/usecase/loan"
 
 	"github.com/labstack/echo/v4"
@@ -28,16 +31,16 @@ func (h *LoanHandler) CreateLoan(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
-	loan := &model.Loan{
-		BorrowerID:    req.BorrowerID,
-		Principal:     req.Principal,
-		Rate:          req.Rate,
-		ROI:           req.ROI,
-		AgreementLink: req.AgreementLink,
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
 	}
-
-	err := h.uc.CreateLoan(c.Request().Context(), loan)
+	loan, err := proposal(principal, req)
 	if err != nil {
+		return err
+	}
+
+	if err := h.uc.CreateLoan(c.Request().Context(), loan); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
@@ -55,10 +58,15 @@ func (h *LoanHandler) ApproveLoan(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
 	approveReq := model.Approval{
-		ValidatorID: req.ValidatorID,
-		ProofURL:    req.ProofURL,
-		ApprovedAt:  req.ApprovedAt,
+		ValidatorID:     principal.ID,
+		ProofDocumentID: req.ProofDocumentID,
+		ApprovedAt:      req.ApprovedAt,
 	}
 
 	loan, err := h.uc.ApproveLoan(c.Request().Context(), req.ID, approveReq)
@@ -80,10 +88,23 @@ func (h *LoanHandler) AddInvestment(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
+	investorID, err := onBehalfOf(principal, auth.RoleInvestor, req.InvestorID, "investor_id")
+	if err != nil {
+		return err
+	}
+
 	investment := model.Investment{
-		InvestorID: req.InvestorID,
+		InvestorID: investorID,
 		Amount:     req.Amount,
 	}
+	if principal.Role == auth.RolePartner {
+		investment.PartnerID = principal.ID
+	}
 
 	loan, err := h.uc.AddInvestment(c.Request().Context(), req.ID, investment)
 	if err != nil {
@@ -104,10 +125,15 @@ func (h *LoanHandler) DisburseLoan(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
 	disbursement := model.Disbursement{
-		OfficerID:    req.OfficerID,
-		AgreementURL: req.AgreementURL,
-		DisbursedAt:  req.DisbursedAt,
+		OfficerID:           principal.ID,
+		AgreementDocumentID: req.AgreementDocumentID,
+		DisbursedAt:         req.DisbursedAt,
 	}
 
 	loan, err := h.uc.DisburseLoan(c.Request().Context(), req.ID, disbursement)
@@ -120,17 +146,114 @@ func (h *LoanHandler) DisburseLoan(c echo.Context) error {
 	})
 }
 
-func (h *LoanHandler) GetLoans(c echo.Context) error {
-	loans, err := h.uc.FindAll(c.Request().Context())
+// ApproveLoans responds with the outcome for each loan, in request order.
+func (h *LoanHandler) ApproveLoans(c echo.Context) error {
+	req := new(request.ApproveLoansRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
+	approval := model.Approval{
+		ValidatorID: principal.ID,
+		ApprovedAt:  req.ApprovedAt,
+	}
+	items := make([]model.BatchItem, len(req.Loans))
+	for i, loan := range req.Loans {
+		items[i] = model.BatchItem{LoanID: loan.LoanID, DocumentID: loan.ProofDocumentID}
+	}
+
+	results, err := h.uc.ApproveLoans(c.Request().Context(), items, approval, req.Atomic)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"results": results,
+	})
+}
+
+// DisburseLoans responds with the outcome for each loan, in request order.
+func (h *LoanHandler) DisburseLoans(c echo.Context) error {
+	req := new(request.DisburseLoansRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
+	disbursement := model.Disbursement{
+		OfficerID:   principal.ID,
+		DisbursedAt: req.DisbursedAt,
+	}
+	items := make([]model.BatchItem, len(req.Loans))
+	for i, loan := range req.Loans {
+		items[i] = model.BatchItem{LoanID: loan.LoanID, DocumentID: loan.AgreementDocumentID}
+	}
+
+	results, err := h.uc.DisburseLoans(c.Request().Context(), items, disbursement, req.Atomic)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
 	return Success(c, http.StatusOK, map[string]interface{}{
-		"loans": loans,
+		"results": results,
 	})
 }
 
+func (h *LoanHandler) GetLoans(c echo.Context) error {
+	req := new(request.GetLoansRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	query := model.LoanQuery{
+		State:        model.LoanState(req.State),
+		BorrowerID:   req.BorrowerID,
+		InvestorID:   req.InvestorID,
+		MinPrincipal: req.MinPrincipal,
+		MaxPrincipal: req.MaxPrincipal,
+		CreatedFrom:  req.CreatedFrom,
+		CreatedTo:    req.CreatedTo,
+		SortBy:       model.LoanSortField(strings.TrimPrefix(req.Sort, "-")),
+		Descending:   strings.HasPrefix(req.Sort, "-"),
+		Limit:        req.Limit,
+	}
+
+	if req.Cursor != "" {
+		after, err := model.DecodeLoanCursor(req.Cursor)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		query.After = after
+	}
+
+	page, err := h.uc.Find(c.Request().Context(), query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return SuccessPage(c, http.StatusOK, map[string]interface{}{
+		"loans": page.Loans,
+	}, page.NextCursor)
+}
+
 func (h *LoanHandler) GetLoan(c echo.Context) error {
 	req := new(request.GetLoanRequest)
 	if err := c.Bind(req); err != nil {
@@ -149,3 +272,47 @@ func (h *LoanHandler) GetLoan(c echo.Context) error {
 		"loan": loan,
 	})
 }
+
+// authenticated returns the caller attached by auth.Middleware. The acting
+// IDs of approvals, investments and disbursements are taken from it rather
+// than from the request body.
+func authenticated(c echo.Context) (auth.Principal, error) {
+	principal, ok := auth.FromContext(c.Request().Context())
+	if !ok {
+		return auth.Principal{}, echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+	}
+	return principal, nil
+}
+
+// proposal builds the loan a create request proposes on behalf of principal.
+func proposal(principal auth.Principal, req *request.CreateLoanRequest) (*model.Loan, error) {
+	borrowerID, err := onBehalfOf(principal, auth.RoleBorrower, req.BorrowerID, "borrower_id")
+	if err != nil {
+		return nil, err
+	}
+
+	loan := &model.Loan{
+		BorrowerID:    borrowerID,
+		Principal:     req.Principal,
+		Rate:          req.Rate,
+		ROI:           req.ROI,
+		AgreementLink: req.AgreementLink,
+		TenorMonths:   req.TenorMonths,
+	}
+	if principal.Role == auth.RolePartner {
+		loan.PartnerID = principal.ID
+	}
+	return loan, nil
+}
+
+// onBehalfOf answers the errors of auth.OnBehalfOf with 403 or 400.
+func onBehalfOf(principal auth.Principal, role auth.Role, id int64, field string) (int64, error) {
+	id, err := auth.OnBehalfOf(principal, role, id, field)
+	switch {
+	case errors.Is(err, auth.ErrOnlySelf):
+		return 0, echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case err != nil:
+		return 0, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return id, nil
+}
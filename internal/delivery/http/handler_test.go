@@ -2,14 +2,17 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	httpHandler "loan_system/internal/delivery/http"
 	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
 	loanmock "loan_system/internal/usecase/loan/mock"
 
 	"github.com/go-playground/validator"
@@ -26,6 +29,11 @@ func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
 }
 
+// withPrincipal authenticates req as auth.Middleware would.
+func withPrincipal(req *http.Request, role auth.Role, id int64) *http.Request {
+	return req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: id, Role: role}))
+}
+
 func TestCreateLoanHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -48,6 +56,7 @@ func TestCreateLoanHandler(t *testing.T) {
 
 		reqBody := `{"principal":10000,"borrower_id":1234,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
 		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req = withPrincipal(req, auth.RoleBorrower, 1234)
 
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -56,9 +65,65 @@ func TestCreateLoanHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
 
+	t.Run("borrower proposing for someone else", func(t *testing.T) {
+		reqBody := `{"principal":10000,"borrower_id":99,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req = withPrincipal(req, auth.RoleBorrower, 1234)
+
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		err := handler.CreateLoan(e.NewContext(req, rec))
+
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("admin without borrower", func(t *testing.T) {
+		reqBody := `{"principal":10000,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req = withPrincipal(req, auth.RoleAdmin, 1)
+
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		err := handler.CreateLoan(e.NewContext(req, rec))
+
+		assert.ErrorContains(t, err, "borrower_id is required")
+	})
+
+	t.Run("partner on behalf of a borrower", func(t *testing.T) {
+		mockUsecase.EXPECT().CreateLoan(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, loan *model.Loan) error {
+			assert.Equal(t, int64(55), loan.BorrowerID)
+			assert.Equal(t, int64(9), loan.PartnerID)
+			return nil
+		})
+
+		reqBody := `{"principal":10000,"borrower_id":55,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req = withPrincipal(req, auth.RolePartner, 9)
+
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		assert.NoError(t, handler.CreateLoan(e.NewContext(req, rec)))
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		reqBody := `{"principal":10000,"borrower_id":1234,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
+		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		err := handler.CreateLoan(e.NewContext(req, rec))
+
+		assert.Equal(t, http.StatusUnauthorized, err.(*echo.HTTPError).Code)
+	})
+
 	t.Run("invalid param", func(t *testing.T) {
 		reqBody := `{"borrower_id":1234,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
 		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req = withPrincipal(req, auth.RoleBorrower, 1234)
 
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -73,6 +138,7 @@ func TestCreateLoanHandler(t *testing.T) {
 
 		reqBody := `{"principal":10000,"borrower_id":1234,"rate":5.0,"roi":6.0,"agreement_link":"https://example.com/agreement.pdf"}`
 		req := httptest.NewRequest(http.MethodPost, "/loans", strings.NewReader(reqBody))
+		req = withPrincipal(req, auth.RoleBorrower, 1234)
 
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -94,14 +160,17 @@ func TestApproveLoanHandler(t *testing.T) {
 	handler := httpHandler.NewLoanHandler(mockUsecase)
 
 	t.Run("success approval", func(t *testing.T) {
-		mockUsecase.EXPECT().ApproveLoan(gomock.Any(), int64(1), gomock.Any()).Return(&model.Loan{ID: 1}, nil)
+		approval := model.Approval{ValidatorID: 42, ProofDocumentID: 5678}
+		mockUsecase.EXPECT().ApproveLoan(gomock.Any(), int64(1), approval).Return(&model.Loan{ID: 1}, nil)
 
+		// validator_id in the body is ignored in favour of the token
 		body := bytes.NewBufferString(`{
 		  "validator_id": 1234,
-		  "proof_url": "https://example.com/proof.pdf",
+		  "proof_document_id": 5678,
 		  "approver": "investor123"
 		}`)
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/approve", body)
+		req = withPrincipal(req, auth.RoleFieldValidator, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -115,6 +184,7 @@ func TestApproveLoanHandler(t *testing.T) {
 
 	t.Run("invalid loan ID", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPost, "/loans/invalid/approve", nil)
+		req = withPrincipal(req, auth.RoleFieldValidator, 42)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.SetParamNames("id")
@@ -126,10 +196,10 @@ func TestApproveLoanHandler(t *testing.T) {
 
 	t.Run("invalid param", func(t *testing.T) {
 		body := bytes.NewBufferString(`{
-		  "proof_url": "https://example.com/proof.pdf",
 		  "approver": "investor123"
 		}`)
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/approve", body)
+		req = withPrincipal(req, auth.RoleFieldValidator, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -146,10 +216,11 @@ func TestApproveLoanHandler(t *testing.T) {
 
 		body := bytes.NewBufferString(`{
 		  "validator_id": 1234,
-		  "proof_url": "https://example.com/proof.pdf",
+		  "proof_document_id": 5678,
 		  "approver": "investor123"
 		}`)
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/approve", body)
+		req = withPrincipal(req, auth.RoleFieldValidator, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -172,13 +243,15 @@ func TestAddInvestmentHandler(t *testing.T) {
 	handler := httpHandler.NewLoanHandler(mockUsecase)
 
 	t.Run("success add investment", func(t *testing.T) {
-		mockUsecase.EXPECT().AddInvestment(gomock.Any(), int64(1), gomock.Any()).Return(&model.Loan{ID: 1}, nil)
+		investment := model.Investment{InvestorID: 42, Amount: 5000}
+		mockUsecase.EXPECT().AddInvestment(gomock.Any(), int64(1), investment).Return(&model.Loan{ID: 1}, nil)
 
 		body := bytes.NewBufferString(`{
 		  "amount": 5000,
-		  "investor_id": 1234
+		  "investor_id": 42
 		}`)
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/invest", body)
+		req = withPrincipal(req, auth.RoleInvestor, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -190,8 +263,43 @@ func TestAddInvestmentHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	})
 
+	t.Run("partner on behalf of an investor", func(t *testing.T) {
+		investment := model.Investment{InvestorID: 1234, Amount: 5000, PartnerID: 9}
+		mockUsecase.EXPECT().AddInvestment(gomock.Any(), int64(1), investment).Return(&model.Loan{ID: 1}, nil)
+
+		body := bytes.NewBufferString(`{
+		  "amount": 5000,
+		  "investor_id": 1234
+		}`)
+		req := httptest.NewRequest(http.MethodPut, "/loans/1/invest", body)
+		req = withPrincipal(req, auth.RolePartner, 9)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans/:id/invest")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		assert.NoError(t, handler.AddInvestment(c))
+	})
+
+	t.Run("partner without investor", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"amount": 5000}`)
+		req := httptest.NewRequest(http.MethodPut, "/loans/1/invest", body)
+		req = withPrincipal(req, auth.RolePartner, 9)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans/:id/invest")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		assert.ErrorContains(t, handler.AddInvestment(c), "investor_id is required")
+	})
+
 	t.Run("invalid loan ID", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPut, "/loans/invalid/invest", nil)
+		req = withPrincipal(req, auth.RoleInvestor, 42)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.SetParamNames("id")
@@ -203,9 +311,10 @@ func TestAddInvestmentHandler(t *testing.T) {
 
 	t.Run("invalid param", func(t *testing.T) {
 		body := bytes.NewBufferString(`{
-		  "investor_id": 1234
+		  "investor_id": 42
 		}`)
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/invest", body)
+		req = withPrincipal(req, auth.RoleInvestor, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -222,9 +331,10 @@ func TestAddInvestmentHandler(t *testing.T) {
 
 		body := bytes.NewBufferString(`{
 		  "amount": 5000,
-		  "investor_id": 1234
+		  "investor_id": 42
 		}`)
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/invest", body)
+		req = withPrincipal(req, auth.RoleInvestor, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -247,15 +357,17 @@ func TestDisburseLoanHandler(t *testing.T) {
 	handler := httpHandler.NewLoanHandler(mockUsecase)
 
 	t.Run("success disburse loan", func(t *testing.T) {
-		mockUsecase.EXPECT().DisburseLoan(gomock.Any(), int64(1), gomock.Any()).Return(&model.Loan{ID: 1}, nil)
+		disbursement := model.Disbursement{OfficerID: 42, AgreementDocumentID: 5678, DisbursedAt: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)}
+		mockUsecase.EXPECT().DisburseLoan(gomock.Any(), int64(1), disbursement).Return(&model.Loan{ID: 1}, nil)
 		body := bytes.NewBufferString(`{
 		  "id": 1,
 		  "officer_id": 1234,
-		  "agreement_url": "https://example.com/agreement.pdf",
+		  "agreement_document_id": 5678,
 		  "disbursed_at": "2023-01-01T12:00:00Z"
 		}`)
 
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/disburse", body)
+		req = withPrincipal(req, auth.RoleFieldOfficer, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -269,6 +381,7 @@ func TestDisburseLoanHandler(t *testing.T) {
 
 	t.Run("invalid loan ID", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPut, "/loans/invalid/disburse", nil)
+		req = withPrincipal(req, auth.RoleFieldOfficer, 42)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 		c.SetParamNames("id")
@@ -280,11 +393,11 @@ func TestDisburseLoanHandler(t *testing.T) {
 
 	t.Run("invalid param", func(t *testing.T) {
 		body := bytes.NewBufferString(`{
-		  "agreement_url": "https://example.com/agreement.pdf",
 		  "disbursed_at": "2023-01-01T12:00:00Z"
 		}`)
 
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/disburse", body)
+		req = withPrincipal(req, auth.RoleFieldOfficer, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -302,11 +415,12 @@ func TestDisburseLoanHandler(t *testing.T) {
 		body := bytes.NewBufferString(`{
 		  "id": 1,
 		  "officer_id": 1234,
-		  "agreement_url": "https://example.com/agreement.pdf",
+		  "agreement_document_id": 5678,
 		  "disbursed_at": "2023-01-01T12:00:00Z"
 		}`)
 
 		req := httptest.NewRequest(http.MethodPut, "/loans/1/disburse", body)
+		req = withPrincipal(req, auth.RoleFieldOfficer, 42)
 		req.Header.Set("Content-Type", "application/json")
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
@@ -319,6 +433,93 @@ func TestDisburseLoanHandler(t *testing.T) {
 	})
 }
 
+func TestApproveLoansHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewLoanHandler(mockUsecase)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/loans/approve", bytes.NewBufferString(body))
+		req = withPrincipal(req, auth.RoleFieldValidator, 42)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		items := []model.BatchItem{{LoanID: 1, DocumentID: 5678}, {LoanID: 2, DocumentID: 5679}}
+		mockUsecase.EXPECT().ApproveLoans(gomock.Any(), items, model.Approval{ValidatorID: 42}, true).Return([]model.BatchResult{
+			{LoanID: 1, Status: model.BatchSkipped},
+			{LoanID: 2, Status: model.BatchFailed, Error: "loan not found"},
+		}, nil)
+
+		c, rec := newContext(`{"loans":[{"loan_id":1,"proof_document_id":5678},{"loan_id":2,"proof_document_id":5679}],"atomic":true}`)
+		assert.NoError(t, handler.ApproveLoans(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":200,"data":{"results":[{"loan_id":1,"status":"skipped"},{"loan_id":2,"status":"failed","error":"loan not found"}]}}`, rec.Body.String())
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		tests := []struct {
+			name string
+			body string
+		}{
+			{"no loans", `{"loans":[]}`},
+			{"repeated loan", `{"loans":[{"loan_id":1,"proof_document_id":5678},{"loan_id":1,"proof_document_id":5679}]}`},
+			{"zero loan ID", `{"loans":[{"loan_id":0,"proof_document_id":5678}]}`},
+			{"no proof", `{"loans":[{"loan_id":1}]}`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c, _ := newContext(tt.body)
+				var he *echo.HTTPError
+				if assert.ErrorAs(t, handler.ApproveLoans(c), &he) {
+					assert.Equal(t, http.StatusBadRequest, he.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockUsecase.EXPECT().ApproveLoans(gomock.Any(), gomock.Any(), gomock.Any(), false).Return(nil, errors.New("document not found"))
+
+		c, _ := newContext(`{"loans":[{"loan_id":1,"proof_document_id":5678}]}`)
+		assert.ErrorContains(t, handler.ApproveLoans(c), "document not found")
+	})
+}
+
+func TestDisburseLoansHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := loanmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewLoanHandler(mockUsecase)
+
+	newContext := func(body string) echo.Context {
+		req := httptest.NewRequest(http.MethodPut, "/loans/disburse", bytes.NewBufferString(body))
+		req = withPrincipal(req, auth.RoleFieldOfficer, 101)
+		req.Header.Set("Content-Type", "application/json")
+		return e.NewContext(req, httptest.NewRecorder())
+	}
+
+	t.Run("success", func(t *testing.T) {
+		items := []model.BatchItem{{LoanID: 3, DocumentID: 11}, {LoanID: 4, DocumentID: 12}}
+		mockUsecase.EXPECT().DisburseLoans(gomock.Any(), items, model.Disbursement{OfficerID: 101}, false).Return([]model.BatchResult{}, nil)
+
+		assert.NoError(t, handler.DisburseLoans(newContext(`{"loans":[{"loan_id":3,"agreement_document_id":11},{"loan_id":4,"agreement_document_id":12}]}`)))
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		assert.ErrorContains(t, handler.DisburseLoans(newContext(`{"loans":[{"loan_id":3}]}`)), "required")
+	})
+}
+
 func TestGetLoanHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -365,7 +566,7 @@ func TestGetLoansHandler(t *testing.T) {
 	handler := httpHandler.NewLoanHandler(mockUsecase)
 
 	t.Run("success get loans", func(t *testing.T) {
-		mockUsecase.EXPECT().FindAll(gomock.Any()).Return([]*model.Loan{{ID: 1}, {ID: 2}}, nil)
+		mockUsecase.EXPECT().Find(gomock.Any(), model.LoanQuery{}).Return(model.LoanPage{Loans: []*model.Loan{{ID: 1}, {ID: 2}}}, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/loans", nil)
 		rec := httptest.NewRecorder()
@@ -374,10 +575,59 @@ func TestGetLoansHandler(t *testing.T) {
 
 		assert.NoError(t, handler.GetLoans(c))
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "next_cursor")
+	})
+
+	t.Run("filters, sort and cursor", func(t *testing.T) {
+		after := model.LoanCursor{ID: 7, Principal: 1500}
+		createdFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockUsecase.EXPECT().Find(gomock.Any(), model.LoanQuery{
+			State:        model.StateApproved,
+			BorrowerID:   10,
+			InvestorID:   20,
+			MinPrincipal: 100,
+			MaxPrincipal: 5000,
+			CreatedFrom:  createdFrom,
+			SortBy:       model.LoanSortPrincipal,
+			Descending:   true,
+			After:        &after,
+			Limit:        2,
+		}).Return(model.LoanPage{Loans: []*model.Loan{{ID: 6}, {ID: 5}}, NextCursor: "next"}, nil)
+
+		query := "state=APPROVED&borrower_id=10&investor_id=20&min_principal=100&max_principal=5000" +
+			"&created_from=2025-01-01T00:00:00Z&sort=-principal&limit=2&cursor=" + after.Encode()
+		req := httptest.NewRequest(http.MethodGet, "/loans?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans")
+
+		assert.NoError(t, handler.GetLoans(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"next_cursor":"next"`)
+	})
+
+	t.Run("invalid param", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans?sort=rate", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans")
+
+		err := handler.GetLoans(c)
+		assert.ErrorContains(t, err, "oneof")
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/loans?cursor=garbage", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans")
+
+		err := handler.GetLoans(c)
+		assert.ErrorContains(t, err, "invalid cursor")
 	})
 
 	t.Run("failure", func(t *testing.T) {
-		mockUsecase.EXPECT().FindAll(gomock.Any()).Return(nil, errors.New("usecase error"))
+		mockUsecase.EXPECT().Find(gomock.Any(), gomock.Any()).Return(model.LoanPage{}, errors.New("usecase error"))
 
 		req := httptest.NewRequest(http.MethodGet, "/loans", nil)
 		rec := httptest.NewRecorder()
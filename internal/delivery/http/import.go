@@ -0,0 +1,246 @@
+package http
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/usecase/importjob"
+
+	"github.com/labstack/echo/v4"
+)
+
+// importColumns are the CreateLoanRequest fields a CSV import may set.
+// requiredImportColumns must be in the header; the others may be left out.
+var (
+	importColumns         = []string{"borrower_id", "principal", "rate", "roi", "agreement_link", "tenor_months"}
+	requiredImportColumns = []string{"principal", "rate", "roi", "agreement_link"}
+)
+
+type ImportHandler struct {
+	uc importjob.Usecase
+	// maxRows caps how many loans one file may propose. It is read per
+	// upload so that the cap can change while serving.
+	maxRows       func() int
+	maxUploadSize int64
+}
+
+func NewImportHandler(uc importjob.Usecase, maxRows func() int, maxUploadSize int64) *ImportHandler {
+	return &ImportHandler{uc: uc, maxRows: maxRows, maxUploadSize: maxUploadSize}
+}
+
+// ImportLoans reads and validates every row of the uploaded CSV file, then
+// creates the loans in the background. It responds with the job to poll.
+func (h *ImportHandler) ImportLoans(c echo.Context) error {
+	limitUpload(c, h.maxUploadSize)
+
+	req := new(request.ImportLoansRequest)
+	if err := c.Bind(req); err != nil {
+		return formError(err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return formError(err)
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	defer file.Close()
+
+	rows, err := h.readRows(c, principal, file)
+	if err != nil {
+		return err
+	}
+
+	job := &model.ImportJob{
+		Mode:      model.ImportMode(req.Mode),
+		Rows:      rows,
+		OwnerRole: string(principal.Role),
+		OwnerID:   principal.ID,
+	}
+	if job.Mode == "" {
+		job.Mode = model.ImportAllOrNothing
+	}
+	if err := h.uc.Start(c.Request().Context(), job); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusAccepted, map[string]interface{}{
+		"import": job,
+	})
+}
+
+// GetImport reports a job's progress and, once it has finished, the outcome
+// of every row. Only the user who started the import and admins may read it.
+func (h *ImportHandler) GetImport(c echo.Context) error {
+	req := new(request.GetImportRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.uc.Job(c.Request().Context(), req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	if principal.Role != auth.RoleAdmin && (job.OwnerRole != string(principal.Role) || job.OwnerID != principal.ID) {
+		return echo.NewHTTPError(http.StatusForbidden, "an import may only be read by whoever started it")
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"import": job,
+	})
+}
+
+// readRows turns each CSV record into a loan proposal, checked with the same
+// rules as a single create request. A record that fails is kept as a failed
+// row; only a file that cannot be read as a whole is rejected.
+func (h *ImportHandler) readRows(c echo.Context, principal auth.Principal, file io.Reader) ([]model.ImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "the file is empty")
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	// spreadsheets often start the file with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	if err := checkHeader(header); err != nil {
+		return nil, err
+	}
+
+	maxRows := h.maxRows()
+	rows := make([]model.ImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		if len(rows) == maxRows {
+			return nil, echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("an import may hold at most %d rows", maxRows))
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := model.ImportRow{Line: line, Status: model.ImportRowPending}
+		row.Loan, err = readLoan(c, principal, header, record)
+		if err != nil {
+			row.Status = model.ImportRowFailed
+			row.Error = errorMessage(err)
+			row.Loan = nil
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "the file has no rows")
+	}
+	return rows, nil
+}
+
+func checkHeader(header []string) error {
+	for i, column := range header {
+		if !slices.Contains(importColumns, column) {
+			return echo.NewHTTPError(http.StatusBadRequest, "unknown column "+strconv.Quote(column)+", expected one of "+strings.Join(importColumns, ", "))
+		}
+		if slices.Contains(header[:i], column) {
+			return echo.NewHTTPError(http.StatusBadRequest, "column "+strconv.Quote(column)+" appears twice")
+		}
+	}
+	for _, column := range requiredImportColumns {
+		if !slices.Contains(header, column) {
+			return echo.NewHTTPError(http.StatusBadRequest, "missing column "+strconv.Quote(column))
+		}
+	}
+	return nil
+}
+
+func readLoan(c echo.Context, principal auth.Principal, header, record []string) (*model.Loan, error) {
+	if len(record) != len(header) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(header), len(record))
+	}
+
+	req := new(request.CreateLoanRequest)
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch column {
+		case "borrower_id":
+			req.BorrowerID, err = strconv.ParseInt(value, 10, 64)
+		case "principal":
+			req.Principal, err = parseFinite(value)
+		case "rate":
+			req.Rate, err = parseFinite(value)
+		case "roi":
+			req.ROI, err = parseFinite(value)
+		case "agreement_link":
+			req.AgreementLink = value
+		case "tenor_months":
+			req.TenorMonths, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a number", column, value)
+		}
+	}
+
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+	return proposal(principal, req)
+}
+
+// parseFinite parses a decimal number, refusing the NaN and infinities
+// strconv.ParseFloat accepts, which no loan amount can hold and JSON cannot
+// encode.
+func parseFinite(value string) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		err = strconv.ErrSyntax
+	}
+	return f, err
+}
+
+// errorMessage gives the text of err without the status an HTTP error carries.
+func errorMessage(err error) string {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return fmt.Sprint(he.Message)
+	}
+	return err.Error()
+}
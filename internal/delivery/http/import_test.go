@@ -0,0 +1,207 @@
+package http_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
+	importmock "loan_system/internal/usecase/importjob/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestImportLoansHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := importmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewImportHandler(mockUsecase, func() int { return 3 }, 1<<20)
+
+	newContext := func(mode, csv string, role auth.Role, id int64) (echo.Context, *httptest.ResponseRecorder) {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		if mode != "" {
+			_ = writer.WriteField("mode", mode)
+		}
+		part, _ := writer.CreateFormFile("file", "loans.csv")
+		_, _ = part.Write([]byte(csv))
+		_ = writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/loans/import", body)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		req = withPrincipal(req, role, id)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("rows are read and validated", func(t *testing.T) {
+		csv := "principal,rate,roi,agreement_link,tenor_months\n" +
+			"1000,5,8,http://example.com/a,6\n" +
+			"abc,5,8,http://example.com/b,\n" +
+			"2000,0,8,http://example.com/c,\n"
+		mockUsecase.EXPECT().Start(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, job *model.ImportJob) error {
+			assert.Equal(t, model.ImportAllOrNothing, job.Mode)
+			assert.Equal(t, "borrower", job.OwnerRole)
+			assert.Equal(t, int64(1234), job.OwnerID)
+			if assert.Len(t, job.Rows, 3) {
+				assert.Equal(t, model.ImportRow{Line: 2, Status: model.ImportRowPending, Loan: &model.Loan{
+					BorrowerID: 1234, Principal: 1000, Rate: 5, ROI: 8, AgreementLink: "http://example.com/a", TenorMonths: 6,
+				}}, job.Rows[0])
+				assert.Equal(t, model.ImportRow{Line: 3, Status: model.ImportRowFailed, Error: `principal: "abc" is not a number`}, job.Rows[1])
+				assert.Equal(t, 4, job.Rows[2].Line)
+				assert.Contains(t, job.Rows[2].Error, "'Rate' failed on the 'required' tag")
+			}
+			job.ID = 1
+			return nil
+		})
+
+		c, rec := newContext("", csv, auth.RoleBorrower, 1234)
+		assert.NoError(t, handler.ImportLoans(c))
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+
+	t.Run("non-finite numbers are refused", func(t *testing.T) {
+		csv := "principal,rate,roi,agreement_link\n" +
+			"Inf,0.1,0.1,https://x\n" +
+			"1000,NaN,0.1,https://x\n" +
+			"1000,0.1,-Infinity,https://x\n"
+		mockUsecase.EXPECT().Start(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, job *model.ImportJob) error {
+			assert.Equal(t, []model.ImportRow{
+				{Line: 2, Status: model.ImportRowFailed, Error: `principal: "Inf" is not a number`},
+				{Line: 3, Status: model.ImportRowFailed, Error: `rate: "NaN" is not a number`},
+				{Line: 4, Status: model.ImportRowFailed, Error: `roi: "-Infinity" is not a number`},
+			}, job.Rows)
+			return nil
+		})
+
+		c, _ := newContext("best_effort", csv, auth.RoleBorrower, 1234)
+		assert.NoError(t, handler.ImportLoans(c))
+	})
+
+	t.Run("partners import for borrowers", func(t *testing.T) {
+		csv := "\ufeffborrower_id,principal,rate,roi,agreement_link\n" +
+			"5,1000,5,8,http://example.com/a\n" +
+			",1000,5,8,http://example.com/b\n"
+		mockUsecase.EXPECT().Start(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, job *model.ImportJob) error {
+			assert.Equal(t, model.ImportBestEffort, job.Mode)
+			assert.Equal(t, &model.Loan{BorrowerID: 5, PartnerID: 9, Principal: 1000, Rate: 5, ROI: 8, AgreementLink: "http://example.com/a"}, job.Rows[0].Loan)
+			assert.Equal(t, "borrower_id is required", job.Rows[1].Error)
+			return nil
+		})
+
+		c, _ := newContext("best_effort", csv, auth.RolePartner, 9)
+		assert.NoError(t, handler.ImportLoans(c))
+	})
+
+	t.Run("a borrower may not import for someone else", func(t *testing.T) {
+		mockUsecase.EXPECT().Start(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, job *model.ImportJob) error {
+			assert.Equal(t, "a borrower may only act for themselves", job.Rows[0].Error)
+			return nil
+		})
+
+		c, _ := newContext("", "borrower_id,principal,rate,roi,agreement_link\n5,1000,5,8,http://example.com/a\n", auth.RoleBorrower, 1234)
+		assert.NoError(t, handler.ImportLoans(c))
+	})
+
+	t.Run("rejected files", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			mode   string
+			csv    string
+			status int
+			err    string
+		}{
+			{"invalid mode", "sometimes", "principal\n", http.StatusBadRequest, "oneof"},
+			{"empty file", "", "", http.StatusBadRequest, "the file is empty"},
+			{"no rows", "", "principal,rate,roi,agreement_link\n", http.StatusBadRequest, "the file has no rows"},
+			{"unknown column", "", "principal,rate,roi,agreement_link,colour\n", http.StatusBadRequest, `unknown column "colour"`},
+			{"missing column", "", "principal,rate,roi\n", http.StatusBadRequest, `missing column "agreement_link"`},
+			{"repeated column", "", "principal,rate,roi,agreement_link,rate\n", http.StatusBadRequest, `column "rate" appears twice`},
+			{"malformed csv", "", "principal,rate,roi,agreement_link\n\"1000,5,8,x\n", http.StatusBadRequest, "quote"},
+			{"too many rows", "", "principal,rate,roi,agreement_link\n1,1,1,a\n1,1,1,a\n1,1,1,a\n1,1,1,a\n", http.StatusRequestEntityTooLarge, "at most 3 rows"},
+			{"file over the limit", "", "principal,rate,roi,agreement_link\n" + strings.Repeat("1,1,1,a\n", 200000), http.StatusRequestEntityTooLarge, "at most 1048576 bytes"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c, _ := newContext(tt.mode, tt.csv, auth.RoleBorrower, 1234)
+				err := handler.ImportLoans(c)
+				var he *echo.HTTPError
+				if assert.ErrorAs(t, err, &he) {
+					assert.Equal(t, tt.status, he.Code)
+				}
+				assert.ErrorContains(t, err, tt.err)
+			})
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockUsecase.EXPECT().Start(gomock.Any(), gomock.Any()).Return(errors.New("import job already exists"))
+
+		c, _ := newContext("", "principal,rate,roi,agreement_link\n1,1,1,a\n", auth.RoleBorrower, 1234)
+		assert.ErrorContains(t, handler.ImportLoans(c), "import job already exists")
+	})
+}
+
+func TestGetImportHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := importmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewImportHandler(mockUsecase, func() int { return 3 }, 1<<20)
+
+	newContext := func(role auth.Role, id int64) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/loans/import/1", nil)
+		req = withPrincipal(req, role, id)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/loans/import/:id")
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		return c, rec
+	}
+	job := &model.ImportJob{ID: 1, Status: model.ImportRunning, OwnerRole: "borrower", OwnerID: 1234}
+
+	t.Run("owner", func(t *testing.T) {
+		mockUsecase.EXPECT().Job(gomock.Any(), int64(1)).Return(job, nil)
+
+		c, rec := newContext(auth.RoleBorrower, 1234)
+		assert.NoError(t, handler.GetImport(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"running"`)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		mockUsecase.EXPECT().Job(gomock.Any(), int64(1)).Return(job, nil)
+
+		c, _ := newContext(auth.RoleAdmin, 1)
+		assert.NoError(t, handler.GetImport(c))
+	})
+
+	t.Run("someone else", func(t *testing.T) {
+		mockUsecase.EXPECT().Job(gomock.Any(), int64(1)).Return(job, nil)
+
+		c, _ := newContext(auth.RoleBorrower, 99)
+		assert.ErrorContains(t, handler.GetImport(c), "whoever started it")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUsecase.EXPECT().Job(gomock.Any(), int64(1)).Return(nil, errors.New("import job not found"))
+
+		c, _ := newContext(auth.RoleBorrower, 1234)
+		assert.ErrorContains(t, handler.GetImport(c), "import job not found")
+	})
+}
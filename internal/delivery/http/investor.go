@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/investor"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InvestorHandler struct {
+	uc investor.Usecase
+}
+
+func NewInvestorHandler(uc investor.Usecase) *InvestorHandler {
+	return &InvestorHandler{uc: uc}
+}
+
+func (h *InvestorHandler) GetPortfolio(c echo.Context) error {
+	req := new(request.GetPortfolioRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	portfolio, err := h.uc.Portfolio(c.Request().Context(), req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"portfolio": portfolio,
+	})
+}
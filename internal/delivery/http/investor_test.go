@@ -0,0 +1,64 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	investormock "loan_system/internal/usecase/investor/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetPortfolioHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := investormock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewInvestorHandler(mockUsecase)
+
+	newContext := func(id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/investors/"+id+"/portfolio", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/investors/:id/portfolio")
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		portfolio := model.NewPortfolio(7, []*model.Loan{
+			{ID: 1, BorrowerID: 3, Principal: 1000, ROI: 0.1, State: model.StateInvested, Investments: []model.Investment{{InvestorID: 7, Amount: 1000}}},
+		})
+		mockUsecase.EXPECT().Portfolio(gomock.Any(), int64(7)).Return(portfolio, nil)
+
+		c, rec := newContext("7")
+		assert.NoError(t, handler.GetPortfolio(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"share_percentage":100`)
+		assert.Contains(t, rec.Body.String(), `"borrower_hhi":1`)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		c, _ := newContext("abc")
+		err := handler.GetPortfolio(c)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		mockUsecase.EXPECT().Portfolio(gomock.Any(), int64(7)).Return(nil, errors.New("boom"))
+
+		c, _ := newContext("7")
+		err := handler.GetPortfolio(c)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+	})
+}
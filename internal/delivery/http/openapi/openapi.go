@@ -0,0 +1,41 @@
+// Package openapi serves the OpenAPI document describing the HTTP API, along
+// with a Swagger UI to browse it, and checks traffic against it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+//go:embed openapi.yaml
+var document []byte
+
+// Load parses and validates the bundled OpenAPI document.
+func Load() (*openapi3.T, error) {
+	spec, err := openapi3.NewLoader().LoadFromData(document)
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Handler serves spec as JSON.
+func Handler(spec *openapi3.T) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, spec)
+	}
+}
+
+// Docs serves a Swagger UI under basePath that browses the document served at
+// specURL. Its assets are bundled, so it works offline.
+func Docs(specURL, basePath string) http.Handler {
+	return v5emb.New("Loan Management System", specURL, basePath)
+}
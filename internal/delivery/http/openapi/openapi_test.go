@@ -0,0 +1,179 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"loan_system/internal/delivery/http/openapi"
+	"loan_system/internal/model/request"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.NotNil(t, spec.Paths.Find("/loans/{id}/invest"))
+}
+
+func TestHandler(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/openapi.json", nil), rec)
+
+	require.NoError(t, openapi.Handler(spec)(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"/loans/{id}/approve"`)
+}
+
+func TestDocs(t *testing.T) {
+	rec := httptest.NewRecorder()
+	openapi.Docs("/openapi.json", "/docs/").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/openapi.json")
+}
+
+// TestRequestStructs fails when a request struct and the operation it binds
+// disagree on which parameters or body fields exist, or which are required.
+func TestRequestStructs(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	tests := []struct {
+		method  string
+		path    string
+		request interface{}
+		// handled lists fields the handler reads itself rather than binding
+		handled []string
+	}{
+		{http.MethodPost, "/loans", request.CreateLoanRequest{}, nil},
+		{http.MethodGet, "/loans", request.GetLoansRequest{}, nil},
+		{http.MethodPost, "/loans/import", request.ImportLoansRequest{}, []string{"file"}},
+		{http.MethodGet, "/loans/import/{id}", request.GetImportRequest{}, nil},
+		{http.MethodGet, "/loans/export", request.ExportLoansRequest{}, nil},
+		{http.MethodGet, "/loans/{id}", request.GetLoanRequest{}, nil},
+		{http.MethodGet, "/loans/{id}/stream", request.StreamLoanRequest{}, []string{"last_event_id"}},
+		{http.MethodPut, "/loans/{id}/approve", request.ApproveLoanRequest{}, nil},
+		{http.MethodPut, "/loans/approve", request.ApproveLoansRequest{}, nil},
+		{http.MethodPost, "/loans/{id}/invest", request.InvestLoanRequest{}, nil},
+		{http.MethodPut, "/loans/{id}/disburse", request.DisburseLoanRequest{}, nil},
+		{http.MethodPut, "/loans/disburse", request.DisburseLoansRequest{}, nil},
+		{http.MethodGet, "/loans/{id}/agreements/{investorID}", request.GetAgreementRequest{}, nil},
+		{http.MethodPost, "/loans/{id}/documents", request.UploadDocumentRequest{}, []string{"file"}},
+		{http.MethodGet, "/investors/{id}/portfolio", request.GetPortfolioRequest{}, nil},
+		{http.MethodGet, "/borrowers/{id}/loans", request.GetBorrowerLoansRequest{}, nil},
+		{http.MethodGet, "/stats/loans", request.GetLoanStatsRequest{}, nil},
+		{http.MethodPost, "/partners", request.CreatePartnerRequest{}, nil},
+		{http.MethodPost, "/partners/{id}/keys", request.IssuePartnerKeyRequest{}, nil},
+		{http.MethodGet, "/partners/{id}/keys", request.GetPartnerKeysRequest{}, nil},
+		{http.MethodDelete, "/partners/{id}/keys/{keyID}", request.RevokePartnerKeyRequest{}, nil},
+		{http.MethodPost, "/webhooks", request.CreateWebhookRequest{}, nil},
+		{http.MethodGet, "/webhooks", request.GetWebhooksRequest{}, nil},
+		{http.MethodGet, "/webhooks/{id}", request.GetWebhookRequest{}, nil},
+		{http.MethodDelete, "/webhooks/{id}", request.DeleteWebhookRequest{}, nil},
+		{http.MethodGet, "/webhooks/deliveries", request.GetWebhookDeliveriesRequest{}, nil},
+		{http.MethodGet, "/webhooks/deliveries/{id}", request.GetWebhookDeliveryRequest{}, nil},
+		{http.MethodPost, "/webhooks/deliveries/{id}/redeliver", request.RedeliverWebhookRequest{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			pathItem := spec.Paths.Find(tt.path)
+			require.NotNil(t, pathItem)
+			operation := pathItem.GetOperation(tt.method)
+			require.NotNil(t, operation)
+
+			want, wantRequired := structFields(reflect.TypeOf(tt.request))
+			got, gotRequired := operationFields(operation)
+			for _, name := range tt.handled {
+				delete(got, name)
+				delete(gotRequired, name)
+			}
+
+			assert.Equal(t, keys(want), keys(got), "fields")
+			for name, in := range want {
+				assert.Equal(t, in, got[name], "location of %s", name)
+			}
+			assert.Equal(t, keys(wantRequired), keys(gotRequired), "required fields")
+		})
+	}
+}
+
+// structFields returns where each bound field of a request struct is read
+// from, and which of them are required.
+func structFields(typ reflect.Type) (map[string]string, map[string]string) {
+	fields := map[string]string{}
+	required := map[string]string{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		for _, in := range []string{"param", "query", "json", "form"} {
+			name, ok := field.Tag.Lookup(in)
+			if !ok {
+				continue
+			}
+			fields[name] = in
+			rules := strings.Split(field.Tag.Get("validate"), ",")
+			if in == "param" || rules[0] == "required" {
+				required[name] = in
+			}
+		}
+	}
+	return fields, required
+}
+
+// operationFields returns the same for an operation, leaving out headers.
+func operationFields(operation *openapi3.Operation) (map[string]string, map[string]string) {
+	fields := map[string]string{}
+	required := map[string]string{}
+	for _, ref := range operation.Parameters {
+		parameter := ref.Value
+		in := parameter.In
+		switch in {
+		case openapi3.ParameterInHeader:
+			continue
+		case openapi3.ParameterInPath:
+			in = "param"
+		}
+		fields[parameter.Name] = in
+		if parameter.Required {
+			required[parameter.Name] = in
+		}
+	}
+	if operation.RequestBody == nil {
+		return fields, required
+	}
+	for mediaType, content := range operation.RequestBody.Value.Content {
+		in := "json"
+		if mediaType == "multipart/form-data" {
+			in = "form"
+		}
+		for name := range content.Schema.Value.Properties {
+			fields[name] = in
+		}
+		for _, name := range content.Schema.Value.Required {
+			required[name] = in
+		}
+	}
+	return fields, required
+}
+
+func keys(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
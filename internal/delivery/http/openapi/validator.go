@@ -0,0 +1,102 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+type ValidatorConfig struct {
+	Spec *openapi3.T
+	// OnError is called with every way a request or its response departs from
+	// Spec. It must not write a response.
+	OnError func(c echo.Context, err error)
+}
+
+// Validator checks each request, and the response the handler writes for it,
+// against the spec. Requests the spec rejects must be rejected by the handler
+// too. It is meant for tests, where it turns drift between the handlers and
+// the document into failures; the request is served either way.
+func Validator(cfg ValidatorConfig) (echo.MiddlewareFunc, error) {
+	// match on paths alone so requests to any host are checked
+	spec := *cfg.Spec
+	spec.Servers = nil
+	router, err := gorillamux.NewRouter(&spec)
+	if err != nil {
+		return nil, err
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+		MultiError:            true,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, pathParams, err := router.FindRoute(req)
+			if err != nil {
+				cfg.OnError(c, fmt.Errorf("%s %s is not documented: %w", req.Method, req.URL.Path, err))
+				return next(c)
+			}
+
+			// validate a copy so the handler still sees the original body
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			checked := req.Clone(req.Context())
+			checked.Body = io.NopCloser(bytes.NewReader(body))
+			input := &openapi3filter.RequestValidationInput{
+				Request:    checked,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			requestErr := openapi3filter.ValidateRequest(req.Context(), input)
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// handle the error here so the response it produces is checked too
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// rejecting a request the spec rejects too is not drift
+			status := c.Response().Status
+			if requestErr != nil && status < http.StatusBadRequest {
+				cfg.OnError(c, fmt.Errorf("request to %s %s was accepted: %w", req.Method, req.URL.Path, requestErr))
+			}
+
+			output := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 status,
+				Header:                 c.Response().Header(),
+				Options:                options,
+			}
+			output.SetBodyBytes(recorder.body.Bytes())
+			if err := openapi3filter.ValidateResponse(req.Context(), output); err != nil {
+				cfg.OnError(c, fmt.Errorf("response to %s %s: %w", req.Method, req.URL.Path, err))
+			}
+			return nil
+		}
+	}, nil
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,114 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"loan_system/internal/delivery/http/openapi"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		handler echo.HandlerFunc
+		wantErr []string
+	}{
+		{
+			name:   "matches the spec",
+			method: http.MethodPost,
+			target: "/partners",
+			body:   `{"name":"Koperasi Maju"}`,
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{"status": 200, "data": map[string]interface{}{
+					"partner": map[string]interface{}{"id": 1, "name": "Koperasi Maju", "created_at": "2025-01-01T00:00:00Z"},
+				}})
+			},
+		},
+		{
+			name:   "invalid request rejected",
+			method: http.MethodPost,
+			target: "/partners",
+			body:   `{"name":""}`,
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+			},
+		},
+		{
+			name:   "invalid request accepted",
+			method: http.MethodPost,
+			target: "/partners",
+			body:   `{"name":"Koperasi Maju","region":"Jakarta"}`,
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{"status": 200, "data": map[string]interface{}{
+					"partner": map[string]interface{}{"id": 1, "name": "Koperasi Maju", "created_at": "2025-01-01T00:00:00Z"},
+				}})
+			},
+			wantErr: []string{"request to POST /partners was accepted"},
+		},
+		{
+			name:   "response missing a required field",
+			method: http.MethodPost,
+			target: "/partners",
+			body:   `{"name":"Koperasi Maju"}`,
+			handler: func(c echo.Context) error {
+				return c.JSON(http.StatusOK, map[string]interface{}{"status": 200, "data": map[string]interface{}{
+					"partner": map[string]interface{}{"id": 1},
+				}})
+			},
+			wantErr: []string{"response to POST /partners"},
+		},
+		{
+			name:   "undocumented status",
+			method: http.MethodGet,
+			target: "/loans/1",
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusTeapot, "teapot")
+			},
+			wantErr: []string{"response to GET /loans/1"},
+		},
+		{
+			name:    "undocumented route",
+			method:  http.MethodGet,
+			target:  "/loans/1/history",
+			handler: func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+			wantErr: []string{"GET /loans/1/history is not documented"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []string
+			validator, err := openapi.Validator(openapi.ValidatorConfig{
+				Spec: spec,
+				OnError: func(c echo.Context, err error) {
+					errs = append(errs, err.Error())
+				},
+			})
+			require.NoError(t, err)
+
+			e := echo.New()
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			require.NoError(t, validator(tt.handler)(c))
+			require.Len(t, errs, len(tt.wantErr), "errors: %v", errs)
+			for i, want := range tt.wantErr {
+				assert.Contains(t, errs[i], want)
+			}
+		})
+	}
+}
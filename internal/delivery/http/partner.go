@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/partner"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PartnerHandler struct {
+	uc partner.Usecase
+}
+
+func NewPartnerHandler(uc partner.Usecase) *PartnerHandler {
+	return &PartnerHandler{uc: uc}
+}
+
+func (h *PartnerHandler) CreatePartner(c echo.Context) error {
+	req := new(request.CreatePartnerRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partner := &model.Partner{Name: req.Name}
+	if err := h.uc.Create(c.Request().Context(), partner); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"partner": partner,
+	})
+}
+
+// IssuePartnerKey responds with the key secret. It is not shown again.
+func (h *PartnerHandler) IssuePartnerKey(c echo.Context) error {
+	req := new(request.IssuePartnerKeyRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	scopes := make([]model.PartnerScope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, model.PartnerScope(scope))
+	}
+
+	key, err := h.uc.IssueKey(c.Request().Context(), req.ID, scopes)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"key": key,
+	})
+}
+
+func (h *PartnerHandler) GetPartnerKeys(c echo.Context) error {
+	req := new(request.GetPartnerKeysRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	keys, err := h.uc.Keys(c.Request().Context(), req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"keys": keys,
+	})
+}
+
+func (h *PartnerHandler) RevokePartnerKey(c echo.Context) error {
+	req := new(request.RevokePartnerKeyRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	key, err := h.uc.RevokeKey(c.Request().Context(), req.ID, req.KeyID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"key": key,
+	})
+}
@@ -0,0 +1,81 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	partnermock "loan_system/internal/usecase/partner/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPartnerHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := partnermock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewPartnerHandler(mockUsecase)
+
+	newContext := func(method, path, body string, names, values []string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		return c, rec
+	}
+
+	t.Run("create partner", func(t *testing.T) {
+		mockUsecase.EXPECT().Create(gomock.Any(), &model.Partner{Name: "Koperasi Maju"}).Return(nil)
+
+		c, rec := newContext(http.MethodPost, "/partners", `{"name":"Koperasi Maju"}`, nil, nil)
+		assert.NoError(t, handler.CreatePartner(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("create partner without name", func(t *testing.T) {
+		c, _ := newContext(http.MethodPost, "/partners", `{}`, nil, nil)
+		assert.ErrorContains(t, handler.CreatePartner(c), "required")
+	})
+
+	t.Run("issue key", func(t *testing.T) {
+		scopes := []model.PartnerScope{model.PartnerScopeLoansCreate, model.PartnerScopeLoansInvest}
+		mockUsecase.EXPECT().IssueKey(gomock.Any(), int64(7), scopes).Return(&model.PartnerKey{ID: "pk_1", PartnerID: 7, Secret: "s3cret", Scopes: scopes}, nil)
+
+		c, rec := newContext(http.MethodPost, "/partners/7/keys", `{"scopes":["loans:create","loans:invest"]}`, []string{"id"}, []string{"7"})
+		assert.NoError(t, handler.IssuePartnerKey(c))
+		assert.Contains(t, rec.Body.String(), `"secret":"s3cret"`)
+	})
+
+	t.Run("issue key with unknown scope", func(t *testing.T) {
+		c, _ := newContext(http.MethodPost, "/partners/7/keys", `{"scopes":["loans:delete"]}`, []string{"id"}, []string{"7"})
+		assert.ErrorContains(t, handler.IssuePartnerKey(c), "oneof")
+	})
+
+	t.Run("list keys", func(t *testing.T) {
+		mockUsecase.EXPECT().Keys(gomock.Any(), int64(7)).Return([]*model.PartnerKey{{ID: "pk_1", PartnerID: 7}}, nil)
+
+		c, rec := newContext(http.MethodGet, "/partners/7/keys", "", []string{"id"}, []string{"7"})
+		assert.NoError(t, handler.GetPartnerKeys(c))
+		assert.Contains(t, rec.Body.String(), `"id":"pk_1"`)
+	})
+
+	t.Run("revoke key", func(t *testing.T) {
+		mockUsecase.EXPECT().RevokeKey(gomock.Any(), int64(7), "pk_1").Return(nil, errors.New("partner key not found"))
+
+		c, _ := newContext(http.MethodDelete, "/partners/7/keys/pk_1", "", []string{"id", "keyID"}, []string{"7", "pk_1"})
+		err := handler.RevokePartnerKey(c)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+	})
+}
@@ -5,8 +5,9 @@ import (
 )
 
 type SuccessResponse struct {
-	Status int         `json:"status"`
-	Data   interface{} `json:"data"`
+	Status     int         `json:"status"`
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 func Success(c echo.Context, status int, data interface{}) error {
@@ -15,3 +16,12 @@ func Success(c echo.Context, status int, data interface{}) error {
 		Data:   data,
 	})
 }
+
+// SuccessPage responds with one page of a paginated listing.
+func SuccessPage(c echo.Context, status int, data interface{}, nextCursor string) error {
+	return c.JSON(status, SuccessResponse{
+		Status:     status,
+		Data:       data,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/usecase/stats"
+
+	"github.com/labstack/echo/v4"
+)
+
+type StatsHandler struct {
+	uc stats.Usecase
+}
+
+func NewStatsHandler(uc stats.Usecase) *StatsHandler {
+	return &StatsHandler{uc: uc}
+}
+
+func (h *StatsHandler) GetLoanStats(c echo.Context) error {
+	req := new(request.GetLoanStatsRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		return echo.NewHTTPError(http.StatusBadRequest, "to must not be before from")
+	}
+
+	result, err := h.uc.LoanStats(c.Request().Context(), req.From, req.To, model.StatsGrouping(req.GroupBy))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"stats": result,
+	})
+}
@@ -0,0 +1,68 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	statsmock "loan_system/internal/usecase/stats/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetLoanStatsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := statsmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewStatsHandler(mockUsecase)
+
+	newContext := func(query string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/stats/loans"+query, nil)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		result := model.NewLoanStats([]*model.Loan{{ID: 1, Principal: 1000, State: model.StateProposed, CreatedAt: from}}, from, to, model.GroupByWeek)
+		mockUsecase.EXPECT().LoanStats(gomock.Any(), from, to, model.GroupByWeek).Return(result, nil)
+
+		c, rec := newContext("?from=2024-01-01T00:00:00Z&to=2024-03-31T00:00:00Z&group_by=week")
+		assert.NoError(t, handler.GetLoanStats(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"PROPOSED":{"count":1,"principal":1000}`)
+		assert.Contains(t, rec.Body.String(), `"group_by":"week"`)
+	})
+
+	t.Run("invalid grouping", func(t *testing.T) {
+		c, _ := newContext("?group_by=year")
+		err := handler.GetLoanStats(c)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("inverted range", func(t *testing.T) {
+		c, _ := newContext("?from=2024-03-31T00:00:00Z&to=2024-01-01T00:00:00Z")
+		err := handler.GetLoanStats(c)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("usecase error", func(t *testing.T) {
+		mockUsecase.EXPECT().LoanStats(gomock.Any(), time.Time{}, time.Time{}, model.StatsGrouping("")).Return(nil, errors.New("boom"))
+
+		c, _ := newContext("")
+		err := handler.GetLoanStats(c)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+	})
+}
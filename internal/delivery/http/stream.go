@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/usecase/stream"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderLastEventID is sent by reconnecting EventSource clients with the ID
+// of the last event they received.
+const HeaderLastEventID = "Last-Event-ID"
+
+// QueryLastEventID stands in for the Last-Event-ID header when a browser
+// opens a new EventSource to resume a stream, as it can't set headers.
+const QueryLastEventID = "last_event_id"
+
+type StreamHandler struct {
+	uc stream.Usecase
+	// heartbeat is how often an idle stream sends a comment, so proxies
+	// and clients don't time the connection out
+	heartbeat time.Duration
+	tokens    *auth.Authenticator
+	// tokenTTL is how long a stream token may be used to open a stream
+	tokenTTL time.Duration
+}
+
+func NewStreamHandler(uc stream.Usecase, heartbeat time.Duration, tokens *auth.Authenticator, tokenTTL time.Duration) *StreamHandler {
+	return &StreamHandler{uc: uc, heartbeat: heartbeat, tokens: tokens, tokenTTL: tokenTTL}
+}
+
+// CreateStreamToken issues the caller a short-lived token to pass as the
+// access_token query parameter of a stream, for browsers whose EventSource
+// can't send the Authorization header. Once open, a stream outlives it.
+func (h *StreamHandler) CreateStreamToken(c echo.Context) error {
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(h.tokenTTL).UTC()
+	token, err := h.tokens.IssueStreamToken(principal, h.tokenTTL)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
+
+// StreamLoans pushes an event whenever any loan changes state or receives an
+// investment.
+func (h *StreamHandler) StreamLoans(c echo.Context) error {
+	return h.stream(c, 0)
+}
+
+// StreamLoan pushes the events of a single loan.
+func (h *StreamHandler) StreamLoan(c echo.Context) error {
+	req := new(request.StreamLoanRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	return h.stream(c, req.ID)
+}
+
+// stream writes server-sent events until the client goes away or the stream
+// is closed for shutdown. Each event carries the loan event ID, its type and
+// the event as JSON. Clients resume with Last-Event-ID, or with
+// last_event_id when they can't set it.
+func (h *StreamHandler) stream(c echo.Context, loanID int64) error {
+	var lastEventID int64
+	value, name := c.Request().Header.Get(HeaderLastEventID), HeaderLastEventID
+	if value == "" {
+		value, name = c.QueryParam(QueryLastEventID), QueryLastEventID
+	}
+	if value != "" {
+		var err error
+		if lastEventID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+		}
+	}
+
+	ctx := c.Request().Context()
+	sub, err := h.uc.Subscribe(ctx, loanID, lastEventID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	defer sub.Close()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	// keep reverse proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range sub.Replay {
+		if err := writeEvent(w, event); err != nil {
+			return nil
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-sub.Events:
+			if !ok {
+				return nil
+			}
+			if err := writeEvent(w, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+	}
+}
+
+func writeEvent(w *echo.Response, event model.LoanEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,141 @@
+package http_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/usecase/stream"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStreamHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loanMock := loanrepo.NewMockRepository(ctrl)
+	uc := stream.NewUsecase(loanMock, 10)
+	tokens := auth.NewAuthenticator("secret")
+	handler := httpHandler.NewStreamHandler(uc, 20*time.Millisecond, tokens, time.Minute)
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	e.GET("/loans/stream", handler.StreamLoans)
+	e.GET("/loans/:id/stream", handler.StreamLoan)
+	e.POST("/loans/stream/token", handler.CreateStreamToken, auth.Middleware(auth.Config{Tokens: tokens}))
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	open := func(path, lastEventID string) (*http.Response, *bufio.Reader) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		if lastEventID != "" {
+			req.Header.Set(httpHandler.HeaderLastEventID, lastEventID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp, bufio.NewReader(resp.Body)
+	}
+	// next returns the next event or comment, without its trailing blank line
+	next := func(r *bufio.Reader) string {
+		t.Helper()
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+	}
+
+	event := model.LoanEvent{ID: 42, Type: model.LoanEventInvestmentAdded, Loan: &model.Loan{ID: 1, State: model.StateApproved}}
+
+	t.Run("stream pushes events and heartbeats", func(t *testing.T) {
+		resp, r := open("/loans/stream", "")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get(echo.HeaderContentType))
+
+		uc.Publish(event)
+		got := next(r)
+		for got == ": heartbeat" {
+			got = next(r)
+		}
+		assert.True(t, strings.HasPrefix(got, "id: 42\nevent: loan.investment_added\ndata: {"), got)
+		assert.Contains(t, got, `"state":"APPROVED"`)
+
+		assert.Equal(t, ": heartbeat", next(r))
+	})
+
+	t.Run("stream resumes after Last-Event-ID", func(t *testing.T) {
+		uc.Publish(model.LoanEvent{ID: 43, Type: model.LoanEventInvested, Loan: &model.Loan{ID: 2}})
+		uc.Publish(model.LoanEvent{ID: 44, Type: model.LoanEventInvested, Loan: &model.Loan{ID: 1}})
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1}, nil)
+
+		resp, r := open("/loans/1/stream", "42")
+		defer resp.Body.Close()
+		assert.True(t, strings.HasPrefix(next(r), "id: 44\n"))
+	})
+
+	t.Run("stream resumes after last_event_id", func(t *testing.T) {
+		resp, r := open("/loans/stream?"+httpHandler.QueryLastEventID+"=43", "")
+		defer resp.Body.Close()
+		assert.True(t, strings.HasPrefix(next(r), "id: 44\n"))
+	})
+
+	t.Run("stream token", func(t *testing.T) {
+		bearer, err := tokens.Issue(auth.Principal{ID: 789, Role: auth.RoleInvestor}, time.Hour)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/loans/stream/token", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+bearer)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response struct {
+			Data struct {
+				Token     string    `json:"token"`
+				ExpiresAt time.Time `json:"expires_at"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		principal, err := tokens.ParseStreamToken(response.Data.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Principal{ID: 789, Role: auth.RoleInvestor}, principal)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), response.Data.ExpiresAt, 5*time.Second)
+	})
+
+	t.Run("stream with bad Last-Event-ID", func(t *testing.T) {
+		resp, _ := open("/loans/stream", "latest")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("stream ends when closed", func(t *testing.T) {
+		resp, r := open("/loans/stream", "")
+		defer resp.Body.Close()
+
+		uc.Close()
+		for {
+			if _, err := r.ReadString('\n'); err != nil {
+				break
+			}
+		}
+	})
+}
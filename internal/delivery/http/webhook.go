@@ -0,0 +1,220 @@
+package http
+
+import (
+	"net/http"
+
+	"loan_system/internal/model"
+	"loan_system/internal/model/request"
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/usecase/webhook"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHandler struct {
+	uc webhook.Usecase
+}
+
+func NewWebhookHandler(uc webhook.Usecase) *WebhookHandler {
+	return &WebhookHandler{uc: uc}
+}
+
+// CreateWebhook responds with the signing secret. It is not shown again.
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	req := new(request.CreateWebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	principal, err := authenticated(c)
+	if err != nil {
+		return err
+	}
+	partnerID, err := onBehalfOf(principal, auth.RolePartner, req.PartnerID, "partner_id")
+	if err != nil {
+		return err
+	}
+
+	subscription := &model.WebhookSubscription{PartnerID: partnerID, URL: req.URL}
+	for _, eventType := range req.EventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, model.LoanEventType(eventType))
+	}
+	if err := h.uc.Subscribe(c.Request().Context(), subscription); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"webhook": subscription,
+	})
+}
+
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	req := new(request.GetWebhooksRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partnerID, err := webhookPartner(c, req.PartnerID)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := h.uc.Subscriptions(c.Request().Context(), partnerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"webhooks": subscriptions,
+	})
+}
+
+func (h *WebhookHandler) GetWebhook(c echo.Context) error {
+	req := new(request.GetWebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partnerID, err := webhookPartner(c, 0)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := h.uc.Subscription(c.Request().Context(), partnerID, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"webhook": subscription,
+	})
+}
+
+// DeleteWebhook stops future deliveries. Queued deliveries are dead-lettered
+// and the delivery log is kept.
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	req := new(request.DeleteWebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partnerID, err := webhookPartner(c, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := h.uc.Unsubscribe(c.Request().Context(), partnerID, req.ID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"id": req.ID,
+	})
+}
+
+// GetWebhookDeliveries lists the delivery log. Filtering by status failed
+// gives the dead-letter list.
+func (h *WebhookHandler) GetWebhookDeliveries(c echo.Context) error {
+	req := new(request.GetWebhookDeliveriesRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partnerID, err := webhookPartner(c, req.PartnerID)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := h.uc.Deliveries(c.Request().Context(), model.WebhookDeliveryQuery{
+		PartnerID:      partnerID,
+		SubscriptionID: req.SubscriptionID,
+		Status:         model.WebhookDeliveryStatus(req.Status),
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+	})
+}
+
+func (h *WebhookHandler) GetWebhookDelivery(c echo.Context) error {
+	req := new(request.GetWebhookDeliveryRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partnerID, err := webhookPartner(c, 0)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := h.uc.Delivery(c.Request().Context(), partnerID, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"delivery": delivery,
+	})
+}
+
+func (h *WebhookHandler) RedeliverWebhook(c echo.Context) error {
+	req := new(request.RedeliverWebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	partnerID, err := webhookPartner(c, 0)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := h.uc.Redeliver(c.Request().Context(), partnerID, req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return Success(c, http.StatusOK, map[string]interface{}{
+		"delivery": delivery,
+	})
+}
+
+// webhookPartner resolves whose webhooks a request reads or changes. Partners
+// only see their own; admins see the given partner's, or everyone's when id
+// is 0.
+func webhookPartner(c echo.Context, id int64) (int64, error) {
+	principal, err := authenticated(c)
+	if err != nil {
+		return 0, err
+	}
+	if principal.Role != auth.RolePartner {
+		return id, nil
+	}
+	if id != 0 && id != principal.ID {
+		return 0, echo.NewHTTPError(http.StatusForbidden, "a partner may only act for themselves")
+	}
+	return principal.ID, nil
+}
@@ -0,0 +1,122 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpHandler "loan_system/internal/delivery/http"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
+	webhookmock "loan_system/internal/usecase/webhook/mock"
+
+	"github.com/go-playground/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	e := echo.New()
+	e.Validator = &CustomValidator{validator: validator.New()}
+	mockUsecase := webhookmock.NewMockUsecase(ctrl)
+	handler := httpHandler.NewWebhookHandler(mockUsecase)
+
+	newContext := func(role auth.Role, id int64, method, path, body string, names, values []string) (echo.Context, *httptest.ResponseRecorder) {
+		req := withPrincipal(httptest.NewRequest(method, path, strings.NewReader(body)), role, id)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+		return c, rec
+	}
+
+	t.Run("partner creates webhook", func(t *testing.T) {
+		mockUsecase.EXPECT().Subscribe(gomock.Any(), &model.WebhookSubscription{
+			PartnerID:  7,
+			URL:        "https://partner.example/hooks",
+			EventTypes: []model.LoanEventType{model.LoanEventApproved},
+		}).DoAndReturn(func(_ any, s *model.WebhookSubscription) error {
+			s.Secret = "whsec_1"
+			return nil
+		})
+
+		c, rec := newContext(auth.RolePartner, 7, http.MethodPost, "/webhooks", `{"url":"https://partner.example/hooks","event_types":["loan.approved"]}`, nil, nil)
+		assert.NoError(t, handler.CreateWebhook(c))
+		assert.Contains(t, rec.Body.String(), `"secret":"whsec_1"`)
+	})
+
+	t.Run("create webhook with unknown event type", func(t *testing.T) {
+		c, _ := newContext(auth.RolePartner, 7, http.MethodPost, "/webhooks", `{"url":"https://partner.example/hooks","event_types":["loan.deleted"]}`, nil, nil)
+		assert.ErrorContains(t, handler.CreateWebhook(c), "oneof")
+	})
+
+	t.Run("partner creates webhook for another partner", func(t *testing.T) {
+		c, _ := newContext(auth.RolePartner, 7, http.MethodPost, "/webhooks", `{"partner_id":8,"url":"https://partner.example/hooks","event_types":["loan.approved"]}`, nil, nil)
+		err := handler.CreateWebhook(c)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("admin creates webhook without partner", func(t *testing.T) {
+		c, _ := newContext(auth.RoleAdmin, 1, http.MethodPost, "/webhooks", `{"url":"https://partner.example/hooks","event_types":["loan.approved"]}`, nil, nil)
+		err := handler.CreateWebhook(c)
+		assert.Equal(t, http.StatusBadRequest, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("partner lists own webhooks", func(t *testing.T) {
+		mockUsecase.EXPECT().Subscriptions(gomock.Any(), int64(7)).Return([]*model.WebhookSubscription{{ID: 3, PartnerID: 7}}, nil)
+
+		c, rec := newContext(auth.RolePartner, 7, http.MethodGet, "/webhooks", "", nil, nil)
+		assert.NoError(t, handler.GetWebhooks(c))
+		assert.Contains(t, rec.Body.String(), `"id":3`)
+	})
+
+	t.Run("partner lists another partner's webhooks", func(t *testing.T) {
+		c, _ := newContext(auth.RolePartner, 7, http.MethodGet, "/webhooks?partner_id=8", "", nil, nil)
+		err := handler.GetWebhooks(c)
+		assert.Equal(t, http.StatusForbidden, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("admin lists every webhook", func(t *testing.T) {
+		mockUsecase.EXPECT().Subscriptions(gomock.Any(), int64(0)).Return(nil, nil)
+
+		c, _ := newContext(auth.RoleAdmin, 1, http.MethodGet, "/webhooks", "", nil, nil)
+		assert.NoError(t, handler.GetWebhooks(c))
+	})
+
+	t.Run("delete webhook", func(t *testing.T) {
+		mockUsecase.EXPECT().Unsubscribe(gomock.Any(), int64(7), int64(3)).Return(errors.New("webhook subscription not found"))
+
+		c, _ := newContext(auth.RolePartner, 7, http.MethodDelete, "/webhooks/3", "", []string{"id"}, []string{"3"})
+		err := handler.DeleteWebhook(c)
+		assert.Equal(t, http.StatusInternalServerError, err.(*echo.HTTPError).Code)
+	})
+
+	t.Run("list dead letters", func(t *testing.T) {
+		mockUsecase.EXPECT().Deliveries(gomock.Any(), model.WebhookDeliveryQuery{PartnerID: 7, Status: model.WebhookDeliveryFailed}).
+			Return([]*model.WebhookDelivery{{ID: 5, Status: model.WebhookDeliveryFailed}}, nil)
+
+		c, rec := newContext(auth.RolePartner, 7, http.MethodGet, "/webhooks/deliveries?status=failed", "", nil, nil)
+		assert.NoError(t, handler.GetWebhookDeliveries(c))
+		assert.Contains(t, rec.Body.String(), `"status":"failed"`)
+	})
+
+	t.Run("list deliveries with unknown status", func(t *testing.T) {
+		c, _ := newContext(auth.RolePartner, 7, http.MethodGet, "/webhooks/deliveries?status=lost", "", nil, nil)
+		assert.ErrorContains(t, handler.GetWebhookDeliveries(c), "oneof")
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		mockUsecase.EXPECT().Redeliver(gomock.Any(), int64(7), int64(5)).Return(&model.WebhookDelivery{ID: 5, Status: model.WebhookDeliveryPending}, nil)
+
+		c, rec := newContext(auth.RolePartner, 7, http.MethodPost, "/webhooks/deliveries/5/redeliver", "", []string{"id"}, []string{"5"})
+		assert.NoError(t, handler.RedeliverWebhook(c))
+		assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	})
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+type AgreementFormat string
+
+const (
+	AgreementFormatHTML AgreementFormat = "html"
+	AgreementFormatPDF  AgreementFormat = "pdf"
+)
+
+type Agreement struct {
+	LoanID         int64               `json:"loan_id"`
+	InvestorID     int64               `json:"investor_id"`
+	Amount         float64             `json:"amount"`
+	ExpectedReturn float64             `json:"expected_return"`
+	Documents      []AgreementDocument `json:"documents"`
+	GeneratedAt    time.Time           `json:"generated_at"`
+}
+
+type AgreementDocument struct {
+	Format      AgreementFormat `json:"format"`
+	ContentType string          `json:"content_type"`
+	ContentHash string          `json:"content_hash"`
+	Size        int64           `json:"size"`
+	BlobKey     string          `json:"-"`
+}
+
+func (a *Agreement) Document(format AgreementFormat) (*AgreementDocument, bool) {
+	for i := range a.Documents {
+		if a.Documents[i].Format == format {
+			return &a.Documents[i], true
+		}
+	}
+	return nil, false
+}
+
+type Blob struct {
+	Key    string `json:"key"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
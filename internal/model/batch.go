@@ -0,0 +1,27 @@
+package model
+
+type BatchStatus string
+
+const (
+	BatchSucceeded BatchStatus = "succeeded"
+	BatchFailed    BatchStatus = "failed"
+	// BatchSkipped loans could have been changed but were left alone because
+	// another loan of an atomic batch failed.
+	BatchSkipped BatchStatus = "skipped"
+)
+
+// BatchResult is the outcome of one loan of a bulk approval or
+// disbursement. Loan is the loan as changed, for those that succeeded.
+type BatchResult struct {
+	LoanID int64       `json:"loan_id"`
+	Status BatchStatus `json:"status"`
+	Loan   *Loan       `json:"loan,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// BatchItem is one loan of a bulk approval or disbursement and the document,
+// uploaded to that loan, it is changed against.
+type BatchItem struct {
+	LoanID     int64
+	DocumentID int64
+}
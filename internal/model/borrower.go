@@ -0,0 +1,115 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type Installment struct {
+	LoanID  int64     `json:"loan_id"`
+	Number  int       `json:"number"`
+	DueDate time.Time `json:"due_date"`
+	Amount  float64   `json:"amount"`
+}
+
+// Schedule splits the repayable amount, principal plus flat interest, into
+// equal monthly installments starting one month after disbursement. Loans
+// that have not been disbursed yet have no schedule.
+func (l *Loan) Schedule() []Installment {
+	if l.Disbursement == nil {
+		return nil
+	}
+
+	tenor := l.TenorMonths
+	if tenor <= 0 {
+		tenor = DefaultTenorMonths
+	}
+
+	total := l.Principal * (1 + l.Rate)
+	amount := math.Round(total/float64(tenor)*100) / 100
+	installments := make([]Installment, tenor)
+	for i := range installments {
+		installments[i] = Installment{
+			LoanID:  l.ID,
+			Number:  i + 1,
+			DueDate: l.Disbursement.DisbursedAt.AddDate(0, i+1, 0),
+			Amount:  amount,
+		}
+	}
+	// the last installment absorbs the rounding difference
+	installments[tenor-1].Amount = math.Round((total-amount*float64(tenor-1))*100) / 100
+
+	return installments
+}
+
+type BorrowerLoan struct {
+	Loan            *Loan        `json:"loan"`
+	Outstanding     float64      `json:"outstanding"`
+	NextInstallment *Installment `json:"next_installment,omitempty"`
+}
+
+type BorrowerSummary struct {
+	TotalBorrowed    float64      `json:"total_borrowed"`
+	TotalOutstanding float64      `json:"total_outstanding"`
+	LoanCycles       int          `json:"loan_cycles"`
+	NextInstallment  *Installment `json:"next_installment,omitempty"`
+}
+
+type BorrowerDashboard struct {
+	BorrowerID int64           `json:"borrower_id"`
+	Active     []BorrowerLoan  `json:"active"`
+	Historical []BorrowerLoan  `json:"historical"`
+	Summary    BorrowerSummary `json:"summary"`
+}
+
+// NewBorrowerDashboard groups the borrower's loans as of the given time. Loans
+// still in the funding pipeline or with installments left are active, disbursed
+// loans past their last due date are historical. Repayments are not recorded
+// yet, so installments are assumed to be paid on their due date.
+func NewBorrowerDashboard(borrowerID int64, loans []*Loan, at time.Time) *BorrowerDashboard {
+	dashboard := &BorrowerDashboard{
+		BorrowerID: borrowerID,
+		Active:     make([]BorrowerLoan, 0),
+		Historical: make([]BorrowerLoan, 0),
+	}
+
+	sorted := make([]*Loan, len(loans))
+	copy(sorted, loans)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for _, loan := range sorted {
+		entry := BorrowerLoan{Loan: loan}
+
+		schedule := loan.Schedule()
+		for i := range schedule {
+			if !schedule[i].DueDate.After(at) {
+				continue
+			}
+			if entry.NextInstallment == nil {
+				entry.NextInstallment = &schedule[i]
+			}
+			entry.Outstanding += schedule[i].Amount
+		}
+		entry.Outstanding = math.Round(entry.Outstanding*100) / 100
+
+		if loan.Disbursement != nil {
+			dashboard.Summary.LoanCycles++
+			dashboard.Summary.TotalBorrowed += loan.Principal
+		}
+		dashboard.Summary.TotalOutstanding += entry.Outstanding
+
+		next := entry.NextInstallment
+		if next != nil && (dashboard.Summary.NextInstallment == nil || next.DueDate.Before(dashboard.Summary.NextInstallment.DueDate)) {
+			dashboard.Summary.NextInstallment = next
+		}
+
+		if loan.Disbursement != nil && next == nil {
+			dashboard.Historical = append(dashboard.Historical, entry)
+		} else {
+			dashboard.Active = append(dashboard.Active, entry)
+		}
+	}
+
+	return dashboard
+}
@@ -0,0 +1,70 @@
+package model_test
+
+import (
+	"loan_system/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoan_Schedule(t *testing.T) {
+	disbursedAt := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("not disbursed", func(t *testing.T) {
+		loan := &model.Loan{ID: 1, Principal: 1000, Rate: 0.1, TenorMonths: 3, State: model.StateInvested}
+		assert.Nil(t, loan.Schedule())
+	})
+
+	t.Run("equal installments", func(t *testing.T) {
+		loan := &model.Loan{ID: 1, Principal: 1000, Rate: 0.1, TenorMonths: 3, Disbursement: &model.Disbursement{DisbursedAt: disbursedAt}}
+		schedule := loan.Schedule()
+		assert.Len(t, schedule, 3)
+		assert.Equal(t, 366.67, schedule[0].Amount)
+		assert.Equal(t, 366.67, schedule[1].Amount)
+		assert.Equal(t, 366.66, schedule[2].Amount)
+		assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), schedule[0].DueDate)
+		assert.Equal(t, time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC), schedule[2].DueDate)
+		assert.Equal(t, 3, schedule[2].Number)
+	})
+
+	t.Run("default tenor", func(t *testing.T) {
+		loan := &model.Loan{ID: 1, Principal: 1200, Disbursement: &model.Disbursement{DisbursedAt: disbursedAt}}
+		assert.Len(t, loan.Schedule(), model.DefaultTenorMonths)
+	})
+}
+
+func TestNewBorrowerDashboard(t *testing.T) {
+	at := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	loans := []*model.Loan{
+		// matured before at
+		{ID: 1, BorrowerID: 5, Principal: 1000, Rate: 0.1, TenorMonths: 2, State: model.StateDisbursed,
+			Disbursement: &model.Disbursement{DisbursedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}},
+		// two installments left: 2024-06-10 and 2024-07-10
+		{ID: 3, BorrowerID: 5, Principal: 2000, Rate: 0.2, TenorMonths: 4, State: model.StateDisbursed,
+			Disbursement: &model.Disbursement{DisbursedAt: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)}},
+		// still being funded
+		{ID: 2, BorrowerID: 5, Principal: 500, Rate: 0.1, TenorMonths: 6, State: model.StateApproved},
+	}
+
+	dashboard := model.NewBorrowerDashboard(5, loans, at)
+
+	assert.Len(t, dashboard.Historical, 1)
+	assert.Equal(t, int64(1), dashboard.Historical[0].Loan.ID)
+	assert.Zero(t, dashboard.Historical[0].Outstanding)
+
+	assert.Len(t, dashboard.Active, 2)
+	assert.Equal(t, int64(2), dashboard.Active[0].Loan.ID)
+	assert.Zero(t, dashboard.Active[0].Outstanding)
+	assert.Nil(t, dashboard.Active[0].NextInstallment)
+
+	running := dashboard.Active[1]
+	assert.Equal(t, int64(3), running.Loan.ID)
+	assert.Equal(t, 1200.0, running.Outstanding)
+	assert.Equal(t, 3, running.NextInstallment.Number)
+
+	assert.Equal(t, 3000.0, dashboard.Summary.TotalBorrowed)
+	assert.Equal(t, 1200.0, dashboard.Summary.TotalOutstanding)
+	assert.Equal(t, 2, dashboard.Summary.LoanCycles)
+	assert.Equal(t, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), dashboard.Summary.NextInstallment.DueDate)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type DocumentKind string
+
+const (
+	DocumentKindApprovalProof   DocumentKind = "approval_proof"
+	DocumentKindSignedAgreement DocumentKind = "signed_agreement"
+)
+
+type Document struct {
+	ID          int64        `json:"id,omitempty"`
+	LoanID      int64        `json:"loan_id,omitempty"`
+	Kind        DocumentKind `json:"kind,omitempty"`
+	FileName    string       `json:"file_name,omitempty"`
+	ContentType string       `json:"content_type,omitempty"`
+	Size        int64        `json:"size,omitempty"`
+	SHA256      string       `json:"sha256,omitempty"`
+	BlobKey     string       `json:"-"`
+	UploadedAt  time.Time    `json:"uploaded_at,omitempty"`
+}
+
+// AttachableTo reports whether the document may be referenced by the given loan as the given kind.
+func (d *Document) AttachableTo(loanID int64, kind DocumentKind) error {
+	if d.LoanID != loanID {
+		return fmt.Errorf("document %d does not belong to loan %d", d.ID, loanID)
+	}
+	if d.Kind != kind {
+		return fmt.Errorf("document %d is not of kind %s", d.ID, kind)
+	}
+	return nil
+}
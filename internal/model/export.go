@@ -0,0 +1,26 @@
+package model
+
+type ExportFormat string
+
+const (
+	// ExportCSV writes one row per investment, repeating the columns of its
+	// loan, and a single row with empty investment columns for a loan
+	// nobody has invested in.
+	ExportCSV ExportFormat = "csv"
+	// ExportNDJSON writes each loan as JSON on a line of its own.
+	ExportNDJSON ExportFormat = "ndjson"
+	// ExportParquet writes one row per loan with its investments as a list.
+	ExportParquet ExportFormat = "parquet"
+)
+
+// ContentType is the media type an export in the format is served as.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	case ExportParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv"
+	}
+}
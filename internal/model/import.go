@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+type ImportMode string
+
+const (
+	// ImportAllOrNothing creates every loan in the file or, if any row is
+	// invalid or cannot be stored, none of them.
+	ImportAllOrNothing ImportMode = "all_or_nothing"
+	// ImportBestEffort creates the loans of every valid row and reports the
+	// rest as failed.
+	ImportBestEffort ImportMode = "best_effort"
+)
+
+type ImportStatus string
+
+const (
+	ImportPending ImportStatus = "pending"
+	ImportRunning ImportStatus = "running"
+	// ImportCompleted jobs ran to the end. In best-effort mode some of
+	// their rows may still have failed.
+	ImportCompleted ImportStatus = "completed"
+	// ImportFailed jobs created no loans.
+	ImportFailed ImportStatus = "failed"
+)
+
+type ImportRowStatus string
+
+const (
+	ImportRowPending ImportRowStatus = "pending"
+	ImportRowCreated ImportRowStatus = "created"
+	ImportRowFailed  ImportRowStatus = "failed"
+	// ImportRowSkipped rows were valid but not created because another row
+	// of an all-or-nothing import failed.
+	ImportRowSkipped ImportRowStatus = "skipped"
+)
+
+// ImportJob is a CSV file of loan proposals being created in the background.
+// Only the user who uploaded it, recorded in OwnerRole and OwnerID, and
+// admins may read it.
+type ImportJob struct {
+	ID          int64        `json:"id,omitempty"`
+	Mode        ImportMode   `json:"mode,omitempty"`
+	Status      ImportStatus `json:"status,omitempty"`
+	Total       int          `json:"total"`
+	Created     int          `json:"created"`
+	Failed      int          `json:"failed"`
+	Rows        []ImportRow  `json:"rows"`
+	OwnerRole   string       `json:"-"`
+	OwnerID     int64        `json:"-"`
+	CreatedAt   time.Time    `json:"created_at,omitempty"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
+
+// ImportRow is the outcome of one CSV record. Line is where the record
+// starts in the file, counting the header as line 1. Loan is the proposal
+// read from a valid row; rows that could not be read start out failed.
+type ImportRow struct {
+	Line   int             `json:"line"`
+	Status ImportRowStatus `json:"status"`
+	LoanID int64           `json:"loan_id,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Loan   *Loan           `json:"-"`
+}
+
+// Finished reports whether the job has stopped running.
+func (j *ImportJob) Finished() bool {
+	return j.Status == ImportCompleted || j.Status == ImportFailed
+}
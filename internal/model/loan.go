@@ -14,6 +14,8 @@ const (
 	StateDisbursed LoanState = "DISBURSED"
 )
 
+const DefaultTenorMonths = 12
+
 type Loan struct {
 	ID            int64         `json:"id,omitempty"`
 	BorrowerID    int64         `json:"borrower_id,omitempty"`
@@ -25,23 +27,31 @@ type Loan struct {
 	Investments   []Investment  `json:"investments,omitempty"`
 	Disbursement  *Disbursement `json:"disbursement,omitempty"`
 	AgreementLink string        `json:"agreement_link,omitempty"`
+	TenorMonths   int           `json:"tenor_months,omitempty"`
+	CreatedAt     time.Time     `json:"created_at,omitempty"`
+	PartnerID     int64         `json:"partner_id,omitempty"`
+	// Version counts the updates stored for the loan, so an update made
+	// from a copy read before another one was stored can be refused.
+	Version int64 `json:"-"`
 }
 
 type Approval struct {
-	ValidatorID int64     `json:"validator_id,omitempty"`
-	ProofURL    string    `json:"proof_url,omitempty"`
-	ApprovedAt  time.Time `json:"approved_at,omitempty"`
+	ValidatorID     int64     `json:"validator_id,omitempty"`
+	ProofDocumentID int64     `json:"proof_document_id,omitempty"`
+	ApprovedAt      time.Time `json:"approved_at,omitempty"`
 }
 
 type Investment struct {
-	InvestorID int64   `json:"investor_id,omitempty"`
-	Amount     float64 `json:"amount,omitempty"`
+	InvestorID int64     `json:"investor_id,omitempty"`
+	Amount     float64   `json:"amount,omitempty"`
+	InvestedAt time.Time `json:"invested_at,omitempty"`
+	PartnerID  int64     `json:"partner_id,omitempty"`
 }
 
 type Disbursement struct {
-	OfficerID    int64     `json:"officer_id,omitempty"`
-	AgreementURL string    `json:"agreement_url,omitempty"`
-	DisbursedAt  time.Time `json:"disbursed_at,omitempty"`
+	OfficerID           int64     `json:"officer_id,omitempty"`
+	AgreementDocumentID int64     `json:"agreement_document_id,omitempty"`
+	DisbursedAt         time.Time `json:"disbursed_at,omitempty"`
 }
 
 func (l *Loan) CanTransitionTo(newState LoanState) bool {
@@ -99,3 +109,52 @@ func (l *Loan) Disburse(disbursement Disbursement) error {
 
 	return nil
 }
+
+// InvestorIDs returns the distinct investors of the loan in order of their first investment.
+func (l *Loan) InvestorIDs() []int64 {
+	seen := make(map[int64]bool, len(l.Investments))
+	ids := make([]int64, 0, len(l.Investments))
+	for _, inv := range l.Investments {
+		if !seen[inv.InvestorID] {
+			seen[inv.InvestorID] = true
+			ids = append(ids, inv.InvestorID)
+		}
+	}
+	return ids
+}
+
+// FundedAt returns when the investment that completed the funding was made.
+func (l *Loan) FundedAt() (time.Time, bool) {
+	if stateOrder[l.State] < stateOrder[StateInvested] || len(l.Investments) == 0 {
+		return time.Time{}, false
+	}
+	return l.Investments[len(l.Investments)-1].InvestedAt, true
+}
+
+func (l *Loan) InvestedBy(investorID int64) float64 {
+	total := 0.0
+	for _, inv := range l.Investments {
+		if inv.InvestorID == investorID {
+			total += inv.Amount
+		}
+	}
+	return total
+}
+
+func (l *Loan) ExpectedReturn(amount float64) float64 {
+	return amount * l.ROI
+}
+
+// InvolvesPartner reports whether the partner proposed the loan or made one
+// of its investments.
+func (l *Loan) InvolvesPartner(partnerID int64) bool {
+	if l.PartnerID == partnerID {
+		return true
+	}
+	for _, inv := range l.Investments {
+		if inv.PartnerID == partnerID {
+			return true
+		}
+	}
+	return false
+}
@@ -80,7 +80,7 @@ func TestLoanApprove(t *testing.T) {
 		{
 			name:        "happy path approval",
 			initialLoan: &model.Loan{State: model.StateProposed},
-			approval:    model.Approval{ApprovedAt: now, ValidatorID: 123, ProofURL: "https://proof.com"},
+			approval:    model.Approval{ApprovedAt: now, ValidatorID: 123, ProofDocumentID: 456},
 		},
 		{
 			name:          "already approved",
@@ -211,9 +211,9 @@ func TestLoan_Disburse(t *testing.T) {
 			}
 
 			disbursement := model.Disbursement{
-				OfficerID:    123,
-				AgreementURL: "https://agreement.com",
-				DisbursedAt:  time.Now(),
+				OfficerID:           123,
+				AgreementDocumentID: 456,
+				DisbursedAt:         time.Now(),
 			}
 			err := l.Disburse(disbursement)
 
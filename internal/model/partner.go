@@ -0,0 +1,44 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// PartnerScope is an operation a partner API key may perform.
+type PartnerScope string
+
+const (
+	PartnerScopeLoansCreate PartnerScope = "loans:create"
+	PartnerScopeLoansInvest PartnerScope = "loans:invest"
+	PartnerScopeWebhooks    PartnerScope = "webhooks:manage"
+)
+
+var PartnerScopes = []PartnerScope{PartnerScopeLoansCreate, PartnerScopeLoansInvest, PartnerScopeWebhooks}
+
+// Partner is an institution that proposes and funds loans through signed
+// machine-to-machine requests.
+type Partner struct {
+	ID        int64     `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// PartnerKey is an API key a partner signs requests with. The secret is only
+// shown once, when the key is issued.
+type PartnerKey struct {
+	ID        string         `json:"id,omitempty"`
+	PartnerID int64          `json:"partner_id,omitempty"`
+	Secret    string         `json:"secret,omitempty"`
+	Scopes    []PartnerScope `json:"scopes,omitempty"`
+	CreatedAt time.Time      `json:"created_at,omitempty"`
+	RevokedAt *time.Time     `json:"revoked_at,omitempty"`
+}
+
+func (k *PartnerKey) Active() bool {
+	return k.RevokedAt == nil
+}
+
+func (k *PartnerKey) HasScope(scope PartnerScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
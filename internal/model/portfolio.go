@@ -0,0 +1,107 @@
+package model
+
+import "sort"
+
+type Position struct {
+	LoanID          int64     `json:"loan_id"`
+	BorrowerID      int64     `json:"borrower_id"`
+	State           LoanState `json:"state"`
+	Principal       float64   `json:"principal"`
+	Amount          float64   `json:"amount"`
+	SharePercentage float64   `json:"share_percentage"`
+	ROI             float64   `json:"roi"`
+	ExpectedReturn  float64   `json:"expected_return"`
+	Received        float64   `json:"received"`
+	Outstanding     float64   `json:"outstanding"`
+}
+
+type PortfolioTotals struct {
+	LoanCount      int     `json:"loan_count"`
+	Invested       float64 `json:"invested"`
+	ExpectedReturn float64 `json:"expected_return"`
+	Received       float64 `json:"received"`
+	Outstanding    float64 `json:"outstanding"`
+}
+
+type Diversification struct {
+	BorrowerCount int `json:"borrower_count"`
+	// LargestPositionPercentage is the largest single loan as a share of the amount invested.
+	LargestPositionPercentage float64 `json:"largest_position_percentage"`
+	// BorrowerHHI is the Herfindahl-Hirschman index of exposure per borrower,
+	// from 1/borrower_count for an even spread up to 1 for a single borrower.
+	BorrowerHHI   float64               `json:"borrower_hhi"`
+	AmountByState map[LoanState]float64 `json:"amount_by_state"`
+}
+
+type Portfolio struct {
+	InvestorID      int64           `json:"investor_id"`
+	Positions       []Position      `json:"positions"`
+	Totals          PortfolioTotals `json:"totals"`
+	Diversification Diversification `json:"diversification"`
+}
+
+// NewPortfolio summarises the investor's positions in the given loans. Repayments
+// are not tracked yet, so nothing counts as received and the whole expected
+// payout is outstanding.
+func NewPortfolio(investorID int64, loans []*Loan) *Portfolio {
+	portfolio := &Portfolio{
+		InvestorID: investorID,
+		Positions:  make([]Position, 0, len(loans)),
+		Diversification: Diversification{
+			AmountByState: make(map[LoanState]float64),
+		},
+	}
+
+	byBorrower := make(map[int64]float64)
+	for _, loan := range loans {
+		amount := loan.InvestedBy(investorID)
+		if amount == 0 {
+			continue
+		}
+
+		position := Position{
+			LoanID:         loan.ID,
+			BorrowerID:     loan.BorrowerID,
+			State:          loan.State,
+			Principal:      loan.Principal,
+			Amount:         amount,
+			ROI:            loan.ROI,
+			ExpectedReturn: loan.ExpectedReturn(amount),
+		}
+		if loan.Principal > 0 {
+			position.SharePercentage = amount / loan.Principal * 100
+		}
+		position.Outstanding = position.Amount + position.ExpectedReturn - position.Received
+
+		portfolio.Positions = append(portfolio.Positions, position)
+		portfolio.Totals.LoanCount++
+		portfolio.Totals.Invested += position.Amount
+		portfolio.Totals.ExpectedReturn += position.ExpectedReturn
+		portfolio.Totals.Received += position.Received
+		portfolio.Totals.Outstanding += position.Outstanding
+		portfolio.Diversification.AmountByState[loan.State] += amount
+		byBorrower[loan.BorrowerID] += amount
+	}
+
+	sort.Slice(portfolio.Positions, func(i, j int) bool {
+		return portfolio.Positions[i].LoanID < portfolio.Positions[j].LoanID
+	})
+
+	invested := portfolio.Totals.Invested
+	if invested == 0 {
+		return portfolio
+	}
+
+	portfolio.Diversification.BorrowerCount = len(byBorrower)
+	for _, position := range portfolio.Positions {
+		if share := position.Amount / invested * 100; share > portfolio.Diversification.LargestPositionPercentage {
+			portfolio.Diversification.LargestPositionPercentage = share
+		}
+	}
+	for _, amount := range byBorrower {
+		share := amount / invested
+		portfolio.Diversification.BorrowerHHI += share * share
+	}
+
+	return portfolio
+}
@@ -0,0 +1,67 @@
+package model_test
+
+import (
+	"loan_system/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPortfolio(t *testing.T) {
+	loans := []*model.Loan{
+		{
+			ID: 2, BorrowerID: 20, Principal: 1000, ROI: 0.1, State: model.StateInvested,
+			Investments: []model.Investment{{InvestorID: 1, Amount: 300}, {InvestorID: 9, Amount: 600}, {InvestorID: 1, Amount: 100}},
+		},
+		{
+			ID: 1, BorrowerID: 10, Principal: 2000, ROI: 0.05, State: model.StateApproved,
+			Investments: []model.Investment{{InvestorID: 1, Amount: 400}},
+		},
+		{
+			ID: 3, BorrowerID: 20, Principal: 500, ROI: 0.2, State: model.StateApproved,
+			Investments: []model.Investment{{InvestorID: 9, Amount: 500}},
+		},
+	}
+
+	portfolio := model.NewPortfolio(1, loans)
+
+	t.Run("positions", func(t *testing.T) {
+		assert.Len(t, portfolio.Positions, 2)
+
+		first := portfolio.Positions[0]
+		assert.Equal(t, int64(1), first.LoanID)
+		assert.Equal(t, float64(400), first.Amount)
+		assert.InDelta(t, 20, first.SharePercentage, 1e-9)
+		assert.InDelta(t, 20, first.ExpectedReturn, 1e-9)
+		assert.InDelta(t, 420, first.Outstanding, 1e-9)
+
+		second := portfolio.Positions[1]
+		assert.Equal(t, int64(2), second.LoanID)
+		assert.Equal(t, float64(400), second.Amount)
+		assert.InDelta(t, 40, second.SharePercentage, 1e-9)
+		assert.Equal(t, model.StateInvested, second.State)
+	})
+
+	t.Run("totals", func(t *testing.T) {
+		assert.Equal(t, 2, portfolio.Totals.LoanCount)
+		assert.InDelta(t, 800, portfolio.Totals.Invested, 1e-9)
+		assert.InDelta(t, 60, portfolio.Totals.ExpectedReturn, 1e-9)
+		assert.InDelta(t, 0, portfolio.Totals.Received, 1e-9)
+		assert.InDelta(t, 860, portfolio.Totals.Outstanding, 1e-9)
+	})
+
+	t.Run("diversification", func(t *testing.T) {
+		assert.Equal(t, 2, portfolio.Diversification.BorrowerCount)
+		assert.InDelta(t, 50, portfolio.Diversification.LargestPositionPercentage, 1e-9)
+		assert.InDelta(t, 0.5, portfolio.Diversification.BorrowerHHI, 1e-9)
+		assert.InDelta(t, 400, portfolio.Diversification.AmountByState[model.StateApproved], 1e-9)
+		assert.InDelta(t, 400, portfolio.Diversification.AmountByState[model.StateInvested], 1e-9)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		empty := model.NewPortfolio(42, loans)
+		assert.Empty(t, empty.Positions)
+		assert.Zero(t, empty.Totals.Invested)
+		assert.Zero(t, empty.Diversification.BorrowerHHI)
+	})
+}
@@ -1,5 +1,34 @@
 package model
 
+import "time"
+
 type LoanAgreement struct {
 	LoanID int64 `json:"loan_id"`
 }
+
+// TopicLoanEvents carries a LoanEvent for every loan state change and
+// investment.
+const TopicLoanEvents = "loan_events"
+
+type LoanEventType string
+
+const (
+	LoanEventProposed LoanEventType = "loan.proposed"
+	LoanEventApproved LoanEventType = "loan.approved"
+	// LoanEventInvestmentAdded is sent for every investment, including the
+	// one that fully funds the loan, which is then followed by LoanEventInvested.
+	LoanEventInvestmentAdded LoanEventType = "loan.investment_added"
+	LoanEventInvested        LoanEventType = "loan.invested"
+	LoanEventDisbursed       LoanEventType = "loan.disbursed"
+)
+
+var LoanEventTypes = []LoanEventType{LoanEventProposed, LoanEventApproved, LoanEventInvestmentAdded, LoanEventInvested, LoanEventDisbursed}
+
+// LoanEvent records a loan reaching a new state, with the loan as it was
+// right after. IDs grow with time, so events can be ordered by them.
+type LoanEvent struct {
+	ID         int64         `json:"id"`
+	Type       LoanEventType `json:"type"`
+	Loan       *Loan         `json:"loan"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
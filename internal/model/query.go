@@ -0,0 +1,142 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultLoanQueryLimit = 20
+	MaxLoanQueryLimit     = 100
+)
+
+type LoanSortField string
+
+const (
+	LoanSortID        LoanSortField = "id"
+	LoanSortPrincipal LoanSortField = "principal"
+	LoanSortCreatedAt LoanSortField = "created_at"
+)
+
+// LoanQuery filters, orders and pages through loans. Zero values mean "no filter".
+type LoanQuery struct {
+	State        LoanState
+	BorrowerID   int64
+	InvestorID   int64
+	MinPrincipal float64
+	MaxPrincipal float64
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+
+	SortBy     LoanSortField
+	Descending bool
+	After      *LoanCursor
+	Limit      int
+}
+
+type LoanPage struct {
+	Loans      []*Loan
+	NextCursor string
+}
+
+// LoanCursor marks the last loan of a page. It carries every sortable value so
+// the next page can resume from it whatever field the query is ordered by.
+type LoanCursor struct {
+	ID        int64     `json:"id"`
+	Principal float64   `json:"principal,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+func NewLoanCursor(loan *Loan) LoanCursor {
+	return LoanCursor{ID: loan.ID, Principal: loan.Principal, CreatedAt: loan.CreatedAt}
+}
+
+func (c LoanCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeLoanCursor(s string) (*LoanCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	cursor := new(LoanCursor)
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID == 0 {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// Normalized fills in the default sort field and clamps the page size.
+func (q LoanQuery) Normalized() LoanQuery {
+	if q.SortBy == "" {
+		q.SortBy = LoanSortID
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLoanQueryLimit
+	}
+	if q.Limit > MaxLoanQueryLimit {
+		q.Limit = MaxLoanQueryLimit
+	}
+	return q
+}
+
+func (q LoanQuery) Matches(l *Loan) bool {
+	if q.State != "" && l.State != q.State {
+		return false
+	}
+	if q.BorrowerID != 0 && l.BorrowerID != q.BorrowerID {
+		return false
+	}
+	if q.InvestorID != 0 && l.InvestedBy(q.InvestorID) == 0 {
+		return false
+	}
+	if q.MinPrincipal != 0 && l.Principal < q.MinPrincipal {
+		return false
+	}
+	if q.MaxPrincipal != 0 && l.Principal > q.MaxPrincipal {
+		return false
+	}
+	if !q.CreatedFrom.IsZero() && l.CreatedAt.Before(q.CreatedFrom) {
+		return false
+	}
+	if !q.CreatedTo.IsZero() && l.CreatedAt.After(q.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// Less orders loans by the query's sort field, breaking ties by ID.
+func (q LoanQuery) Less(a, b LoanCursor) bool {
+	cmp := 0
+	switch q.SortBy {
+	case LoanSortPrincipal:
+		cmp = compare(a.Principal, b.Principal)
+	case LoanSortCreatedAt:
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = compare(a.ID, b.ID)
+	}
+
+	if q.Descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compare[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
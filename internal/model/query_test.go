@@ -0,0 +1,33 @@
+package model_test
+
+import (
+	"loan_system/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoanCursor(t *testing.T) {
+	loan := &model.Loan{ID: 42, Principal: 1500, CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	decoded, err := model.DecodeLoanCursor(model.NewLoanCursor(loan).Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), decoded.ID)
+	assert.Equal(t, float64(1500), decoded.Principal)
+	assert.True(t, loan.CreatedAt.Equal(decoded.CreatedAt))
+
+	for _, invalid := range []string{"%%%", "bm90LWpzb24", "e30"} {
+		_, err := model.DecodeLoanCursor(invalid)
+		assert.ErrorContains(t, err, "invalid cursor")
+	}
+}
+
+func TestLoanQuery_Normalized(t *testing.T) {
+	q := model.LoanQuery{}.Normalized()
+	assert.Equal(t, model.LoanSortID, q.SortBy)
+	assert.Equal(t, model.DefaultLoanQueryLimit, q.Limit)
+
+	q = model.LoanQuery{Limit: 1000}.Normalized()
+	assert.Equal(t, model.MaxLoanQueryLimit, q.Limit)
+}
@@ -0,0 +1,5 @@
+package request
+
+type GetBorrowerLoansRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
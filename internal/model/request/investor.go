@@ -0,0 +1,5 @@
+package request
+
+type GetPortfolioRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
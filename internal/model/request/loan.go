@@ -4,32 +4,109 @@ import "time"
 
 type CreateLoanRequest struct {
 	Principal     float64 `json:"principal" validate:"required,gt=0"`
-	BorrowerID    int64   `json:"borrower_id" validate:"required"`
+	BorrowerID    int64   `json:"borrower_id"`
 	Rate          float64 `json:"rate" validate:"required,gt=0"`
 	ROI           float64 `json:"roi" validate:"required,gt=0"`
 	AgreementLink string  `json:"agreement_link" validate:"required"`
+	TenorMonths   int     `json:"tenor_months" validate:"omitempty,min=1,max=120"`
 }
 
 type ApproveLoanRequest struct {
-	ID          int64     `param:"id" validate:"required"`
-	ValidatorID int64     `json:"validator_id" validate:"required"`
-	ProofURL    string    `json:"proof_url" validate:"required"`
-	ApprovedAt  time.Time `json:"approved_at"`
+	ID              int64     `param:"id" validate:"required"`
+	ProofDocumentID int64     `json:"proof_document_id" validate:"required"`
+	ApprovedAt      time.Time `json:"approved_at"`
+}
+
+// ApproveLoansRequest approves every loan, each with the proof uploaded to
+// it. Atomic approves none unless every one can be.
+type ApproveLoansRequest struct {
+	Loans      []LoanProof `json:"loans" validate:"required,min=1,max=100,unique=LoanID,dive"`
+	ApprovedAt time.Time   `json:"approved_at"`
+	Atomic     bool        `json:"atomic"`
+}
+
+type LoanProof struct {
+	LoanID          int64 `json:"loan_id" validate:"required"`
+	ProofDocumentID int64 `json:"proof_document_id" validate:"required"`
 }
 
 type InvestLoanRequest struct {
 	ID         int64   `param:"id" validate:"required"`
-	InvestorID int64   `json:"investor_id" validate:"required"`
+	InvestorID int64   `json:"investor_id"`
 	Amount     float64 `json:"amount" validate:"required,gt=0"`
 }
 
 type DisburseLoanRequest struct {
-	ID           int64     `param:"id" validate:"required"`
-	OfficerID    int64     `json:"officer_id" validate:"required"`
-	AgreementURL string    `json:"agreement_url" validate:"required"`
-	DisbursedAt  time.Time `json:"disbursed_at"`
+	ID                  int64     `param:"id" validate:"required"`
+	AgreementDocumentID int64     `json:"agreement_document_id" validate:"required"`
+	DisbursedAt         time.Time `json:"disbursed_at"`
+}
+
+// DisburseLoansRequest disburses every loan, each against the signed
+// agreement uploaded to it. Atomic disburses none unless every one can be.
+type DisburseLoansRequest struct {
+	Loans       []LoanAgreement `json:"loans" validate:"required,min=1,max=100,unique=LoanID,dive"`
+	DisbursedAt time.Time       `json:"disbursed_at"`
+	Atomic      bool            `json:"atomic"`
+}
+
+type LoanAgreement struct {
+	LoanID              int64 `json:"loan_id" validate:"required"`
+	AgreementDocumentID int64 `json:"agreement_document_id" validate:"required"`
+}
+
+type GetLoansRequest struct {
+	State        string    `query:"state" validate:"omitempty,oneof=PROPOSED APPROVED INVESTED DISBURSED"`
+	BorrowerID   int64     `query:"borrower_id"`
+	InvestorID   int64     `query:"investor_id"`
+	MinPrincipal float64   `query:"min_principal" validate:"gte=0"`
+	MaxPrincipal float64   `query:"max_principal" validate:"gte=0"`
+	CreatedFrom  time.Time `query:"created_from"`
+	CreatedTo    time.Time `query:"created_to"`
+	Sort         string    `query:"sort" validate:"omitempty,oneof=id -id principal -principal created_at -created_at"`
+	Cursor       string    `query:"cursor"`
+	Limit        int       `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// ExportLoansRequest filters and sorts loans like GetLoansRequest but
+// exports every match instead of a page.
+type ExportLoansRequest struct {
+	Format       string    `query:"format" validate:"omitempty,oneof=csv ndjson parquet"`
+	State        string    `query:"state" validate:"omitempty,oneof=PROPOSED APPROVED INVESTED DISBURSED"`
+	BorrowerID   int64     `query:"borrower_id"`
+	InvestorID   int64     `query:"investor_id"`
+	MinPrincipal float64   `query:"min_principal" validate:"gte=0"`
+	MaxPrincipal float64   `query:"max_principal" validate:"gte=0"`
+	CreatedFrom  time.Time `query:"created_from"`
+	CreatedTo    time.Time `query:"created_to"`
+	Sort         string    `query:"sort" validate:"omitempty,oneof=id -id principal -principal created_at -created_at"`
 }
 
 type GetLoanRequest struct {
 	ID int64 `param:"id" validate:"required"`
 }
+
+type GetAgreementRequest struct {
+	ID         int64  `param:"id" validate:"required"`
+	InvestorID int64  `param:"investorID" validate:"required"`
+	Format     string `query:"format" validate:"omitempty,oneof=html pdf"`
+}
+
+type UploadDocumentRequest struct {
+	ID   int64  `param:"id" validate:"required"`
+	Kind string `form:"kind" validate:"required,oneof=approval_proof signed_agreement"`
+}
+
+type StreamLoanRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
+
+// ImportLoansRequest comes with a CSV file whose header names the
+// CreateLoanRequest fields of each row.
+type ImportLoansRequest struct {
+	Mode string `form:"mode" validate:"omitempty,oneof=all_or_nothing best_effort"`
+}
+
+type GetImportRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
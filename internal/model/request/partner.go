@@ -0,0 +1,19 @@
+package request
+
+type CreatePartnerRequest struct {
+	Name string `json:"name" validate:"required,max=200"`
+}
+
+type IssuePartnerKeyRequest struct {
+	ID     int64    `param:"id" validate:"required"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=loans:create loans:invest webhooks:manage"`
+}
+
+type GetPartnerKeysRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
+
+type RevokePartnerKeyRequest struct {
+	ID    int64  `param:"id" validate:"required"`
+	KeyID string `param:"keyID" validate:"required"`
+}
@@ -0,0 +1,9 @@
+package request
+
+import "time"
+
+type GetLoanStatsRequest struct {
+	From    time.Time `query:"from"`
+	To      time.Time `query:"to"`
+	GroupBy string    `query:"group_by" validate:"omitempty,oneof=day week month"`
+}
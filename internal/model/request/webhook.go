@@ -0,0 +1,36 @@
+package request
+
+// PartnerID on these requests lets an admin act for a partner. Partners
+// always act for themselves.
+
+type CreateWebhookRequest struct {
+	PartnerID  int64    `json:"partner_id"`
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=loan.proposed loan.approved loan.investment_added loan.invested loan.disbursed"`
+}
+
+type GetWebhooksRequest struct {
+	PartnerID int64 `query:"partner_id"`
+}
+
+type GetWebhookRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
+
+type DeleteWebhookRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
+
+type GetWebhookDeliveriesRequest struct {
+	PartnerID      int64  `query:"partner_id"`
+	SubscriptionID int64  `query:"subscription_id"`
+	Status         string `query:"status" validate:"omitempty,oneof=pending succeeded failed"`
+}
+
+type GetWebhookDeliveryRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
+
+type RedeliverWebhookRequest struct {
+	ID int64 `param:"id" validate:"required"`
+}
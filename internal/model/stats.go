@@ -0,0 +1,204 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+type StatsGrouping string
+
+const (
+	GroupByDay   StatsGrouping = "day"
+	GroupByWeek  StatsGrouping = "week"
+	GroupByMonth StatsGrouping = "month"
+)
+
+// Start returns the beginning of the UTC period containing t. Weeks start on Monday.
+func (g StatsGrouping) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch g {
+	case GroupByWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case GroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+type StateStats struct {
+	Count     int     `json:"count"`
+	Principal float64 `json:"principal"`
+}
+
+// StageDurations holds average hours spent between state transitions, over
+// the loans that have made each transition.
+type StageDurations struct {
+	ProposedToApproved  float64 `json:"proposed_to_approved_hours"`
+	ApprovedToInvested  float64 `json:"approved_to_invested_hours"`
+	InvestedToDisbursed float64 `json:"invested_to_disbursed_hours"`
+}
+
+// FundingProgress covers approved loans that are still open for investment.
+type FundingProgress struct {
+	OpenLoans  int     `json:"open_loans"`
+	Principal  float64 `json:"principal"`
+	Funded     float64 `json:"funded"`
+	Percentage float64 `json:"percentage"`
+}
+
+type LoanStatsSummary struct {
+	LoanCount        int                      `json:"loan_count"`
+	ByState          map[LoanState]StateStats `json:"by_state"`
+	TotalFunded      float64                  `json:"total_funded"`
+	AverageDurations StageDurations           `json:"average_durations"`
+	FundingProgress  FundingProgress          `json:"funding_progress"`
+}
+
+type LoanStatsGroup struct {
+	Start time.Time `json:"start"`
+	LoanStatsSummary
+}
+
+type LoanStats struct {
+	From    time.Time        `json:"from,omitzero"`
+	To      time.Time        `json:"to,omitzero"`
+	GroupBy StatsGrouping    `json:"group_by,omitempty"`
+	Total   LoanStatsSummary `json:"total"`
+	Groups  []LoanStatsGroup `json:"groups,omitempty"`
+}
+
+// NewLoanStats aggregates the given loans, and when groupBy is set also per
+// period of their creation time. Periods without loans are left out.
+func NewLoanStats(loans []*Loan, from, to time.Time, groupBy StatsGrouping) *LoanStats {
+	builder := NewLoanStatsBuilder(from, to, groupBy)
+	for _, loan := range loans {
+		builder.Add(loan)
+	}
+	return builder.Stats()
+}
+
+// LoanStatsBuilder aggregates loans one at a time, so the book can be
+// streamed through it rather than loaded at once.
+type LoanStatsBuilder struct {
+	from, to time.Time
+	groupBy  StatsGrouping
+	total    *statsAccumulator
+	groups   map[time.Time]*statsAccumulator
+}
+
+func NewLoanStatsBuilder(from, to time.Time, groupBy StatsGrouping) *LoanStatsBuilder {
+	return &LoanStatsBuilder{
+		from:    from,
+		to:      to,
+		groupBy: groupBy,
+		total:   newStatsAccumulator(),
+		groups:  make(map[time.Time]*statsAccumulator),
+	}
+}
+
+func (b *LoanStatsBuilder) Add(loan *Loan) {
+	b.total.add(loan)
+	if b.groupBy == "" {
+		return
+	}
+
+	start := b.groupBy.Start(loan.CreatedAt)
+	group, ok := b.groups[start]
+	if !ok {
+		group = newStatsAccumulator()
+		b.groups[start] = group
+	}
+	group.add(loan)
+}
+
+// Stats returns the statistics of the loans added so far.
+func (b *LoanStatsBuilder) Stats() *LoanStats {
+	stats := &LoanStats{From: b.from, To: b.to, GroupBy: b.groupBy, Total: b.total.summary()}
+	for start, group := range b.groups {
+		stats.Groups = append(stats.Groups, LoanStatsGroup{Start: start, LoanStatsSummary: group.summary()})
+	}
+	sort.Slice(stats.Groups, func(i, j int) bool { return stats.Groups[i].Start.Before(stats.Groups[j].Start) })
+
+	return stats
+}
+
+type duration struct {
+	total time.Duration
+	count int
+}
+
+func (d *duration) add(from, to time.Time) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return
+	}
+	d.total += to.Sub(from)
+	d.count++
+}
+
+func (d duration) averageHours() float64 {
+	if d.count == 0 {
+		return 0
+	}
+	return d.total.Hours() / float64(d.count)
+}
+
+type statsAccumulator struct {
+	totals              LoanStatsSummary
+	proposedToApproved  duration
+	approvedToInvested  duration
+	investedToDisbursed duration
+}
+
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{totals: LoanStatsSummary{ByState: make(map[LoanState]StateStats)}}
+}
+
+func (a *statsAccumulator) add(loan *Loan) {
+	s := &a.totals
+	s.LoanCount++
+
+	state := s.ByState[loan.State]
+	state.Count++
+	state.Principal += loan.Principal
+	s.ByState[loan.State] = state
+
+	funded := 0.0
+	for _, inv := range loan.Investments {
+		funded += inv.Amount
+	}
+	s.TotalFunded += funded
+
+	if loan.State == StateApproved {
+		s.FundingProgress.OpenLoans++
+		s.FundingProgress.Principal += loan.Principal
+		s.FundingProgress.Funded += funded
+	}
+
+	var approvedAt time.Time
+	if loan.Approval != nil {
+		approvedAt = loan.Approval.ApprovedAt
+		a.proposedToApproved.add(loan.CreatedAt, approvedAt)
+	}
+	fundedAt, ok := loan.FundedAt()
+	if ok {
+		a.approvedToInvested.add(approvedAt, fundedAt)
+	}
+	if ok && loan.Disbursement != nil {
+		a.investedToDisbursed.add(fundedAt, loan.Disbursement.DisbursedAt)
+	}
+}
+
+func (a *statsAccumulator) summary() LoanStatsSummary {
+	s := a.totals
+	s.AverageDurations = StageDurations{
+		ProposedToApproved:  a.proposedToApproved.averageHours(),
+		ApprovedToInvested:  a.approvedToInvested.averageHours(),
+		InvestedToDisbursed: a.investedToDisbursed.averageHours(),
+	}
+	if s.FundingProgress.Principal > 0 {
+		s.FundingProgress.Percentage = s.FundingProgress.Funded / s.FundingProgress.Principal * 100
+	}
+	return s
+}
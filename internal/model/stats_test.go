@@ -0,0 +1,59 @@
+package model_test
+
+import (
+	"loan_system/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsGrouping_Start(t *testing.T) {
+	// a Wednesday
+	at := time.Date(2024, 5, 15, 13, 45, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC), model.GroupByDay.Start(at))
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), model.GroupByWeek.Start(at))
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), model.GroupByMonth.Start(at))
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), model.GroupByWeek.Start(time.Date(2024, 5, 19, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestNewLoanStats(t *testing.T) {
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	loans := []*model.Loan{
+		{ID: 1, Principal: 1000, State: model.StateProposed, CreatedAt: may},
+		{ID: 2, Principal: 2000, State: model.StateApproved, CreatedAt: may.Add(24 * time.Hour),
+			Approval:    &model.Approval{ApprovedAt: may.Add(48 * time.Hour)},
+			Investments: []model.Investment{{Amount: 500}}},
+		{ID: 3, Principal: 1000, State: model.StateDisbursed, CreatedAt: june,
+			Approval:     &model.Approval{ApprovedAt: june.Add(4 * time.Hour)},
+			Investments:  []model.Investment{{Amount: 400, InvestedAt: june.Add(5 * time.Hour)}, {Amount: 600, InvestedAt: june.Add(10 * time.Hour)}},
+			Disbursement: &model.Disbursement{DisbursedAt: june.Add(16 * time.Hour)}},
+	}
+
+	t.Run("total", func(t *testing.T) {
+		stats := model.NewLoanStats(loans, time.Time{}, time.Time{}, "")
+		assert.Empty(t, stats.Groups)
+
+		total := stats.Total
+		assert.Equal(t, 3, total.LoanCount)
+		assert.Equal(t, model.StateStats{Count: 1, Principal: 2000}, total.ByState[model.StateApproved])
+		assert.Equal(t, model.StateStats{Count: 1, Principal: 1000}, total.ByState[model.StateDisbursed])
+		assert.Equal(t, 1500.0, total.TotalFunded)
+		assert.InDelta(t, 14, total.AverageDurations.ProposedToApproved, 1e-9)
+		assert.InDelta(t, 6, total.AverageDurations.ApprovedToInvested, 1e-9)
+		assert.InDelta(t, 6, total.AverageDurations.InvestedToDisbursed, 1e-9)
+		assert.Equal(t, model.FundingProgress{OpenLoans: 1, Principal: 2000, Funded: 500, Percentage: 25}, total.FundingProgress)
+	})
+
+	t.Run("grouped by month", func(t *testing.T) {
+		stats := model.NewLoanStats(loans, may, june.AddDate(0, 1, 0), model.GroupByMonth)
+		assert.Len(t, stats.Groups, 2)
+		assert.Equal(t, may, stats.Groups[0].Start)
+		assert.Equal(t, 2, stats.Groups[0].LoanCount)
+		assert.Equal(t, june, stats.Groups[1].Start)
+		assert.Equal(t, 1, stats.Groups[1].LoanCount)
+		assert.Zero(t, stats.Groups[1].FundingProgress.OpenLoans)
+	})
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookSubscription asks for the partner's loan events of EventTypes to be
+// POSTed to URL, signed with Secret. The secret is only shown once, when the
+// subscription is created.
+type WebhookSubscription struct {
+	ID         int64           `json:"id,omitempty"`
+	PartnerID  int64           `json:"partner_id,omitempty"`
+	URL        string          `json:"url,omitempty"`
+	EventTypes []LoanEventType `json:"event_types,omitempty"`
+	Secret     string          `json:"secret,omitempty"`
+	CreatedAt  time.Time       `json:"created_at,omitempty"`
+}
+
+// Wants reports whether event should be sent to the subscription: it must be
+// of a subscribed type and about a loan the partner is involved in.
+func (s *WebhookSubscription) Wants(event LoanEvent) bool {
+	return slices.Contains(s.EventTypes, event.Type) && event.Loan != nil && event.Loan.InvolvesPartner(s.PartnerID)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed deliveries ran out of attempts. They form the
+	// dead-letter list and can be redelivered by hand.
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription. Attempts logs every
+// try; AttemptCount only counts those since the delivery was last queued.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id,omitempty"`
+	SubscriptionID int64                 `json:"subscription_id,omitempty"`
+	PartnerID      int64                 `json:"partner_id,omitempty"`
+	EventID        int64                 `json:"event_id,omitempty"`
+	EventType      LoanEventType         `json:"event_type,omitempty"`
+	Payload        json.RawMessage       `json:"payload,omitempty"`
+	Status         WebhookDeliveryStatus `json:"status,omitempty"`
+	AttemptCount   int                   `json:"attempt_count"`
+	Attempts       []WebhookAttempt      `json:"attempts,omitempty"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at,omitempty"`
+}
+
+// WebhookAttempt is one try at a delivery. StatusCode is 0 when no response
+// was received.
+type WebhookAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// WebhookDeliveryQuery filters deliveries. Zero fields match everything.
+type WebhookDeliveryQuery struct {
+	PartnerID      int64
+	SubscriptionID int64
+	Status         WebhookDeliveryStatus
+}
@@ -0,0 +1,54 @@
+package model_test
+
+import (
+	"testing"
+
+	"loan_system/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscription_Wants(t *testing.T) {
+	subscription := &model.WebhookSubscription{
+		PartnerID:  9,
+		EventTypes: []model.LoanEventType{model.LoanEventApproved, model.LoanEventDisbursed},
+	}
+
+	tests := []struct {
+		name  string
+		event model.LoanEvent
+		want  bool
+	}{
+		{
+			name:  "partner proposed the loan",
+			event: model.LoanEvent{Type: model.LoanEventApproved, Loan: &model.Loan{PartnerID: 9}},
+			want:  true,
+		},
+		{
+			name:  "partner invested in the loan",
+			event: model.LoanEvent{Type: model.LoanEventDisbursed, Loan: &model.Loan{Investments: []model.Investment{{InvestorID: 1}, {InvestorID: 2, PartnerID: 9}}}},
+			want:  true,
+		},
+		{
+			name:  "another partner's loan",
+			event: model.LoanEvent{Type: model.LoanEventApproved, Loan: &model.Loan{PartnerID: 8}},
+			want:  false,
+		},
+		{
+			name:  "unsubscribed type",
+			event: model.LoanEvent{Type: model.LoanEventProposed, Loan: &model.Loan{PartnerID: 9}},
+			want:  false,
+		},
+		{
+			name:  "no loan",
+			event: model.LoanEvent{Type: model.LoanEventApproved},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, subscription.Wants(tt.event))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package agreement
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"loan_system/internal/model"
+
+	"github.com/go-pdf/fpdf"
+)
+
+//go:embed templates/agreement.html
+var templates embed.FS
+
+var htmlTemplate = template.Must(template.ParseFS(templates, "templates/agreement.html"))
+
+type Letter struct {
+	LoanID         int64
+	BorrowerID     int64
+	InvestorID     int64
+	Principal      float64
+	Amount         float64
+	ROI            float64
+	ExpectedReturn float64
+	GeneratedAt    time.Time
+}
+
+type Term struct {
+	Label string
+	Value string
+}
+
+func NewLetter(loan *model.Loan, investorID int64, generatedAt time.Time) Letter {
+	amount := loan.InvestedBy(investorID)
+	return Letter{
+		LoanID:         loan.ID,
+		BorrowerID:     loan.BorrowerID,
+		InvestorID:     investorID,
+		Principal:      loan.Principal,
+		Amount:         amount,
+		ROI:            loan.ROI,
+		ExpectedReturn: loan.ExpectedReturn(amount),
+		GeneratedAt:    generatedAt,
+	}
+}
+
+// Terms lists the figures printed in the letter, shared by every output format.
+func (l Letter) Terms() []Term {
+	share := 0.0
+	if l.Principal > 0 {
+		share = l.Amount / l.Principal * 100
+	}
+
+	return []Term{
+		{Label: "Loan ID", Value: fmt.Sprint(l.LoanID)},
+		{Label: "Borrower ID", Value: fmt.Sprint(l.BorrowerID)},
+		{Label: "Investor ID", Value: fmt.Sprint(l.InvestorID)},
+		{Label: "Loan principal", Value: fmt.Sprintf("%.2f", l.Principal)},
+		{Label: "Invested amount", Value: fmt.Sprintf("%.2f", l.Amount)},
+		{Label: "Share of principal", Value: fmt.Sprintf("%.2f%%", share)},
+		{Label: "Return on investment", Value: fmt.Sprintf("%.2f%%", l.ROI*100)},
+		{Label: "Expected return", Value: fmt.Sprintf("%.2f", l.ExpectedReturn)},
+		{Label: "Expected payout", Value: fmt.Sprintf("%.2f", l.Amount+l.ExpectedReturn)},
+	}
+}
+
+func Render(letter Letter, format model.AgreementFormat) ([]byte, error) {
+	switch format {
+	case model.AgreementFormatHTML:
+		return renderHTML(letter)
+	case model.AgreementFormatPDF:
+		return renderPDF(letter)
+	default:
+		return nil, errors.New("unsupported agreement format")
+	}
+}
+
+func ContentType(format model.AgreementFormat) string {
+	if format == model.AgreementFormatPDF {
+		return "application/pdf"
+	}
+	return "text/html; charset=utf-8"
+}
+
+func renderHTML(letter Letter) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, letter); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func renderPDF(letter Letter) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	// pin the metadata so the same letter always renders to the same bytes and content hash
+	pdf.SetCreationDate(letter.GeneratedAt)
+	pdf.SetModificationDate(letter.GeneratedAt)
+	pdf.SetCatalogSort(true)
+	pdf.SetTitle(fmt.Sprintf("Loan Agreement #%d - Investor #%d", letter.LoanID, letter.InvestorID), true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Cell(0, 10, "Loan Investment Agreement")
+	pdf.Ln(14)
+
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.MultiCell(0, 6, fmt.Sprintf(
+		"This agreement confirms the participation of investor #%d in loan #%d granted to borrower #%d.",
+		letter.InvestorID, letter.LoanID, letter.BorrowerID,
+	), "", "L", false)
+	pdf.Ln(4)
+
+	for _, term := range letter.Terms() {
+		pdf.SetTextColor(102, 102, 102)
+		pdf.CellFormat(60, 8, term.Label, "", 0, "L", false, 0, "")
+		pdf.SetTextColor(34, 34, 34)
+		pdf.CellFormat(0, 8, term.Value, "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(4)
+	pdf.Cell(0, 6, "Generated at "+letter.GeneratedAt.Format("2006-01-02 15:04:05 MST")+".")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
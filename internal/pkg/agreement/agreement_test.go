@@ -0,0 +1,55 @@
+package agreement_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/agreement"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	loan := &model.Loan{
+		ID:         1,
+		BorrowerID: 2,
+		Principal:  1000,
+		ROI:        0.1,
+		Investments: []model.Investment{
+			{InvestorID: 3, Amount: 400},
+			{InvestorID: 4, Amount: 500},
+			{InvestorID: 3, Amount: 100},
+		},
+	}
+	letter := agreement.NewLetter(loan, 3, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	t.Run("NewLetter aggregates investor amounts", func(t *testing.T) {
+		assert.Equal(t, float64(500), letter.Amount)
+		assert.Equal(t, float64(50), letter.ExpectedReturn)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		html, err := agreement.Render(letter, model.AgreementFormatHTML)
+		assert.NoError(t, err)
+		assert.Contains(t, string(html), "investor #3 in loan #1")
+		assert.Contains(t, string(html), "50.00%")
+		assert.Contains(t, string(html), "550.00")
+	})
+
+	t.Run("pdf is deterministic", func(t *testing.T) {
+		first, err := agreement.Render(letter, model.AgreementFormatPDF)
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(first, []byte("%PDF-")))
+
+		second, err := agreement.Render(letter, model.AgreementFormatPDF)
+		assert.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := agreement.Render(letter, "docx")
+		assert.ErrorContains(t, err, "unsupported agreement format")
+	})
+}
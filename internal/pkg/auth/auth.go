@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan_system/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Role string
+
+const (
+	RoleBorrower       Role = "borrower"
+	RoleFieldValidator Role = "field_validator"
+	RoleInvestor       Role = "investor"
+	RoleFieldOfficer   Role = "field_officer"
+	RoleAdmin          Role = "admin"
+	// RolePartner is given to signed partner requests. Bearer tokens can't
+	// carry it.
+	RolePartner Role = "partner"
+)
+
+// Roles lists the roles bearer tokens may be issued for.
+var Roles = []Role{RoleBorrower, RoleFieldValidator, RoleInvestor, RoleFieldOfficer, RoleAdmin}
+
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+// Principal is the authenticated caller: a user or partner ID, the role it
+// acts in and, for partners, the scopes of the key it signed with.
+type Principal struct {
+	ID     int64
+	Role   Role
+	Scopes []model.PartnerScope
+}
+
+type claims struct {
+	Role Role `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Authenticator issues and verifies HS256 bearer tokens whose subject is the
+// user ID and whose "role" claim is one of Roles.
+type Authenticator struct {
+	key []byte
+	now func() time.Time
+}
+
+func NewAuthenticator(key string) *Authenticator {
+	return &Authenticator{key: []byte(key), now: time.Now}
+}
+
+// streamAudience restricts a token to opening event streams.
+const streamAudience = "loan-stream"
+
+func (a *Authenticator) Issue(p Principal, ttl time.Duration) (string, error) {
+	return a.issue(p, ttl, nil)
+}
+
+// IssueStreamToken issues a token for browsers opening event streams, as
+// EventSource can't send an Authorization header. It is only accepted in the
+// access_token query parameter of stream routes and never as a bearer token.
+// Keep ttl short: URLs end up in browser history and proxy logs.
+func (a *Authenticator) IssueStreamToken(p Principal, ttl time.Duration) (string, error) {
+	return a.issue(p, ttl, jwt.ClaimStrings{streamAudience})
+}
+
+func (a *Authenticator) issue(p Principal, ttl time.Duration, audience jwt.ClaimStrings) (string, error) {
+	if !p.Role.Valid() {
+		return "", fmt.Errorf("unknown role %q", p.Role)
+	}
+	if p.ID <= 0 {
+		return "", errors.New("principal id must be positive")
+	}
+
+	now := a.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Role: p.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(p.ID, 10),
+			Audience:  audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+	return token.SignedString(a.key)
+}
+
+func (a *Authenticator) Parse(token string) (Principal, error) {
+	return a.parse(token, "")
+}
+
+// ParseStreamToken parses a token issued by IssueStreamToken.
+func (a *Authenticator) ParseStreamToken(token string) (Principal, error) {
+	return a.parse(token, streamAudience)
+}
+
+// parse accepts tokens for audience only, or tokens without an audience when
+// it is empty.
+func (a *Authenticator) parse(token, audience string) (Principal, error) {
+	options := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithTimeFunc(a.now)}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (interface{}, error) {
+		return a.key, nil
+	}, options...)
+	if err != nil {
+		return Principal{}, err
+	}
+	if audience == "" && len(c.Audience) > 0 {
+		return Principal{}, fmt.Errorf("token is restricted to %s", strings.Join(c.Audience, ", "))
+	}
+
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return Principal{}, fmt.Errorf("invalid subject %q", c.Subject)
+	}
+	if !c.Role.Valid() {
+		return Principal{}, fmt.Errorf("unknown role %q", c.Role)
+	}
+
+	return Principal{ID: id, Role: c.Role}, nil
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal authenticated for the request, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+var (
+	// ErrOnlySelf is returned by OnBehalfOf when a caller names someone other
+	// than themselves.
+	ErrOnlySelf = errors.New("may only act for themselves")
+	// ErrSubjectRequired is returned by OnBehalfOf when a caller acting for
+	// someone else does not say whom.
+	ErrSubjectRequired = errors.New("is required")
+)
+
+// OnBehalfOf resolves the borrower or investor a request acts for. Callers
+// with role act for themselves and may only repeat their own ID; any other
+// caller let through, such as an admin or partner, acts for the user whose ID
+// is given in field. Its errors read like "a borrower may only act for
+// themselves" and "borrower_id is required".
+func OnBehalfOf(principal Principal, role Role, id int64, field string) (int64, error) {
+	if principal.Role == role {
+		if id != 0 && id != principal.ID {
+			return 0, fmt.Errorf("a %s %w", role, ErrOnlySelf)
+		}
+		return principal.ID, nil
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("%s %w", field, ErrSubjectRequired)
+	}
+	return id, nil
+}
@@ -0,0 +1,109 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"loan_system/internal/pkg/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator(t *testing.T) {
+	a := auth.NewAuthenticator("secret")
+
+	t.Run("round trip", func(t *testing.T) {
+		token, err := a.Issue(auth.Principal{ID: 42, Role: auth.RoleInvestor}, time.Hour)
+		assert.NoError(t, err)
+
+		principal, err := a.Parse(token)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Principal{ID: 42, Role: auth.RoleInvestor}, principal)
+	})
+
+	t.Run("stream tokens are kept apart from bearer tokens", func(t *testing.T) {
+		token, err := a.IssueStreamToken(auth.Principal{ID: 42, Role: auth.RoleInvestor}, time.Minute)
+		assert.NoError(t, err)
+
+		principal, err := a.ParseStreamToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.Principal{ID: 42, Role: auth.RoleInvestor}, principal)
+		_, err = a.Parse(token)
+		assert.ErrorContains(t, err, "token is restricted to loan-stream")
+
+		bearer, err := a.Issue(auth.Principal{ID: 42, Role: auth.RoleInvestor}, time.Hour)
+		assert.NoError(t, err)
+		_, err = a.ParseStreamToken(bearer)
+		assert.ErrorIs(t, err, jwt.ErrTokenRequiredClaimMissing)
+	})
+
+	t.Run("rejects unknown roles and ids", func(t *testing.T) {
+		_, err := a.Issue(auth.Principal{ID: 42, Role: "root"}, time.Hour)
+		assert.ErrorContains(t, err, "unknown role")
+
+		_, err = a.Issue(auth.Principal{Role: auth.RoleAdmin}, time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects expired tokens", func(t *testing.T) {
+		token, err := a.Issue(auth.Principal{ID: 42, Role: auth.RoleAdmin}, -time.Minute)
+		assert.NoError(t, err)
+
+		_, err = a.Parse(token)
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("rejects tokens signed with another key", func(t *testing.T) {
+		token, err := auth.NewAuthenticator("other").Issue(auth.Principal{ID: 42, Role: auth.RoleAdmin}, time.Hour)
+		assert.NoError(t, err)
+
+		_, err = a.Parse(token)
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("rejects unsigned tokens", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+			"sub": "42", "role": "admin", "exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		assert.NoError(t, err)
+
+		_, err = a.Parse(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects unknown role claims", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "42", "role": "root", "exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("secret"))
+		assert.NoError(t, err)
+
+		_, err = a.Parse(token)
+		assert.ErrorContains(t, err, "unknown role")
+	})
+}
+
+func TestOnBehalfOf(t *testing.T) {
+	borrower := auth.Principal{ID: 7, Role: auth.RoleBorrower}
+	admin := auth.Principal{ID: 1, Role: auth.RoleAdmin}
+
+	id, err := auth.OnBehalfOf(borrower, auth.RoleBorrower, 0, "borrower_id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id, "callers act for themselves")
+
+	id, err = auth.OnBehalfOf(borrower, auth.RoleBorrower, 7, "borrower_id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+
+	_, err = auth.OnBehalfOf(borrower, auth.RoleBorrower, 8, "borrower_id")
+	assert.ErrorIs(t, err, auth.ErrOnlySelf)
+	assert.EqualError(t, err, "a borrower may only act for themselves")
+
+	id, err = auth.OnBehalfOf(admin, auth.RoleBorrower, 8, "borrower_id")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), id, "admins act for the given user")
+
+	_, err = auth.OnBehalfOf(admin, auth.RoleBorrower, 0, "borrower_id")
+	assert.ErrorIs(t, err, auth.ErrSubjectRequired)
+	assert.EqualError(t, err, "borrower_id is required")
+}
@@ -0,0 +1,105 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"loan_system/internal/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a := auth.NewAuthenticator("secret")
+	interceptor := auth.UnaryServerInterceptor(a, map[string][]auth.Role{
+		"/loan.v1.LoanService/ApproveLoan": {auth.RoleFieldValidator},
+	})
+	handler := func(ctx context.Context, req any) (any, error) {
+		principal, _ := auth.FromContext(ctx)
+		return principal.ID, nil
+	}
+
+	call := func(method, authorization string) (any, error) {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+	bearer := func(principal auth.Principal) string {
+		token, err := a.Issue(principal, time.Hour)
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		want          codes.Code
+	}{
+		{name: "missing token", method: "/loan.v1.LoanService/ApproveLoan", want: codes.Unauthenticated},
+		{name: "invalid token", method: "/loan.v1.LoanService/ApproveLoan", authorization: "Bearer not-a-token", want: codes.Unauthenticated},
+		{name: "allowed role", method: "/loan.v1.LoanService/ApproveLoan", authorization: bearer(auth.Principal{ID: 7, Role: auth.RoleFieldValidator}), want: codes.OK},
+		{name: "admin", method: "/loan.v1.LoanService/ApproveLoan", authorization: bearer(auth.Principal{ID: 1, Role: auth.RoleAdmin}), want: codes.OK},
+		{name: "other role", method: "/loan.v1.LoanService/ApproveLoan", authorization: bearer(auth.Principal{ID: 7, Role: auth.RoleInvestor}), want: codes.PermissionDenied},
+		{name: "unlisted method", method: "/loan.v1.LoanService/GetLoan", authorization: bearer(auth.Principal{ID: 7, Role: auth.RoleInvestor}), want: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := call(tt.method, tt.authorization)
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+
+	t.Run("acting id comes from the token", func(t *testing.T) {
+		id, err := call("/loan.v1.LoanService/ApproveLoan", bearer(auth.Principal{ID: 7, Role: auth.RoleFieldValidator}))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+	})
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	a := auth.NewAuthenticator("secret")
+	interceptor := auth.StreamServerInterceptor(a, map[string][]auth.Role{
+		"/loan.v1.LoanService/WatchLoans": {auth.RoleInvestor},
+	})
+	var got auth.Principal
+	handler := func(srv any, stream grpc.ServerStream) error {
+		got, _ = auth.FromContext(stream.Context())
+		return nil
+	}
+
+	call := func(method string, principal *auth.Principal) error {
+		ctx := context.Background()
+		if principal != nil {
+			token, err := a.Issue(*principal, time.Hour)
+			require.NoError(t, err)
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+		}
+		return interceptor(nil, testStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: method}, handler)
+	}
+
+	reflection := "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"
+	assert.Equal(t, codes.Unauthenticated, status.Code(call(reflection, nil)))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call("/loan.v1.LoanService/WatchLoans", &auth.Principal{ID: 7, Role: auth.RoleBorrower})))
+
+	require.NoError(t, call(reflection, &auth.Principal{ID: 7, Role: auth.RoleBorrower}))
+	assert.Equal(t, auth.Principal{ID: 7, Role: auth.RoleBorrower}, got)
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"loan_system/internal/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+type Config struct {
+	Tokens *Authenticator
+	// Partners verifies requests signed with a partner API key. Signed
+	// requests are rejected when nil.
+	Partners *PartnerAuthenticator
+	// StreamToken reports whether the request may authenticate with a token
+	// from IssueStreamToken in the access_token query parameter instead.
+	StreamToken func(c echo.Context) bool
+	Skipper     middleware.Skipper
+}
+
+// QueryAccessToken is the query parameter carrying a stream token.
+const QueryAccessToken = "access_token"
+
+// Middleware authenticates every request not skipped, either by its bearer
+// token or, when it carries an X-Partner-Key header, by its partner
+// signature, and attaches the principal to the request context. Requests
+// that fail get 401.
+func Middleware(cfg Config) echo.MiddlewareFunc {
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			var (
+				principal Principal
+				err       error
+			)
+			if c.Request().Header.Get(HeaderPartnerKey) != "" {
+				principal, err = verifyPartner(c, cfg.Partners)
+			} else {
+				principal, err = verifyToken(c, cfg)
+			}
+			if err != nil {
+				return err
+			}
+
+			c.SetRequest(c.Request().WithContext(WithPrincipal(c.Request().Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+func verifyToken(c echo.Context, cfg Config) (Principal, error) {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if query := c.QueryParam(QueryAccessToken); header == "" && query != "" && cfg.StreamToken != nil && cfg.StreamToken(c) {
+		principal, err := cfg.Tokens.ParseStreamToken(query)
+		if err != nil {
+			return Principal{}, echo.NewHTTPError(http.StatusUnauthorized, "invalid stream token")
+		}
+		return principal, nil
+	}
+
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+		return Principal{}, echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+	}
+
+	principal, err := cfg.Tokens.Parse(token)
+	if err != nil {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+		return Principal{}, echo.NewHTTPError(http.StatusUnauthorized, "invalid bearer token")
+	}
+	return principal, nil
+}
+
+// verifyPartner reads the body, up to the authenticator's limit, to check its
+// hash and puts it back for the handler.
+func verifyPartner(c echo.Context, a *PartnerAuthenticator) (Principal, error) {
+	if a == nil {
+		return Principal{}, echo.NewHTTPError(http.StatusUnauthorized, "partner access is not enabled")
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, a.maxBodySize))
+	if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+		return Principal{}, echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("a partner request may be at most %d bytes", tooLarge.Limit))
+	}
+	if err != nil {
+		return Principal{}, echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+	principal, err := a.Verify(c.Request(), body)
+	if err != nil {
+		return Principal{}, echo.NewHTTPError(http.StatusUnauthorized, "invalid partner signature: "+err.Error())
+	}
+	return principal, nil
+}
+
+// Require lets the request through when the caller has one of roles. Admins
+// are always let through.
+func Require(roles ...Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := FromContext(c.Request().Context())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+			}
+			if !permitted(principal, roles) {
+				return echo.NewHTTPError(http.StatusForbidden, "role "+string(principal.Role)+" may not perform this operation")
+			}
+			return next(c)
+		}
+	}
+}
+
+func permitted(principal Principal, roles []Role) bool {
+	return principal.Role == RoleAdmin || slices.Contains(roles, principal.Role)
+}
+
+// Owner restricts callers with role to resources whose path param matches
+// their own ID, such as an investor reading only their own portfolio. Other
+// roles are not checked here.
+func Owner(role Role, param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := FromContext(c.Request().Context())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+			}
+			if principal.Role == role && c.Param(param) != strconv.FormatInt(principal.ID, 10) {
+				return echo.NewHTTPError(http.StatusForbidden, "access to another "+string(role)+"'s resources is not allowed")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireScope restricts partners to keys that carry scope. Other roles are
+// not checked here.
+func RequireScope(scope model.PartnerScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal, ok := FromContext(c.Request().Context())
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing bearer token")
+			}
+			if principal.Role == RolePartner && !slices.Contains(principal.Scopes, scope) {
+				return echo.NewHTTPError(http.StatusForbidden, "partner key lacks scope "+string(scope))
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"loan_system/internal/pkg/auth"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	a := auth.NewAuthenticator("secret")
+
+	e := echo.New()
+	e.Use(auth.Middleware(auth.Config{
+		Tokens:      a,
+		StreamToken: func(c echo.Context) bool { return c.Path() == "/loans/stream" },
+		Skipper:     func(c echo.Context) bool { return c.Path() == "/healthcheck" },
+	}))
+	e.GET("/healthcheck", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.GET("/loans/stream", func(c echo.Context) error {
+		principal, _ := auth.FromContext(c.Request().Context())
+		return c.JSON(http.StatusOK, principal.ID)
+	})
+	e.PUT("/loans/:id/approve", func(c echo.Context) error {
+		principal, _ := auth.FromContext(c.Request().Context())
+		return c.JSON(http.StatusOK, principal.ID)
+	}, auth.Require(auth.RoleFieldValidator))
+	e.GET("/investors/:id/portfolio", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.Require(auth.RoleInvestor), auth.Owner(auth.RoleInvestor, "id"))
+
+	do := func(method, path string, principal *auth.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if principal != nil {
+			token, err := a.Issue(*principal, time.Hour)
+			assert.NoError(t, err)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		principal *auth.Principal
+		want      int
+	}{
+		{name: "skipped route", method: http.MethodGet, path: "/healthcheck", want: http.StatusOK},
+		{name: "missing token", method: http.MethodPut, path: "/loans/1/approve", want: http.StatusUnauthorized},
+		{name: "allowed role", method: http.MethodPut, path: "/loans/1/approve", principal: &auth.Principal{ID: 7, Role: auth.RoleFieldValidator}, want: http.StatusOK},
+		{name: "admin", method: http.MethodPut, path: "/loans/1/approve", principal: &auth.Principal{ID: 1, Role: auth.RoleAdmin}, want: http.StatusOK},
+		{name: "other role", method: http.MethodPut, path: "/loans/1/approve", principal: &auth.Principal{ID: 7, Role: auth.RoleInvestor}, want: http.StatusForbidden},
+		{name: "own resource", method: http.MethodGet, path: "/investors/7/portfolio", principal: &auth.Principal{ID: 7, Role: auth.RoleInvestor}, want: http.StatusOK},
+		{name: "another owner's resource", method: http.MethodGet, path: "/investors/8/portfolio", principal: &auth.Principal{ID: 7, Role: auth.RoleInvestor}, want: http.StatusForbidden},
+		{name: "admin on another owner's resource", method: http.MethodGet, path: "/investors/8/portfolio", principal: &auth.Principal{ID: 1, Role: auth.RoleAdmin}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.path, tt.principal)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/loans/1/approve", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer not-a-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "invalid_token")
+	})
+
+	t.Run("stream token in the query", func(t *testing.T) {
+		streamToken, err := a.IssueStreamToken(auth.Principal{ID: 7, Role: auth.RoleInvestor}, time.Minute)
+		assert.NoError(t, err)
+		bearer, err := a.Issue(auth.Principal{ID: 7, Role: auth.RoleInvestor}, time.Hour)
+		assert.NoError(t, err)
+
+		query := func(path, token string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+auth.QueryAccessToken+"="+token, nil))
+			return rec
+		}
+
+		rec := query("/loans/stream", streamToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "7\n", rec.Body.String())
+		assert.Equal(t, http.StatusUnauthorized, query("/loans/stream", bearer).Code, "bearer tokens stay out of URLs")
+		assert.Equal(t, http.StatusUnauthorized, query("/investors/7/portfolio", streamToken).Code, "only stream routes read the query")
+
+		req := httptest.NewRequest(http.MethodGet, "/investors/7/portfolio", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+streamToken)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "stream tokens are not bearer tokens")
+	})
+
+	t.Run("acting id comes from the token", func(t *testing.T) {
+		rec := do(http.MethodPut, "/loans/1/approve", &auth.Principal{ID: 7, Role: auth.RoleFieldValidator})
+		assert.Equal(t, "7\n", rec.Body.String())
+	})
+}
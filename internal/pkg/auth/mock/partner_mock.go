@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: partner.go
+//
+// Generated by this command:
+//
+//	mockgen -source=partner.go -destination=mock/partner_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "loan_system/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockPartnerKeys is a mock of PartnerKeys interface.
+type MockPartnerKeys struct {
+	ctrl     *gomock.Controller
+	recorder *MockPartnerKeysMockRecorder
+	isgomock struct{}
+}
+
+// MockPartnerKeysMockRecorder is the mock recorder for MockPartnerKeys.
+type MockPartnerKeysMockRecorder struct {
+	mock *MockPartnerKeys
+}
+
+// NewMockPartnerKeys creates a new mock instance.
+func NewMockPartnerKeys(ctrl *gomock.Controller) *MockPartnerKeys {
+	mock := &MockPartnerKeys{ctrl: ctrl}
+	mock.recorder = &MockPartnerKeysMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPartnerKeys) EXPECT() *MockPartnerKeysMockRecorder {
+	return m.recorder
+}
+
+// FindKey mocks base method.
+func (m *MockPartnerKeys) FindKey(ctx context.Context, id string) (*model.PartnerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKey", ctx, id)
+	ret0, _ := ret[0].(*model.PartnerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindKey indicates an expected call of FindKey.
+func (mr *MockPartnerKeysMockRecorder) FindKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKey", reflect.TypeOf((*MockPartnerKeys)(nil).FindKey), ctx, id)
+}
+
+// MockNonceStore is a mock of NonceStore interface.
+type MockNonceStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockNonceStoreMockRecorder
+	isgomock struct{}
+}
+
+// MockNonceStoreMockRecorder is the mock recorder for MockNonceStore.
+type MockNonceStoreMockRecorder struct {
+	mock *MockNonceStore
+}
+
+// NewMockNonceStore creates a new mock instance.
+func NewMockNonceStore(ctrl *gomock.Controller) *MockNonceStore {
+	mock := &MockNonceStore{ctrl: ctrl}
+	mock.recorder = &MockNonceStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNonceStore) EXPECT() *MockNonceStoreMockRecorder {
+	return m.recorder
+}
+
+// Use mocks base method.
+func (m *MockNonceStore) Use(ctx context.Context, keyID, nonce string, until time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, keyID, nonce, until)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockNonceStoreMockRecorder) Use(ctx, keyID, nonce, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockNonceStore)(nil).Use), ctx, keyID, nonce, until)
+}
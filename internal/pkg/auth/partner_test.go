@@ -0,0 +1,194 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/pkg/auth/mock"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func signedRequest(method, path, body, keyID, secret, nonce string, at time.Time) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(auth.HeaderPartnerKey, keyID)
+	req.Header.Set(auth.HeaderPartnerTimestamp, strconv.FormatInt(at.Unix(), 10))
+	req.Header.Set(auth.HeaderPartnerNonce, nonce)
+	req.Header.Set(auth.HeaderPartnerSignature, auth.Sign(secret, method, path, at.Unix(), nonce, []byte(body)))
+	return req
+}
+
+func TestPartnerAuthenticator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	revokedAt := time.Now()
+	keys := mock.NewMockPartnerKeys(ctrl)
+	keys.EXPECT().FindKey(gomock.Any(), "pk_live").Return(&model.PartnerKey{
+		ID: "pk_live", PartnerID: 77, Secret: "s3cret", Scopes: []model.PartnerScope{model.PartnerScopeLoansCreate},
+	}, nil).AnyTimes()
+	keys.EXPECT().FindKey(gomock.Any(), "pk_revoked").Return(&model.PartnerKey{
+		ID: "pk_revoked", PartnerID: 77, Secret: "s3cret", RevokedAt: &revokedAt,
+	}, nil).AnyTimes()
+	keys.EXPECT().FindKey(gomock.Any(), gomock.Any()).Return(nil, errors.New("partner key not found")).AnyTimes()
+
+	a := auth.NewPartnerAuthenticator(keys, auth.NewMemoryNonceStore(), 5*time.Minute, 1<<20)
+	now := time.Now()
+	body := `{"principal":1000}`
+
+	t.Run("valid signature", func(t *testing.T) {
+		principal, err := a.Verify(signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-1", now), []byte(body))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(77), principal.ID)
+		assert.Equal(t, auth.RolePartner, principal.Role)
+		assert.Equal(t, []model.PartnerScope{model.PartnerScopeLoansCreate}, principal.Scopes)
+	})
+
+	t.Run("replayed nonce", func(t *testing.T) {
+		_, err := a.Verify(signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-1", now), []byte(body))
+		assert.ErrorContains(t, err, "nonce already used")
+	})
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		body    string
+		wantErr string
+	}{
+		{name: "tampered body", req: signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-2", now), body: `{"principal":9000}`, wantErr: "signature mismatch"},
+		{name: "tampered path", req: func() *http.Request {
+			req := signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-3", now)
+			req.URL.Path = "/loans/1/invest"
+			return req
+		}(), body: body, wantErr: "signature mismatch"},
+		{name: "wrong secret", req: signedRequest(http.MethodPost, "/loans", body, "pk_live", "guess", "n-4", now), body: body, wantErr: "signature mismatch"},
+		{name: "stale timestamp", req: signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-5", now.Add(-10*time.Minute)), body: body, wantErr: "outside the allowed window"},
+		{name: "future timestamp", req: signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-6", now.Add(10*time.Minute)), body: body, wantErr: "outside the allowed window"},
+		{name: "revoked key", req: signedRequest(http.MethodPost, "/loans", body, "pk_revoked", "s3cret", "n-7", now), body: body, wantErr: "key revoked"},
+		{name: "unknown key", req: signedRequest(http.MethodPost, "/loans", body, "pk_other", "s3cret", "n-8", now), body: body, wantErr: "unknown key"},
+		{name: "missing nonce", req: signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "", now), body: body, wantErr: "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.Verify(tt.req, []byte(tt.body))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("forged requests don't burn the nonce", func(t *testing.T) {
+		_, err := a.Verify(signedRequest(http.MethodPost, "/loans", body, "pk_live", "guess", "n-9", now), []byte(body))
+		assert.Error(t, err)
+
+		_, err = a.Verify(signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "n-9", now), []byte(body))
+		assert.NoError(t, err)
+	})
+}
+
+func TestPartnerMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := mock.NewMockPartnerKeys(ctrl)
+	keys.EXPECT().FindKey(gomock.Any(), "pk_live").Return(&model.PartnerKey{
+		ID: "pk_live", PartnerID: 77, Secret: "s3cret", Scopes: []model.PartnerScope{model.PartnerScopeLoansCreate},
+	}, nil).AnyTimes()
+
+	e := echo.New()
+	e.Use(auth.Middleware(auth.Config{
+		Tokens:   auth.NewAuthenticator("secret"),
+		Partners: auth.NewPartnerAuthenticator(keys, auth.NewMemoryNonceStore(), time.Minute, 64),
+	}))
+	e.POST("/loans", func(c echo.Context) error {
+		var req map[string]interface{}
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, req)
+	}, auth.Require(auth.RoleBorrower, auth.RolePartner), auth.RequireScope(model.PartnerScopeLoansCreate))
+	e.POST("/loans/:id/invest", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.Require(auth.RoleInvestor, auth.RolePartner), auth.RequireScope(model.PartnerScopeLoansInvest))
+	e.PUT("/loans/:id/approve", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.Require(auth.RoleFieldValidator))
+
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("signed request reaches the handler with its body", func(t *testing.T) {
+		rec := do(signedRequest(http.MethodPost, "/loans", `{"borrower_id":5}`, "pk_live", "s3cret", "a", time.Now()))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"borrower_id":5}`, rec.Body.String())
+	})
+
+	t.Run("body over the limit", func(t *testing.T) {
+		body := `{"agreement_link":"` + strings.Repeat("a", 64) + `"}`
+		rec := do(signedRequest(http.MethodPost, "/loans", body, "pk_live", "s3cret", "f", time.Now()))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at most 64 bytes")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		rec := do(signedRequest(http.MethodPost, "/loans", `{}`, "pk_live", "guess", "b", time.Now()))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("missing scope", func(t *testing.T) {
+		rec := do(signedRequest(http.MethodPost, "/loans/1/invest", `{}`, "pk_live", "s3cret", "c", time.Now()))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("route closed to partners", func(t *testing.T) {
+		rec := do(signedRequest(http.MethodPut, "/loans/1/approve", `{}`, "pk_live", "s3cret", "d", time.Now()))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("partners disabled", func(t *testing.T) {
+		e := echo.New()
+		e.Use(auth.Middleware(auth.Config{Tokens: auth.NewAuthenticator("secret")}))
+		e.POST("/loans", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, signedRequest(http.MethodPost, "/loans", `{}`, "pk_live", "s3cret", "e", time.Now()))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := auth.NewMemoryNonceStore()
+	until := time.Now().Add(time.Minute)
+
+	fresh, err := store.Use(context.Background(), "pk_1", "n", until)
+	assert.NoError(t, err)
+	assert.True(t, fresh)
+
+	fresh, err = store.Use(context.Background(), "pk_1", "n", until)
+	assert.NoError(t, err)
+	assert.False(t, fresh)
+
+	fresh, err = store.Use(context.Background(), "pk_2", "n", until)
+	assert.NoError(t, err)
+	assert.True(t, fresh, "nonces are per key")
+
+	fresh, err = store.Use(context.Background(), "pk_1", "expired", time.Now().Add(-time.Second))
+	assert.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, err = store.Use(context.Background(), "pk_1", "expired", until)
+	assert.NoError(t, err)
+	assert.True(t, fresh, "expired nonces may be reused")
+}
@@ -0,0 +1,308 @@
+// Package client calls the loan HTTP API of a running server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan_system/internal/model"
+)
+
+// Error is a response with a non-2xx status.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New returns a client sending token as the bearer token to the server at
+// baseURL, such as http://localhost:1323.
+func New(baseURL, token string, httpClient *http.Client) *Client {
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, http: httpClient}
+}
+
+// CreateLoan proposes a loan. BorrowerID is only sent by admins and partners
+// acting for a borrower.
+type CreateLoan struct {
+	BorrowerID     int64   `json:"borrower_id,omitempty"`
+	Principal      float64 `json:"principal"`
+	Rate           float64 `json:"rate"`
+	ROI            float64 `json:"roi"`
+	AgreementLink  string  `json:"agreement_link"`
+	TenorMonths    int     `json:"tenor_months,omitempty"`
+	IdempotencyKey string  `json:"-"`
+}
+
+type ApproveLoan struct {
+	ProofDocumentID int64      `json:"proof_document_id"`
+	ApprovedAt      *time.Time `json:"approved_at,omitempty"`
+	IdempotencyKey  string     `json:"-"`
+}
+
+// AddInvestment invests in a loan. InvestorID is only sent by admins and
+// partners acting for an investor.
+type AddInvestment struct {
+	InvestorID     int64   `json:"investor_id,omitempty"`
+	Amount         float64 `json:"amount"`
+	IdempotencyKey string  `json:"-"`
+}
+
+type DisburseLoan struct {
+	AgreementDocumentID int64      `json:"agreement_document_id"`
+	DisbursedAt         *time.Time `json:"disbursed_at,omitempty"`
+	IdempotencyKey      string     `json:"-"`
+}
+
+// ListLoans filters and pages loans. Zero fields are left out. Sort is a
+// field name, prefixed with "-" for descending order.
+type ListLoans struct {
+	State        model.LoanState
+	BorrowerID   int64
+	InvestorID   int64
+	MinPrincipal float64
+	MaxPrincipal float64
+	CreatedFrom  time.Time
+	CreatedTo    time.Time
+	Sort         string
+	Cursor       string
+	Limit        int
+}
+
+// ExportLoans downloads every loan matching the filters of ListLoans, whose
+// Cursor and Limit are ignored, in Format: csv, the default when empty,
+// ndjson or parquet.
+type ExportLoans struct {
+	ListLoans
+	Format model.ExportFormat
+}
+
+// ImportLoans uploads a CSV file of loan proposals. Mode is all_or_nothing,
+// the default when empty, or best_effort.
+type ImportLoans struct {
+	Mode           model.ImportMode
+	FileName       string
+	File           io.Reader
+	IdempotencyKey string
+}
+
+func (c *Client) CreateLoan(ctx context.Context, in CreateLoan) (*model.Loan, error) {
+	return c.loan(ctx, http.MethodPost, "/loans", in, in.IdempotencyKey)
+}
+
+func (c *Client) ApproveLoan(ctx context.Context, id int64, in ApproveLoan) (*model.Loan, error) {
+	return c.loan(ctx, http.MethodPut, fmt.Sprintf("/loans/%d/approve", id), in, in.IdempotencyKey)
+}
+
+func (c *Client) AddInvestment(ctx context.Context, id int64, in AddInvestment) (*model.Loan, error) {
+	return c.loan(ctx, http.MethodPost, fmt.Sprintf("/loans/%d/invest", id), in, in.IdempotencyKey)
+}
+
+func (c *Client) DisburseLoan(ctx context.Context, id int64, in DisburseLoan) (*model.Loan, error) {
+	return c.loan(ctx, http.MethodPut, fmt.Sprintf("/loans/%d/disburse", id), in, in.IdempotencyKey)
+}
+
+func (c *Client) GetLoan(ctx context.Context, id int64) (*model.Loan, error) {
+	return c.loan(ctx, http.MethodGet, fmt.Sprintf("/loans/%d", id), nil, "")
+}
+
+func (c *Client) ListLoans(ctx context.Context, in ListLoans) (*model.LoanPage, error) {
+	query := in.values()
+	if in.Cursor != "" {
+		query.Set("cursor", in.Cursor)
+	}
+	if in.Limit != 0 {
+		query.Set("limit", strconv.Itoa(in.Limit))
+	}
+
+	path := "/loans"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var response struct {
+		Data struct {
+			Loans []*model.Loan `json:"loans"`
+		} `json:"data"`
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, "", &response); err != nil {
+		return nil, err
+	}
+	return &model.LoanPage{Loans: response.Data.Loans, NextCursor: response.NextCursor}, nil
+}
+
+// ExportLoans copies the export to w as it is downloaded. An error part way
+// through leaves what was copied so far in w.
+func (c *Client) ExportLoans(ctx context.Context, in ExportLoans, w io.Writer) error {
+	query := in.values()
+	if in.Format != "" {
+		query.Set("format", string(in.Format))
+	}
+	path := "/loans/export"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp, err := c.open(ctx, http.MethodGet, path, "", nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// values encodes the filters and sort order, leaving out zero fields.
+func (in ListLoans) values() url.Values {
+	query := url.Values{}
+	set := func(name, value string, zero bool) {
+		if !zero {
+			query.Set(name, value)
+		}
+	}
+	set("state", string(in.State), in.State == "")
+	set("borrower_id", strconv.FormatInt(in.BorrowerID, 10), in.BorrowerID == 0)
+	set("investor_id", strconv.FormatInt(in.InvestorID, 10), in.InvestorID == 0)
+	set("min_principal", strconv.FormatFloat(in.MinPrincipal, 'f', -1, 64), in.MinPrincipal == 0)
+	set("max_principal", strconv.FormatFloat(in.MaxPrincipal, 'f', -1, 64), in.MaxPrincipal == 0)
+	set("created_from", in.CreatedFrom.Format(time.RFC3339), in.CreatedFrom.IsZero())
+	set("created_to", in.CreatedTo.Format(time.RFC3339), in.CreatedTo.IsZero())
+	set("sort", in.Sort, in.Sort == "")
+	return query
+}
+
+// ImportLoans starts an import and returns the job to poll with GetImport.
+func (c *Client) ImportLoans(ctx context.Context, in ImportLoans) (*model.ImportJob, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if in.Mode != "" {
+		if err := w.WriteField("mode", string(in.Mode)); err != nil {
+			return nil, err
+		}
+	}
+	part, err := w.CreateFormFile("file", in.FileName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, in.File); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data struct {
+			Import *model.ImportJob `json:"import"`
+		} `json:"data"`
+	}
+	if err := c.send(ctx, http.MethodPost, "/loans/import", w.FormDataContentType(), &body, in.IdempotencyKey, &response); err != nil {
+		return nil, err
+	}
+	return response.Data.Import, nil
+}
+
+func (c *Client) GetImport(ctx context.Context, id int64) (*model.ImportJob, error) {
+	var response struct {
+		Data struct {
+			Import *model.ImportJob `json:"import"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/loans/import/%d", id), nil, "", &response); err != nil {
+		return nil, err
+	}
+	return response.Data.Import, nil
+}
+
+func (c *Client) loan(ctx context.Context, method, path string, body any, idempotencyKey string) (*model.Loan, error) {
+	var response struct {
+		Data struct {
+			Loan *model.Loan `json:"loan"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, method, path, body, idempotencyKey, &response); err != nil {
+		return nil, err
+	}
+	return response.Data.Loan, nil
+}
+
+// do sends body as JSON, when not nil, and decodes a successful response
+// into out. Error responses are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, body any, idempotencyKey string, out any) error {
+	if body == nil {
+		return c.send(ctx, method, path, "", nil, idempotencyKey, out)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, method, path, "application/json", bytes.NewReader(data), idempotencyKey, out)
+}
+
+// send is do for a body already encoded as contentType.
+func (c *Client) send(ctx context.Context, method, path, contentType string, body io.Reader, idempotencyKey string, out any) error {
+	resp, err := c.open(ctx, method, path, contentType, body, idempotencyKey)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// open sends a request and returns the response for the caller to read and
+// close. Error responses are returned as *Error.
+func (c *Client) open(ctx context.Context, method, path, contentType string, body io.Reader, idempotencyKey string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var failure struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &failure) != nil || failure.Message == "" {
+			failure.Message = strings.TrimSpace(string(data))
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, Message: failure.Message}
+	}
+	return resp, nil
+}
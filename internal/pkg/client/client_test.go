@@ -0,0 +1,146 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	respond := `{"status":200,"data":{"loan":{"id":7,"state":"PROPOSED"}}}`
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got, gotBody = r, string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, respond)
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL+"/", "t0ken", server.Client())
+	ctx := context.Background()
+
+	t.Run("CreateLoan", func(t *testing.T) {
+		loan, err := c.CreateLoan(ctx, client.CreateLoan{Principal: 1000, Rate: 0.05, ROI: 0.07, AgreementLink: "https://example.com/a", IdempotencyKey: "k1"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), loan.ID)
+		assert.Equal(t, http.MethodPost, got.Method)
+		assert.Equal(t, "/loans", got.URL.Path)
+		assert.Equal(t, "Bearer t0ken", got.Header.Get("Authorization"))
+		assert.Equal(t, "k1", got.Header.Get("Idempotency-Key"))
+		assert.JSONEq(t, `{"principal":1000,"rate":0.05,"roi":0.07,"agreement_link":"https://example.com/a"}`, gotBody)
+	})
+
+	t.Run("ApproveLoan", func(t *testing.T) {
+		approvedAt := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+		_, err := c.ApproveLoan(ctx, 7, client.ApproveLoan{ProofDocumentID: 3, ApprovedAt: &approvedAt})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPut, got.Method)
+		assert.Equal(t, "/loans/7/approve", got.URL.Path)
+		assert.Empty(t, got.Header.Get("Idempotency-Key"))
+		assert.JSONEq(t, `{"proof_document_id":3,"approved_at":"2025-01-02T10:00:00Z"}`, gotBody)
+	})
+
+	t.Run("AddInvestment", func(t *testing.T) {
+		_, err := c.AddInvestment(ctx, 7, client.AddInvestment{InvestorID: 789, Amount: 500})
+		require.NoError(t, err)
+		assert.Equal(t, "/loans/7/invest", got.URL.Path)
+		assert.JSONEq(t, `{"investor_id":789,"amount":500}`, gotBody)
+	})
+
+	t.Run("DisburseLoan", func(t *testing.T) {
+		_, err := c.DisburseLoan(ctx, 7, client.DisburseLoan{AgreementDocumentID: 4})
+		require.NoError(t, err)
+		assert.Equal(t, "/loans/7/disburse", got.URL.Path)
+		assert.JSONEq(t, `{"agreement_document_id":4}`, gotBody)
+	})
+
+	t.Run("GetLoan", func(t *testing.T) {
+		loan, err := c.GetLoan(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, model.StateProposed, loan.State)
+		assert.Equal(t, http.MethodGet, got.Method)
+		assert.Empty(t, gotBody)
+	})
+
+	t.Run("ListLoans", func(t *testing.T) {
+		respond = `{"status":200,"data":{"loans":[{"id":7},{"id":8}]},"next_cursor":"abc"}`
+		page, err := c.ListLoans(ctx, client.ListLoans{State: model.StateApproved, MinPrincipal: 1000.5, Sort: "-principal", Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, page.Loans, 2)
+		assert.Equal(t, "abc", page.NextCursor)
+		assert.Equal(t, "limit=2&min_principal=1000.5&sort=-principal&state=APPROVED", got.URL.RawQuery)
+	})
+
+	t.Run("ImportLoans", func(t *testing.T) {
+		status, respond = http.StatusAccepted, `{"status":202,"data":{"import":{"id":9,"status":"pending","total":1,"created":0,"failed":0,"rows":[]}}}`
+		job, err := c.ImportLoans(ctx, client.ImportLoans{Mode: model.ImportBestEffort, FileName: "loans.csv", File: strings.NewReader("principal\n1000\n"), IdempotencyKey: "k2"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(9), job.ID)
+		assert.Equal(t, "/loans/import", got.URL.Path)
+		assert.Equal(t, "k2", got.Header.Get("Idempotency-Key"))
+		assert.True(t, strings.HasPrefix(got.Header.Get("Content-Type"), "multipart/form-data; boundary="))
+		assert.Contains(t, gotBody, "best_effort")
+		assert.Contains(t, gotBody, `filename="loans.csv"`)
+		assert.Contains(t, gotBody, "principal\n1000\n")
+	})
+
+	t.Run("GetImport", func(t *testing.T) {
+		status, respond = http.StatusOK, `{"status":200,"data":{"import":{"id":9,"status":"completed","total":1,"created":1,"failed":0,"rows":[{"line":2,"status":"created","loan_id":7}]}}}`
+		job, err := c.GetImport(ctx, 9)
+		require.NoError(t, err)
+		assert.Equal(t, "/loans/import/9", got.URL.Path)
+		assert.Equal(t, model.ImportCompleted, job.Status)
+		assert.Equal(t, int64(7), job.Rows[0].LoanID)
+	})
+
+	t.Run("ExportLoans", func(t *testing.T) {
+		status, respond = http.StatusOK, "loan_id\n7\n"
+		var out strings.Builder
+		err := c.ExportLoans(ctx, client.ExportLoans{ListLoans: client.ListLoans{State: model.StateDisbursed, Cursor: "ignored", Limit: 5}, Format: model.ExportCSV}, &out)
+		require.NoError(t, err)
+		assert.Equal(t, "/loans/export", got.URL.Path)
+		assert.Equal(t, "format=csv&state=DISBURSED", got.URL.RawQuery)
+		assert.Equal(t, "loan_id\n7\n", out.String())
+
+		status, respond = http.StatusForbidden, `{"message":"insufficient role"}`
+		out.Reset()
+		err = c.ExportLoans(ctx, client.ExportLoans{}, &out)
+		assert.EqualError(t, err, "403 Forbidden: insufficient role")
+		assert.Empty(t, got.URL.RawQuery)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		status, respond = http.StatusForbidden, `{"message":"insufficient role"}`
+		_, err := c.GetLoan(ctx, 7)
+		var apiErr *client.Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+		assert.Equal(t, "403 Forbidden: insufficient role", err.Error())
+
+		status, respond = http.StatusBadGateway, "upstream down"
+		_, err = c.GetLoan(ctx, 7)
+		assert.EqualError(t, err, "502 Bad Gateway: upstream down")
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		status, respond = http.StatusOK, `{"data":{"loan":{"id":7}}}`
+		_, err := client.New(server.URL, "", server.Client()).GetLoan(ctx, 7)
+		require.NoError(t, err)
+		assert.Empty(t, got.Header.Get("Authorization"))
+	})
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Product is a kind of loan borrowers may propose, bounding its principal and
+// its tenor in months.
+type Product struct {
+	Name           string
+	MinPrincipal   float64
+	MaxPrincipal   float64
+	MinTenorMonths int
+	MaxTenorMonths int
+}
+
+// Catalog is the list of products on offer.
+type Catalog []Product
+
+// ParseCatalog parses products separated by commas, each written as
+// "<name>:<min principal>-<max principal>:<min months>-<max months>", such as
+// "micro:100000-5000000:3-12,group:1000000-50000000:6-24". Names must be
+// unique, and every bound positive and no greater than its maximum.
+func ParseCatalog(s string) (Catalog, error) {
+	var catalog Catalog
+	seen := make(map[string]bool)
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		product, err := parseProduct(entry)
+		if err != nil {
+			return nil, err
+		}
+		if seen[product.Name] {
+			return nil, fmt.Errorf("product %q appears twice", product.Name)
+		}
+		seen[product.Name] = true
+		catalog = append(catalog, product)
+	}
+	return catalog, nil
+}
+
+func parseProduct(entry string) (Product, error) {
+	invalid := fmt.Errorf("invalid product %q, want <name>:<min principal>-<max principal>:<min months>-<max months>", entry)
+	parts := strings.Split(entry, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return Product{}, invalid
+	}
+	product := Product{Name: parts[0]}
+
+	minPrincipal, maxPrincipal, ok := strings.Cut(parts[1], "-")
+	if !ok {
+		return Product{}, invalid
+	}
+	var err error
+	if product.MinPrincipal, err = strconv.ParseFloat(minPrincipal, 64); err != nil {
+		return Product{}, invalid
+	}
+	if product.MaxPrincipal, err = strconv.ParseFloat(maxPrincipal, 64); err != nil {
+		return Product{}, invalid
+	}
+	// NaN fails both comparisons, so it is refused along with
+	// non-positive bounds.
+	if !(product.MinPrincipal > 0) || !(product.MaxPrincipal >= product.MinPrincipal) {
+		return Product{}, fmt.Errorf("product %q: principal must be positive and its minimum no greater than its maximum", product.Name)
+	}
+
+	minTenor, maxTenor, ok := strings.Cut(parts[2], "-")
+	if !ok {
+		return Product{}, invalid
+	}
+	if product.MinTenorMonths, err = strconv.Atoi(minTenor); err != nil {
+		return Product{}, invalid
+	}
+	if product.MaxTenorMonths, err = strconv.Atoi(maxTenor); err != nil {
+		return Product{}, invalid
+	}
+	if product.MinTenorMonths <= 0 || product.MaxTenorMonths < product.MinTenorMonths {
+		return Product{}, fmt.Errorf("product %q: tenor must be positive and its minimum no greater than its maximum", product.Name)
+	}
+	return product, nil
+}
+
+// UnmarshalText parses a catalog the way ParseCatalog does, so that it can be
+// read straight from configuration.
+func (c *Catalog) UnmarshalText(text []byte) error {
+	catalog, err := ParseCatalog(string(text))
+	if err != nil {
+		return err
+	}
+	*c = catalog
+	return nil
+}
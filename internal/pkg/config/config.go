@@ -1,36 +1,162 @@
+// Package config loads the service settings from layered sources and checks
+// them before anything starts. See Load for the sources and Store for
+// reloading them while running.
 package config
 
 import (
-	"os"
+	"time"
 
-	"github.com/joho/godotenv"
-	"github.com/kelseyhightower/envconfig"
+	"loan_system/internal/pkg/ratelimit"
 )
 
+// Config holds every setting. Each one is named by its YAML path, such as
+// rate_limit.loans, and its environment variable, such as RATE_LIMIT_LOANS,
+// and may carry a default, validate rules and reload:"true" when it is safe
+// to change while running.
 type Config struct {
-	App App `envconfig:"APP"`
+	App         App         `yaml:"app" env:"APP"`
+	Storage     Storage     `yaml:"storage" env:"STORAGE"`
+	Database    Database    `yaml:"database" env:"DATABASE"`
+	Tracing     Tracing     `yaml:"tracing" env:"TRACING"`
+	Log         Log         `yaml:"log" env:"LOG"`
+	Metrics     Metrics     `yaml:"metrics" env:"METRICS"`
+	Idempotency Idempotency `yaml:"idempotency" env:"IDEMPOTENCY"`
+	Auth        Auth        `yaml:"auth" env:"AUTH"`
+	RateLimit   RateLimit   `yaml:"rate_limit" env:"RATE_LIMIT" reload:"true"`
+	Webhook     Webhook     `yaml:"webhook" env:"WEBHOOK"`
+	Stream      Stream      `yaml:"stream" env:"STREAM"`
+	Import      Import      `yaml:"import" env:"IMPORT"`
+	Fees        Fees        `yaml:"fees" env:"FEES"`
+	Funding     Funding     `yaml:"funding" env:"FUNDING"`
+	Products    Products    `yaml:"products" env:"PRODUCTS"`
 }
 
+// App sets the port served on, how long a client may take to send request
+// headers, and how long shutdown waits for requests in flight.
 type App struct {
-	ServerPort string `envconfig:"SERVER_PORT" default:"1323"`
+	ServerPort        string        `yaml:"server_port" env:"SERVER_PORT" default:"1323" validate:"required,numeric"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"READ_HEADER_TIMEOUT" default:"10s" validate:"gt=0"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"10s" validate:"gt=0"`
 }
 
-var instance Config
+type Storage struct {
+	BlobDir       string `yaml:"blob_dir" env:"BLOB_DIR" default:"data/blobs" validate:"required"`
+	MaxUploadSize int64  `yaml:"max_upload_size" env:"MAX_UPLOAD_SIZE" default:"10485760" validate:"gt=0"`
+}
+
+// Database selects where loans are persisted: "memory" or "sqlite".
+type Database struct {
+	Backend    string `yaml:"backend" env:"BACKEND" default:"memory" validate:"oneof=memory sqlite"`
+	SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH" default:"data/loan.db" validate:"required"`
+}
+
+// Tracing selects the span exporter: "none", "stdout" or "otlp". The OTLP
+// exporter sends to OTLPEndpoint over HTTP.
+type Tracing struct {
+	Exporter     string  `yaml:"exporter" env:"EXPORTER" default:"none" validate:"oneof=none stdout otlp"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTLP_ENDPOINT" default:"localhost:4318"`
+	ServiceName  string  `yaml:"service_name" env:"SERVICE_NAME" default:"loan_system" validate:"required"`
+	SampleRatio  float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" default:"1" validate:"gte=0,lte=1"`
+}
+
+// Log sets the minimum level (debug, info, warn or error) and the output
+// format ("json" or "text").
+type Log struct {
+	Level  string `yaml:"level" env:"LEVEL" default:"info" validate:"loglevel" reload:"true"`
+	Format string `yaml:"format" env:"FORMAT" default:"json" validate:"oneof=json text"`
+}
+
+// Metrics sets how long the loan book gauges are reused between scrapes
+// before the book is aggregated again.
+type Metrics struct {
+	LoanBookTTL time.Duration `yaml:"loan_book_ttl" env:"LOAN_BOOK_TTL" default:"30s" validate:"gt=0"`
+}
+
+// Idempotency sets how long responses to requests sent with an
+// Idempotency-Key are kept for replay, and caps the body of those requests,
+// which is held in memory to fingerprint it.
+type Idempotency struct {
+	TTL         time.Duration `yaml:"ttl" env:"TTL" default:"24h" validate:"gt=0"`
+	MaxBodySize int64         `yaml:"max_body_size" env:"MAX_BODY_SIZE" default:"16777216" validate:"gt=0"`
+}
 
-func Load() {
-	var envFilePath = ".env"
-	if _, err := os.Stat(envFilePath); err == nil {
-		if err := godotenv.Load(envFilePath); err != nil {
-			panic(err)
-		}
-	}
-	err := envconfig.Process("", &instance)
-	if err != nil {
-		panic(err)
-	}
+// Auth holds the HMAC key bearer tokens are signed with, which anyone
+// holding can issue themselves any role. Signed partner requests are accepted
+// within PartnerClockSkew of their timestamp, and their body, which is read
+// into memory to check the signature, may be at most PartnerMaxBodySize.
+type Auth struct {
+	SigningKey         string        `yaml:"signing_key" env:"SIGNING_KEY" validate:"required,min=32"`
+	PartnerClockSkew   time.Duration `yaml:"partner_clock_skew" env:"PARTNER_CLOCK_SKEW" default:"5m" validate:"gt=0"`
+	PartnerMaxBodySize int64         `yaml:"partner_max_body_size" env:"PARTNER_MAX_BODY_SIZE" default:"16777216" validate:"gt=0"`
+}
+
+// RateLimit sets per-client limits as "<requests>/<period>", or "0" to turn
+// one off. IP applies to every request before authentication; the others
+// apply per authenticated user or partner key to their route group. Reports
+// covers the investor, borrower and stats endpoints; Exports the loan book
+// export on top of the loans limit.
+type RateLimit struct {
+	IP       ratelimit.Limit `yaml:"ip" env:"IP" default:"600/1m"`
+	Loans    ratelimit.Limit `yaml:"loans" env:"LOANS" default:"120/1m"`
+	Invest   ratelimit.Limit `yaml:"invest" env:"INVEST" default:"20/1m"`
+	Reports  ratelimit.Limit `yaml:"reports" env:"REPORTS" default:"60/1m"`
+	Partners ratelimit.Limit `yaml:"partners" env:"PARTNERS" default:"30/1m"`
+	Webhooks ratelimit.Limit `yaml:"webhooks" env:"WEBHOOKS" default:"30/1m"`
+	Imports  ratelimit.Limit `yaml:"imports" env:"IMPORTS" default:"10/1m"`
+	Exports  ratelimit.Limit `yaml:"exports" env:"EXPORTS" default:"6/1m"`
+}
+
+// Webhook sets how partner webhooks are delivered. A failed delivery is
+// retried after Backoff, doubling each time up to MaxBackoff, and is
+// dead-lettered after MaxAttempts. Due retries are looked for every
+// PollInterval, and up to Concurrency deliveries are sent at once.
+// AllowInsecure lets webhooks use http and reach loopback and private
+// addresses; it is meant for local development only.
+type Webhook struct {
+	MaxAttempts   int           `yaml:"max_attempts" env:"MAX_ATTEMPTS" default:"8" validate:"gt=0"`
+	Backoff       time.Duration `yaml:"backoff" env:"BACKOFF" default:"10s" validate:"gt=0"`
+	MaxBackoff    time.Duration `yaml:"max_backoff" env:"MAX_BACKOFF" default:"1h" validate:"gtefield=Backoff"`
+	Timeout       time.Duration `yaml:"timeout" env:"TIMEOUT" default:"10s" validate:"gt=0"`
+	PollInterval  time.Duration `yaml:"poll_interval" env:"POLL_INTERVAL" default:"1s" validate:"gt=0"`
+	Concurrency   int           `yaml:"concurrency" env:"CONCURRENCY" default:"10" validate:"gt=0"`
+	AllowInsecure bool          `yaml:"allow_insecure" env:"ALLOW_INSECURE" default:"false"`
+}
+
+// Stream sets how many loan events are kept for clients resuming a loan
+// stream with Last-Event-ID, how often idle streams send a heartbeat, and how
+// long a browser's stream token may be used to open a stream.
+type Stream struct {
+	ReplaySize int           `yaml:"replay_size" env:"REPLAY_SIZE" default:"1000" validate:"gte=0"`
+	Heartbeat  time.Duration `yaml:"heartbeat" env:"HEARTBEAT" default:"15s" validate:"gt=0"`
+	TokenTTL   time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" default:"1m" validate:"gt=0"`
+}
+
+// Import caps how many rows a CSV loan import may hold, and sets how long a
+// finished import job can still be polled.
+type Import struct {
+	MaxRows   int           `yaml:"max_rows" env:"MAX_ROWS" default:"10000" validate:"gt=0" reload:"true"`
+	Retention time.Duration `yaml:"retention" env:"RETENTION" default:"24h" validate:"gt=0"`
+}
+
+// Fees sets what a loan is charged: an origination fee of OriginationRate of
+// the principal, but at least MinOriginationFee, and a late fee of
+// LatePaymentRate of each overdue instalment.
+type Fees struct {
+	OriginationRate   float64 `yaml:"origination_rate" env:"ORIGINATION_RATE" default:"0" validate:"gte=0,lte=1"`
+	MinOriginationFee float64 `yaml:"min_origination_fee" env:"MIN_ORIGINATION_FEE" default:"0" validate:"gte=0"`
+	LatePaymentRate   float64 `yaml:"late_payment_rate" env:"LATE_PAYMENT_RATE" default:"0" validate:"gte=0,lte=1"`
+}
 
+// Funding sets how long an approved loan stays open to investors, and the
+// smallest and largest amount one investment may bring.
+type Funding struct {
+	Window        time.Duration `yaml:"window" env:"WINDOW" default:"720h" validate:"gt=0"`
+	MinInvestment float64       `yaml:"min_investment" env:"MIN_INVESTMENT" default:"1" validate:"gt=0"`
+	MaxInvestment float64       `yaml:"max_investment" env:"MAX_INVESTMENT" default:"1000000000" validate:"gtefield=MinInvestment"`
 }
 
-func Instance() Config {
-	return instance
+// Products lists the loan products borrowers may propose, as read by
+// ParseCatalog. An empty catalog puts no bounds on the principal or tenor.
+type Products struct {
+	Catalog Catalog `yaml:"catalog" env:"CATALOG"`
 }
@@ -1,22 +1,237 @@
 package config_test
 
 import (
-	"loan_system/internal/pkg/config"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// signingKey is the one setting without a default.
+var signingKey = "AUTH_SIGNING_KEY=" + strings.Repeat("k", 32)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
 func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := config.Load(config.Sources{Environ: []string{signingKey}})
+		require.NoError(t, err)
+		assert.Equal(t, "1323", cfg.App.ServerPort)
+		assert.Equal(t, "memory", cfg.Database.Backend)
+		assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+		assert.Equal(t, ratelimit.Limit{Rate: 2, Burst: 120}, cfg.RateLimit.Loans)
+		assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+	})
+
+	t.Run("sources override the ones before them", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", `
+app:
+  server_port: "8000"
+log:
+  level: debug
+  format: text
+rate_limit:
+  loans: 60/1m
+# every setting of the section commented out
+stream:
+`)
+		envFile := writeFile(t, ".env", "LOG_LEVEL=warn\nDATABASE_BACKEND=sqlite\nIMPORT_MAX_ROWS=50\n")
+
+		cfg, err := config.Load(config.Sources{
+			File:      file,
+			EnvFile:   envFile,
+			Environ:   []string{signingKey, "IMPORT_MAX_ROWS=100", "RATE_LIMIT_LOANS=0"},
+			Overrides: []string{"import.max_rows=200", "webhook.backoff=1s"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "8000", cfg.App.ServerPort, "file over defaults")
+		assert.Equal(t, "text", cfg.Log.Format, "file over defaults")
+		assert.Equal(t, "warn", cfg.Log.Level, ".env over file")
+		assert.Equal(t, "sqlite", cfg.Database.Backend, ".env over defaults")
+		assert.False(t, cfg.RateLimit.Loans.Enabled(), "environment over file")
+		assert.Equal(t, 200, cfg.Import.MaxRows, "overrides over environment")
+		assert.Equal(t, time.Second, cfg.Webhook.Backoff)
+		assert.Equal(t, 1000, cfg.Stream.ReplaySize, "empty section keeps defaults")
+	})
+
+	t.Run("missing .env file is skipped", func(t *testing.T) {
+		_, err := config.Load(config.Sources{EnvFile: filepath.Join(t.TempDir(), ".env"), Environ: []string{signingKey}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		_, err := config.Load(config.Sources{File: writeFile(t, "config.yaml", ""), Environ: []string{signingKey}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := config.Load(config.Sources{File: filepath.Join(t.TempDir(), "config.yaml")})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestLoadErrors(t *testing.T) {
 	tests := []struct {
-		name string // description of this test case
+		name    string
+		file    string
+		environ []string
+		set     []string
+		want    []string
 	}{
-		// TODO: Add test cases.
 		{
-			name: "load config",
+			name: "unknown file setting",
+			file: "app:\n  server_prot: \"8000\"\n",
+			want: []string{"config.yaml:2: unknown setting app.server_prot"},
+		},
+		{
+			name: "list in file",
+			file: "rate_limit:\n  - 60/1m\n",
+			want: []string{"config.yaml:2: rate_limit must be a value or a mapping"},
+		},
+		{
+			name: "unparsable file value",
+			file: "idempotency:\n  ttl: a day\n",
+			want: []string{`config.yaml:2: idempotency.ttl (IDEMPOTENCY_TTL): invalid duration "a day"`},
+		},
+		{
+			name:    "unparsable values",
+			environ: []string{"RATE_LIMIT_LOANS=60", "IMPORT_MAX_ROWS=many", "TRACING_SAMPLE_RATIO=half"},
+			want: []string{
+				`rate_limit.loans (RATE_LIMIT_LOANS): invalid rate limit "60"`,
+				`import.max_rows (IMPORT_MAX_ROWS): invalid integer "many"`,
+				`tracing.sample_ratio (TRACING_SAMPLE_RATIO): invalid number "half"`,
+			},
+		},
+		{
+			name: "bad overrides",
+			set:  []string{"app.port=8000", "app.server_port", "database.backend=postgres"},
+			want: []string{
+				`override "app.port=8000": unknown setting app.port`,
+				`override "app.server_port": want <path>=<value>`,
+				`database.backend (DATABASE_BACKEND): must be one of memory, sqlite, got "postgres"`,
+			},
+		},
+		{
+			name:    "short signing key",
+			environ: []string{"AUTH_SIGNING_KEY=secret"},
+			want:    []string{"auth.signing_key (AUTH_SIGNING_KEY): must be at least 32 characters long"},
+		},
+		{
+			name:    "missing signing key",
+			environ: []string{"AUTH_SIGNING_KEY="},
+			want:    []string{"auth.signing_key (AUTH_SIGNING_KEY): must be set"},
+		},
+		{
+			name: "invalid settings",
+			environ: []string{
+				"DATABASE_BACKEND=postgres",
+				"LOG_LEVEL=verbose",
+				"APP_SERVER_PORT=http",
+				"TRACING_SAMPLE_RATIO=2",
+				"WEBHOOK_MAX_BACKOFF=1s",
+				"IDEMPOTENCY_TTL=0s",
+			},
+			want: []string{
+				`database.backend (DATABASE_BACKEND): must be one of memory, sqlite, got "postgres"`,
+				`log.level (LOG_LEVEL): must be debug, info, warn or error, got "verbose"`,
+				`app.server_port (APP_SERVER_PORT): must be a number, got "http"`,
+				`tracing.sample_ratio (TRACING_SAMPLE_RATIO): must be at most 1, got 2`,
+				`webhook.max_backoff (WEBHOOK_MAX_BACKOFF): must be at least webhook.backoff, got 1s`,
+				`idempotency.ttl (IDEMPOTENCY_TTL): must be greater than 0, got 0s`,
+			},
 		},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config.Load()
+			src := config.Sources{Environ: append([]string{signingKey}, tt.environ...), Overrides: tt.set}
+			if tt.file != "" {
+				src.File = writeFile(t, "config.yaml", tt.file)
+			}
+
+			cfg, err := config.Load(src)
+			assert.Nil(t, cfg)
+			require.Error(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, err.Error(), want)
+			}
 		})
 	}
 }
+
+func TestStoreReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+	write("log:\n  level: info\n")
+	src := config.Sources{File: file, Environ: []string{signingKey, "IMPORT_MAX_ROWS=100"}}
+	cfg, err := config.Load(src)
+	require.NoError(t, err)
+
+	store := config.NewStore(cfg, src)
+	var reloaded []*config.Config
+	store.OnReload(func(cfg *config.Config) { reloaded = append(reloaded, cfg) })
+
+	write(`
+app:
+  server_port: "8000"
+log:
+  level: debug
+  format: text
+rate_limit:
+  loans: 1/1s
+import:
+  max_rows: 5
+`)
+	pending, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app.server_port (APP_SERVER_PORT)", "log.format (LOG_FORMAT)"}, pending)
+
+	current := store.Current()
+	assert.Equal(t, []*config.Config{current}, reloaded)
+	assert.Equal(t, "debug", current.Log.Level)
+	assert.Equal(t, ratelimit.Limit{Rate: 1, Burst: 1}, current.RateLimit.Loans)
+	assert.Equal(t, 100, current.Import.MaxRows, "environment still wins over the file")
+	assert.Equal(t, "1323", current.App.ServerPort)
+	assert.Equal(t, "json", current.Log.Format)
+	assert.Equal(t, "info", cfg.Log.Level, "the old configuration is not modified")
+
+	write("log:\n  level: loud\n")
+	_, err = store.Reload()
+	assert.ErrorContains(t, err, "log.level (LOG_LEVEL)")
+	assert.Same(t, current, store.Current())
+	assert.Len(t, reloaded, 1)
+}
+
+func TestFlagsSources(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("CONFIG_FILE", "")
+
+	var flags config.Flags
+	assert.Empty(t, flags.Sources().File)
+
+	require.NoError(t, os.WriteFile(config.DefaultFile, nil, 0o600))
+	assert.Equal(t, config.DefaultFile, flags.Sources().File)
+
+	t.Setenv("CONFIG_FILE", "env.yaml")
+	assert.Equal(t, "env.yaml", flags.Sources().File)
+
+	flags = config.Flags{File: "flag.yaml", Set: []string{"log.level=debug"}}
+	src := flags.Sources()
+	assert.Equal(t, "flag.yaml", src.File)
+	assert.Equal(t, ".env", src.EnvFile)
+	assert.Equal(t, []string{"log.level=debug"}, src.Overrides)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultFile is the YAML file read when neither --config nor $CONFIG_FILE
+// names one, if it exists.
+const DefaultFile = "config.yaml"
+
+// Flags are the command-line flags that pick the YAML file and override
+// single settings, for commands that load the configuration.
+type Flags struct {
+	File string
+	Set  []string
+}
+
+func (f *Flags) Register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.File, "config", "", "YAML config file, or $CONFIG_FILE; "+DefaultFile+" is read when it exists")
+	flags.StringArrayVar(&f.Set, "set", nil, "override a setting by its YAML path, such as --set rate_limit.loans=60/1m")
+}
+
+// Sources layers the flags over the .env file and environment of this
+// process.
+func (f *Flags) Sources() Sources {
+	file := f.File
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file == "" {
+		if _, err := os.Stat(DefaultFile); err == nil {
+			file = DefaultFile
+		}
+	}
+
+	return Sources{File: file, EnvFile: ".env", Environ: os.Environ(), Overrides: f.Set}
+}
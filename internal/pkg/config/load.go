@@ -0,0 +1,342 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources are where Load reads settings from. Each overrides the ones before
+// it: the defaults of Config, File, EnvFile, Environ and then Overrides.
+type Sources struct {
+	// File is a YAML file laid out like Config, such as
+	// "rate_limit:\n  loans: 60/1m". Empty skips it.
+	File string
+	// EnvFile is a .env file of environment variables, skipped when it
+	// does not exist.
+	EnvFile string
+	// Environ holds "KEY=value" pairs, usually os.Environ().
+	Environ []string
+	// Overrides hold "path=value" pairs naming settings by their YAML path,
+	// such as "app.server_port=8080".
+	Overrides []string
+}
+
+// Load reads the settings from src and validates them. The error lists
+// every setting that could not be read or is invalid, by its YAML path and
+// environment variable.
+func Load(src Sources) (*Config, error) {
+	cfg := new(Config)
+	var errs []error
+	for _, s := range settings(cfg) {
+		if s.def == "" {
+			continue
+		}
+		if err := s.set(s.def); err != nil {
+			errs = append(errs, fmt.Errorf("%s: default %q: %w", s, s.def, err))
+		}
+	}
+
+	if src.File != "" {
+		values, err := readFile(src.File)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			s, ok := lookup(cfg, v.path)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s:%d: unknown setting %s", src.File, v.line, v.path))
+				continue
+			}
+			if err := s.set(v.value); err != nil {
+				errs = append(errs, fmt.Errorf("%s:%d: %s: %w", src.File, v.line, s, err))
+			}
+		}
+	}
+
+	env := make(map[string]string)
+	if src.EnvFile != "" {
+		dotenv, err := godotenv.Read(src.EnvFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("config: read %s: %w", src.EnvFile, err)
+		}
+		for key, value := range dotenv {
+			env[key] = value
+		}
+	}
+	for _, pair := range src.Environ {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			env[key] = value
+		}
+	}
+	for _, s := range settings(cfg) {
+		if value, ok := env[s.env]; ok {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s, err))
+			}
+		}
+	}
+
+	for _, override := range src.Overrides {
+		path, value, ok := strings.Cut(override, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("override %q: want <path>=<value>", override))
+			continue
+		}
+		s, ok := lookup(cfg, path)
+		if !ok {
+			errs = append(errs, fmt.Errorf("override %q: unknown setting %s", override, path))
+			continue
+		}
+		if err := s.set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s, err))
+		}
+	}
+
+	// Settings that could not be parsed keep their earlier value, so the
+	// rest are still worth checking.
+	errs = append(errs, validate(cfg)...)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// fileValue is a setting read from a YAML file.
+type fileValue struct {
+	path  string
+	value string
+	line  int
+}
+
+// readFile reads the settings in the YAML file at path, naming each by its
+// YAML path so that they are set, and their errors reported, the same way
+// as the other sources.
+func readFile(path string) ([]fileValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	var values []fileValue
+	var walk func(node *yaml.Node, prefix string) error
+	walk = func(node *yaml.Node, prefix string) error {
+		switch {
+		case node.Tag == "!!null":
+			// An empty key, such as a section with its settings commented
+			// out, leaves things as they are.
+		case node.Kind == yaml.ScalarNode:
+			values = append(values, fileValue{path: prefix, value: node.Value, line: node.Line})
+		case node.Kind == yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				if err := walk(node.Content[i+1], key); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("config: %s:%d: %s must be a value or a mapping", path, node.Line, prefix)
+		}
+		return nil
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	if err := walk(doc.Content[0], ""); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// setting is one field of Config with the names it is set by.
+type setting struct {
+	value reflect.Value
+	// path is the YAML path, such as rate_limit.loans.
+	path string
+	// env is the environment variable, such as RATE_LIMIT_LOANS.
+	env string
+	// field is the Go path below Config, such as RateLimit.Loans.
+	field  string
+	def    string
+	reload bool
+}
+
+func (s setting) String() string {
+	return s.path + " (" + s.env + ")"
+}
+
+// settings lists the fields of cfg in declaration order.
+func settings(cfg *Config) []setting {
+	var all []setting
+	var walk func(v reflect.Value, parent setting)
+	walk = func(v reflect.Value, parent setting) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			s := setting{
+				value:  v.Field(i),
+				path:   field.Tag.Get("yaml"),
+				env:    field.Tag.Get("env"),
+				field:  field.Name,
+				def:    field.Tag.Get("default"),
+				reload: parent.reload || field.Tag.Get("reload") == "true",
+			}
+			if parent.path != "" {
+				s.path, s.env, s.field = parent.path+"."+s.path, parent.env+"_"+s.env, parent.field+"."+s.field
+			}
+
+			if field.Type.Kind() == reflect.Struct && !isValue(field.Type) {
+				walk(s.value, s)
+				continue
+			}
+			all = append(all, s)
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), setting{})
+	return all
+}
+
+func lookup(cfg *Config, path string) (setting, bool) {
+	for _, s := range settings(cfg) {
+		if s.path == path {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isValue reports whether structs of type t are read from a single string
+// rather than holding settings of their own.
+func isValue(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// set parses value into the setting.
+func (s setting) set(value string) error {
+	v := s.value
+	if isValue(v.Type()) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.CanInt():
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(n)
+	case v.CanFloat():
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+var validate = func() func(cfg *Config) []error {
+	v := validator.New()
+	v.RegisterValidation("loglevel", func(fl validator.FieldLevel) bool {
+		var level slog.Level
+		return level.UnmarshalText([]byte(fl.Field().String())) == nil
+	})
+
+	// validate checks the validate rules of cfg, describing each broken
+	// one in terms of the setting's names rather than its Go field.
+	return func(cfg *Config) []error {
+		err := v.Struct(cfg)
+		var failures validator.ValidationErrors
+		if !errors.As(err, &failures) {
+			return nil
+		}
+
+		names := make(map[string]setting)
+		for _, s := range settings(cfg) {
+			names["Config."+s.field] = s
+		}
+		errs := make([]error, 0, len(failures))
+		for _, failure := range failures {
+			errs = append(errs, fmt.Errorf("%s: %s", names[failure.StructNamespace()], rule(failure, names)))
+		}
+		return errs
+	}
+}()
+
+// rule describes the validate rule a setting broke.
+func rule(failure validator.FieldError, names map[string]setting) string {
+	var got string
+	if d, ok := failure.Value().(time.Duration); ok {
+		got = d.String()
+	} else {
+		got = fmt.Sprintf("%v", failure.Value())
+	}
+
+	switch failure.Tag() {
+	case "required":
+		return "must be set"
+	case "numeric":
+		return fmt.Sprintf("must be a number, got %q", got)
+	case "oneof":
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(strings.Fields(failure.Param()), ", "), got)
+	case "min":
+		// the value is left out, as it may be a secret
+		return fmt.Sprintf("must be at least %s characters long", failure.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s, got %s", failure.Param(), got)
+	case "gte":
+		return fmt.Sprintf("must be at least %s, got %s", failure.Param(), got)
+	case "lte":
+		return fmt.Sprintf("must be at most %s, got %s", failure.Param(), got)
+	case "gtefield":
+		namespace := strings.TrimSuffix(failure.StructNamespace(), failure.StructField()) + failure.Param()
+		return fmt.Sprintf("must be at least %s, got %s", names[namespace].path, got)
+	case "loglevel":
+		return fmt.Sprintf("must be debug, info, warn or error, got %q", got)
+	default:
+		return fmt.Sprintf("fails %s, got %q", failure.Tag(), got)
+	}
+}
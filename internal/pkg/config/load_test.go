@@ -0,0 +1,101 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"loan_system/internal/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLoanRules(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := config.Load(config.Sources{Environ: []string{signingKey}})
+		require.NoError(t, err)
+		assert.Equal(t, config.Fees{}, cfg.Fees)
+		assert.Equal(t, config.Funding{Window: 720 * time.Hour, MinInvestment: 1, MaxInvestment: 1e9}, cfg.Funding)
+		assert.Empty(t, cfg.Products.Catalog)
+	})
+
+	t.Run("file and environment", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", `
+fees:
+  origination_rate: 0.02
+  min_origination_fee: 5000
+funding:
+  window: 336h
+products:
+  catalog: "micro:100000-5000000:3-12, group:1000000-50000000:6-24"
+`)
+		cfg, err := config.Load(config.Sources{
+			File:    file,
+			Environ: []string{signingKey, "FEES_LATE_PAYMENT_RATE=0.01", "FUNDING_MIN_INVESTMENT=50000"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, config.Fees{OriginationRate: 0.02, MinOriginationFee: 5000, LatePaymentRate: 0.01}, cfg.Fees)
+		assert.Equal(t, 336*time.Hour, cfg.Funding.Window)
+		assert.Equal(t, 50000.0, cfg.Funding.MinInvestment)
+		assert.Equal(t, config.Catalog{
+			{Name: "micro", MinPrincipal: 100000, MaxPrincipal: 5000000, MinTenorMonths: 3, MaxTenorMonths: 12},
+			{Name: "group", MinPrincipal: 1000000, MaxPrincipal: 50000000, MinTenorMonths: 6, MaxTenorMonths: 24},
+		}, cfg.Products.Catalog)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			environ []string
+			want    []string
+		}{
+			{
+				name:    "fees",
+				environ: []string{"FEES_ORIGINATION_RATE=1.5", "FEES_MIN_ORIGINATION_FEE=-1", "FEES_LATE_PAYMENT_RATE=some"},
+				want: []string{
+					"fees.origination_rate (FEES_ORIGINATION_RATE): must be at most 1, got 1.5",
+					"fees.min_origination_fee (FEES_MIN_ORIGINATION_FEE): must be at least 0, got -1",
+					`fees.late_payment_rate (FEES_LATE_PAYMENT_RATE): invalid number "some"`,
+				},
+			},
+			{
+				name:    "funding",
+				environ: []string{"FUNDING_WINDOW=0s", "FUNDING_MIN_INVESTMENT=100", "FUNDING_MAX_INVESTMENT=10"},
+				want: []string{
+					"funding.window (FUNDING_WINDOW): must be greater than 0, got 0s",
+					"funding.max_investment (FUNDING_MAX_INVESTMENT): must be at least funding.min_investment, got 10",
+				},
+			},
+			{
+				name:    "malformed product",
+				environ: []string{"PRODUCTS_CATALOG=micro:100000:3-12"},
+				want:    []string{`products.catalog (PRODUCTS_CATALOG): invalid product "micro:100000:3-12"`},
+			},
+			{
+				name:    "principal bounds",
+				environ: []string{"PRODUCTS_CATALOG=micro:5000-100:3-12"},
+				want:    []string{`products.catalog (PRODUCTS_CATALOG): product "micro": principal must be positive`},
+			},
+			{
+				name:    "tenor bounds",
+				environ: []string{"PRODUCTS_CATALOG=micro:100-5000:0-12"},
+				want:    []string{`products.catalog (PRODUCTS_CATALOG): product "micro": tenor must be positive`},
+			},
+			{
+				name:    "repeated product",
+				environ: []string{"PRODUCTS_CATALOG=micro:100-5000:3-12,micro:100-9000:3-24"},
+				want:    []string{`products.catalog (PRODUCTS_CATALOG): product "micro" appears twice`},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cfg, err := config.Load(config.Sources{Environ: append([]string{signingKey}, tt.environ...)})
+				assert.Nil(t, cfg)
+				require.Error(t, err)
+				for _, want := range tt.want {
+					assert.Contains(t, err.Error(), want)
+				}
+			})
+		}
+	})
+}
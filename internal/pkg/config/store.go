@@ -0,0 +1,69 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Store holds the configuration being served with, for components to read
+// through Current so they see reloads.
+type Store struct {
+	sources Sources
+	current atomic.Pointer[Config]
+
+	// mu serializes reloads and guards listeners.
+	mu        sync.Mutex
+	listeners []func(*Config)
+}
+
+// NewStore serves cfg, loaded from sources, which Reload reads again.
+func NewStore(cfg *Config, sources Sources) *Store {
+	s := &Store{sources: sources}
+	s.current.Store(cfg)
+	return s
+}
+
+// Current returns the configuration in use. It must not be modified.
+func (s *Store) Current() *Config {
+	return s.current.Load()
+}
+
+// OnReload calls fn with the new configuration after every reload.
+func (s *Store) OnReload(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Reload loads the configuration from its sources again and swaps in the
+// settings tagged reload:"true". Other settings keep their current value
+// until a restart; the ones that changed are returned as "path (ENV)". When
+// the new configuration does not load or validate, nothing changes.
+func (s *Store) Reload() (pending []string, err error) {
+	next, err := Load(s.sources)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.Current()
+	old := settings(current)
+	for i, setting := range settings(next) {
+		if setting.reload {
+			continue
+		}
+		if !reflect.DeepEqual(setting.value.Interface(), old[i].value.Interface()) {
+			pending = append(pending, setting.String())
+			setting.value.Set(old[i].value)
+		}
+	}
+
+	s.current.Store(next)
+	for _, fn := range s.listeners {
+		fn(next)
+	}
+	return pending, nil
+}
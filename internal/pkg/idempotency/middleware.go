@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+type Config struct {
+	Store Store
+	TTL   time.Duration
+	// MaxBodySize caps the body of a request with a key, which is read into
+	// memory to fingerprint it before the handler's own limits apply.
+	// Larger requests get a 413. Zero means no limit.
+	MaxBodySize int64
+	// Scope returns who is making the request so two callers cannot collide
+	// on, or read each other's responses through, the same key.
+	Scope func(c echo.Context) string
+}
+
+// Middleware makes mutating requests that carry an Idempotency-Key safe to
+// retry. The first response is stored and replayed for every repeat with the
+// same key and request; reusing the key for a different request gets a 422.
+// Server errors are not stored, so those requests can be retried.
+func Middleware(cfg Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(HeaderKey)
+			if key == "" || !mutating(req.Method) {
+				return next(c)
+			}
+			if len(key) > 255 {
+				return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			}
+
+			if cfg.MaxBodySize > 0 {
+				req.Body = http.MaxBytesReader(c.Response(), req.Body, cfg.MaxBodySize)
+			}
+			body, err := io.ReadAll(req.Body)
+			if tooLarge := new(http.MaxBytesError); errors.As(err, &tooLarge) {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("a request with an Idempotency-Key may be at most %d bytes", tooLarge.Limit))
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := ""
+			if cfg.Scope != nil {
+				scope = cfg.Scope(c)
+			}
+			storeKey := scope + "\x00" + key
+			fingerprint := fingerprintOf(req, body)
+
+			ctx := req.Context()
+			existing, err := cfg.Store.Reserve(ctx, storeKey, fingerprint, cfg.TTL)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return replay(c, existing, fingerprint)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// handle the error here so the response it produces is recorded too
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				return cfg.Store.Release(ctx, storeKey)
+			}
+			header := c.Response().Header().Clone()
+			header.Del(echo.HeaderXRequestID)
+			return cfg.Store.Complete(ctx, storeKey, &Record{
+				Fingerprint: fingerprint,
+				Status:      status,
+				Header:      header,
+				Body:        recorder.body.Bytes(),
+			})
+		}
+	}
+}
+
+func replay(c echo.Context, record *Record, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	}
+	if record.Pending {
+		return echo.NewHTTPError(http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+	}
+
+	header := c.Response().Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(HeaderReplayed, "true")
+	c.Response().WriteHeader(record.Status)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func fingerprintOf(req *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, req.Method+"\n"+req.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,133 @@
+package idempotency_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"loan_system/internal/pkg/idempotency"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(idempotency.Middleware(idempotency.Config{
+		Store:       idempotency.NewMemoryStore(),
+		TTL:         time.Hour,
+		MaxBodySize: 64,
+		Scope:       func(c echo.Context) string { return c.Request().Header.Get("X-User") },
+	}))
+
+	calls := 0
+	e.POST("/loans", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusOK, map[string]int{"call": calls})
+	})
+	failing := 0
+	e.POST("/failing", func(c echo.Context) error {
+		failing++
+		return echo.NewHTTPError(http.StatusInternalServerError, "boom")
+	})
+	e.POST("/invalid", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid")
+	})
+	e.GET("/loans", func(c echo.Context) error {
+		calls++
+		return c.NoContent(http.StatusOK)
+	})
+
+	do := func(method, path, key, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(idempotency.HeaderKey, key)
+		}
+		req.Header.Set("X-User", user)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("replays the first response", func(t *testing.T) {
+		first := do(http.MethodPost, "/loans", "key-1", "alice", `{"principal":100}`)
+		second := do(http.MethodPost, "/loans", "key-1", "alice", `{"principal":100}`)
+
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(idempotency.HeaderReplayed))
+		assert.Equal(t, echo.MIMEApplicationJSON, second.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("rejects a different body", func(t *testing.T) {
+		rec := do(http.MethodPost, "/loans", "key-1", "alice", `{"principal":200}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("keys are scoped per user", func(t *testing.T) {
+		rec := do(http.MethodPost, "/loans", "key-1", "bob", `{"principal":100}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get(idempotency.HeaderReplayed))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("replays client errors", func(t *testing.T) {
+		do(http.MethodPost, "/invalid", "key-2", "alice", `{}`)
+		rec := do(http.MethodPost, "/invalid", "key-2", "alice", `{}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "true", rec.Header().Get(idempotency.HeaderReplayed))
+	})
+
+	t.Run("does not store server errors", func(t *testing.T) {
+		do(http.MethodPost, "/failing", "key-3", "alice", `{}`)
+		rec := do(http.MethodPost, "/failing", "key-3", "alice", `{}`)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, 2, failing)
+	})
+
+	t.Run("rejects bodies over the limit", func(t *testing.T) {
+		before := calls
+		rec := do(http.MethodPost, "/loans", "key-5", "alice", `{"note":"`+strings.Repeat("x", 64)+`"}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at most 64 bytes")
+		assert.Equal(t, before, calls)
+
+		rec = do(http.MethodPost, "/loans", "key-5", "alice", `{"principal":100}`)
+		assert.Equal(t, http.StatusOK, rec.Code, "the key was not reserved")
+	})
+
+	t.Run("ignores requests without a key and safe methods", func(t *testing.T) {
+		before := calls
+		do(http.MethodPost, "/loans", "", "alice", `{"principal":100}`)
+		do(http.MethodPost, "/loans", "", "alice", `{"principal":100}`)
+		do(http.MethodGet, "/loans", "key-4", "alice", "")
+		do(http.MethodGet, "/loans", "key-4", "alice", "")
+		assert.Equal(t, before+4, calls)
+	})
+}
+
+func TestMiddlewarePending(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	e := echo.New()
+	e.Use(idempotency.Middleware(idempotency.Config{Store: store, TTL: time.Hour}))
+	e.POST("/loans/:id/invest", func(c echo.Context) error {
+		// a retry arriving while the first request is still running
+		req := httptest.NewRequest(http.MethodPost, "/loans/1/invest", strings.NewReader(`{}`))
+		req.Header.Set(idempotency.HeaderKey, "key")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return c.String(http.StatusOK, rec.Result().Status)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/loans/1/invest", strings.NewReader(`{}`))
+	req.Header.Set(idempotency.HeaderKey, "key")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "409 Conflict", rec.Body.String())
+}
@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Record is the stored outcome of the first request made with a key. It is
+// pending until that request has finished.
+type Record struct {
+	Fingerprint string
+	Pending     bool
+	Status      int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+//go:generate mockgen -source=store.go -destination=mock/store_mock.go -package=mock
+type Store interface {
+	// Reserve claims key for a new request and returns nil. When the key is
+	// already taken it returns the existing record instead.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+	// Complete stores the response of the request that reserved key.
+	Complete(ctx context.Context, key string, record *Record) error
+	// Release frees key so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*Record
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{records: make(map[string]*Record), now: time.Now}
+}
+
+func (s *memoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now, ttl)
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		existing := *record
+		return &existing, nil
+	}
+
+	s.records[key] = &Record{Fingerprint: fingerprint, Pending: true, ExpiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, key string, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reserved, ok := s.records[key]
+	if !ok {
+		return errors.New("idempotency key not reserved")
+	}
+
+	completed := *record
+	completed.Pending = false
+	completed.ExpiresAt = reserved.ExpiresAt
+	s.records[key] = &completed
+	return nil
+}
+
+func (s *memoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops expired records, at most once per ttl.
+func (s *memoryStore) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(s.lastSweep) < ttl {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memoryStore{records: make(map[string]*Record), now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	existing, err := store.Reserve(ctx, "k", "fp", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+
+	existing, err = store.Reserve(ctx, "k", "fp", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, existing.Pending)
+
+	assert.NoError(t, store.Complete(ctx, "k", &Record{Fingerprint: "fp", Status: 201, Body: []byte("ok")}))
+	existing, err = store.Reserve(ctx, "k", "fp", time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, existing.Pending)
+	assert.Equal(t, 201, existing.Status)
+	assert.Equal(t, now.Add(time.Hour), existing.ExpiresAt)
+
+	now = now.Add(2 * time.Hour)
+	existing, err = store.Reserve(ctx, "k", "other", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, existing, "expired records are replaced")
+
+	assert.NoError(t, store.Release(ctx, "k"))
+	assert.Empty(t, store.records)
+
+	assert.EqualError(t, store.Complete(ctx, "missing", &Record{}), "idempotency key not reserved")
+}
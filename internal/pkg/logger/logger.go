@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"loan_system/internal/pkg/config"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// New builds the root logger. Every line it writes carries the attributes
+// attached to the context passed to the *Context logging methods. level is
+// set to cfg.Level and changes which lines are written when set again.
+func New(w io.Writer, cfg config.Log, level *slog.LevelVar) (*slog.Logger, error) {
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch cfg.Format {
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	case "text":
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Discard returns a logger that drops everything, for tests and tools.
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+type attrsKey struct{}
+
+// WithAttrs returns a context whose log lines carry attrs on top of the ones
+// already attached, such as the request ID, loan ID, actor and operation.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(existing)+len(attrs))
+	merged = append(merged, existing...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// RequestID reuses the X-Request-ID sent by the caller or generates one,
+// echoes it in the response and attaches it to the request context.
+func RequestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(WithAttrs(req.Context(), slog.String("request_id", id))))
+		},
+	})
+}
+
+// Requests logs one line per handled request.
+func Requests(log *slog.Logger) echo.MiddlewareFunc {
+	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogMethod:    true,
+		LogURI:       true,
+		LogRoutePath: true,
+		LogStatus:    true,
+		LogLatency:   true,
+		LogError:     true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			status := v.Status
+			level := slog.LevelInfo
+			attrs := []slog.Attr{
+				slog.String("method", v.Method),
+				slog.String("uri", redactURI(v.URI)),
+				slog.String("route", v.RoutePath),
+				slog.Duration("latency", v.Latency),
+			}
+			if v.Error != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(v.Error, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+				attrs = append(attrs, slog.String("error", v.Error.Error()))
+			}
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			attrs = append(attrs, slog.Int("status", status))
+
+			log.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		},
+	})
+}
+
+// redactURI hides the access_token query parameter, which carries a token
+// for browsers opening event streams.
+func redactURI(uri string) string {
+	parsed, err := url.ParseRequestURI(uri)
+	if err != nil || !parsed.Query().Has("access_token") {
+		return uri
+	}
+
+	query := parsed.Query()
+	query.Set("access_token", "REDACTED")
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// Actor identifies who performs an operation.
+func Actor(role string, id int64) slog.Attr {
+	return slog.Group("actor", slog.String("role", role), slog.Int64("id", id))
+}
@@ -0,0 +1,130 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]any{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Log
+		wantErr string
+	}{
+		{name: "json", cfg: config.Log{Level: "info", Format: "json"}},
+		{name: "text", cfg: config.Log{Level: "debug", Format: "text"}},
+		{name: "invalid level", cfg: config.Log{Level: "loud", Format: "json"}, wantErr: `invalid log level "loud"`},
+		{name: "invalid format", cfg: config.Log{Level: "info", Format: "xml"}, wantErr: `invalid log format "xml"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logger.New(&bytes.Buffer{}, tt.cfg, new(slog.LevelVar))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("level filters", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		level := new(slog.LevelVar)
+		log, err := logger.New(buf, config.Log{Level: "warn", Format: "json"}, level)
+		assert.NoError(t, err)
+
+		log.Info("hidden")
+		log.Warn("shown")
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), "shown")
+
+		level.Set(slog.LevelInfo)
+		log.Info("shown after the level changed")
+		assert.Contains(t, buf.String(), "shown after the level changed")
+	})
+}
+
+func TestWithAttrs(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log, err := logger.New(buf, config.Log{Level: "info", Format: "json"}, new(slog.LevelVar))
+	assert.NoError(t, err)
+
+	ctx := logger.WithAttrs(context.Background(), slog.String("request_id", "abc"))
+	ctx = logger.WithAttrs(ctx, slog.Int64("loan_id", 7), logger.Actor("investor", 9))
+	log.With("component", "test").InfoContext(ctx, "hello")
+	log.Info("without context")
+
+	entries := lines(t, buf)
+	assert.Equal(t, "abc", entries[0]["request_id"])
+	assert.Equal(t, float64(7), entries[0]["loan_id"])
+	assert.Equal(t, map[string]any{"role": "investor", "id": float64(9)}, entries[0]["actor"])
+	assert.Equal(t, "test", entries[0]["component"])
+	assert.NotContains(t, entries[1], "request_id")
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log, err := logger.New(buf, config.Log{Level: "info", Format: "json"}, new(slog.LevelVar))
+	assert.NoError(t, err)
+
+	e := echo.New()
+	e.Use(logger.RequestID())
+	e.Use(logger.Requests(log))
+	e.GET("/loans/:id", func(c echo.Context) error {
+		log.InfoContext(c.Request().Context(), "inside handler")
+		return echo.NewHTTPError(http.StatusNotFound, "loan not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/loans/1", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-1", rec.Header().Get(echo.HeaderXRequestID))
+
+	entries := lines(t, buf)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "inside handler", entries[0]["msg"])
+	assert.Equal(t, "req-1", entries[0]["request_id"])
+	assert.Equal(t, "request", entries[1]["msg"])
+	assert.Equal(t, "req-1", entries[1]["request_id"])
+	assert.Equal(t, "/loans/:id", entries[1]["route"])
+	assert.Equal(t, float64(http.StatusNotFound), entries[1]["status"])
+
+	t.Run("redacts stream tokens", func(t *testing.T) {
+		buf.Reset()
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/loans/1?access_token=secret&last_event_id=4", nil))
+
+		entries := lines(t, buf)
+		assert.Equal(t, "/loans/1?access_token=REDACTED&last_event_id=4", entries[len(entries)-1]["uri"])
+	})
+
+	t.Run("generates an ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/loans/1", nil))
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderXRequestID))
+	})
+}
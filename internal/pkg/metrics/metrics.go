@@ -0,0 +1,204 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"loan_system/internal/model"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "loan_system"
+
+// Metrics is what the usecases and the publisher report to. It is kept small
+// so tests can pass NewNoop() or a mock instead of a real registry.
+//
+//go:generate mockgen -source=metrics.go -destination=mock/metrics_mock.go -package=mock
+type Metrics interface {
+	ObserveOperation(operation string, err error)
+	PublishFailed(topic string)
+}
+
+type noop struct{}
+
+func NewNoop() Metrics {
+	return noop{}
+}
+
+func (noop) ObserveOperation(string, error) {}
+func (noop) PublishFailed(string)           {}
+
+// LoanStatsFunc reports the current loan book, e.g. a stats usecase's LoanStats over all time.
+type LoanStatsFunc func(ctx context.Context) (*model.LoanStats, error)
+
+type Prometheus struct {
+	registry        *prometheus.Registry
+	requestDuration *prometheus.HistogramVec
+	operations      *prometheus.CounterVec
+	operationErrors *prometheus.CounterVec
+	publishFailures *prometheus.CounterVec
+}
+
+func NewPrometheus() *Prometheus {
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "usecase_operations_total",
+			Help:      "Usecase operations by name.",
+		}, []string{"operation"}),
+		operationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "usecase_operation_errors_total",
+			Help:      "Usecase operations that returned an error, by name.",
+		}, []string{"operation"}),
+		publishFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pubsub_publish_failures_total",
+			Help:      "Failed publishes by topic.",
+		}, []string{"topic"}),
+	}
+
+	p.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		p.requestDuration,
+		p.operations,
+		p.operationErrors,
+		p.publishFailures,
+	)
+
+	return p
+}
+
+func (p *Prometheus) ObserveOperation(operation string, err error) {
+	p.operations.WithLabelValues(operation).Inc()
+	if err != nil {
+		p.operationErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func (p *Prometheus) PublishFailed(topic string) {
+	p.publishFailures.WithLabelValues(topic).Inc()
+}
+
+// RegisterLoanBook exposes gauges for the loan book, computed from stats at
+// most once per ttl however often it is scraped.
+func (p *Prometheus) RegisterLoanBook(stats LoanStatsFunc, ttl time.Duration) {
+	p.registry.MustRegister(newLoanBookCollector(stats, ttl))
+}
+
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the latency of every request under its route template,
+// so /loans/1 and /loans/2 share a series.
+func (p *Prometheus) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				var httpErr *echo.HTTPError
+				if errors.As(err, &httpErr) {
+					status = httpErr.Code
+				} else {
+					status = http.StatusInternalServerError
+				}
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = "unmatched"
+			}
+
+			p.requestDuration.
+				WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).
+				Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+type loanBookCollector struct {
+	stats     LoanStatsFunc
+	ttl       time.Duration
+	loans     *prometheus.Desc
+	principal *prometheus.Desc
+	funded    *prometheus.Desc
+
+	// mu guards the cached stats, and makes concurrent scrapes of expired
+	// stats wait for one computation instead of starting their own.
+	mu        sync.Mutex
+	cached    *model.LoanStats
+	expiresAt time.Time
+}
+
+func newLoanBookCollector(stats LoanStatsFunc, ttl time.Duration) *loanBookCollector {
+	return &loanBookCollector{
+		stats:     stats,
+		ttl:       ttl,
+		loans:     prometheus.NewDesc(namespace+"_loans", "Loans by state.", []string{"state"}, nil),
+		principal: prometheus.NewDesc(namespace+"_loan_principal", "Principal of loans by state.", []string{"state"}, nil),
+		funded:    prometheus.NewDesc(namespace+"_funded_principal", "Principal invested across all loans.", nil, nil),
+	}
+}
+
+func (c *loanBookCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.loans
+	ch <- c.principal
+	ch <- c.funded
+}
+
+func (c *loanBookCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.current()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.loans, err)
+		return
+	}
+
+	for _, state := range []model.LoanState{model.StateProposed, model.StateApproved, model.StateInvested, model.StateDisbursed} {
+		byState := stats.Total.ByState[state]
+		ch <- prometheus.MustNewConstMetric(c.loans, prometheus.GaugeValue, float64(byState.Count), string(state))
+		ch <- prometheus.MustNewConstMetric(c.principal, prometheus.GaugeValue, byState.Principal, string(state))
+	}
+	ch <- prometheus.MustNewConstMetric(c.funded, prometheus.GaugeValue, stats.Total.TotalFunded)
+}
+
+// current returns the cached stats, computing them again once they expire.
+// Errors are not cached, so the next scrape tries again.
+func (c *loanBookCollector) current() (*model.LoanStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil && time.Now().Before(c.expiresAt) {
+		return c.cached, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := c.stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.cached, c.expiresAt = stats, time.Now().Add(c.ttl)
+	return stats, nil
+}
@@ -0,0 +1,102 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/metrics"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, p *metrics.Prometheus) string {
+	rec := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestPrometheus(t *testing.T) {
+	p := metrics.NewPrometheus()
+
+	t.Run("middleware", func(t *testing.T) {
+		e := echo.New()
+		e.Use(p.Middleware())
+		e.GET("/loans/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		e.PUT("/loans/:id/approve", func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusBadRequest, "bad")
+		})
+
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodGet, "/loans/1", nil),
+			httptest.NewRequest(http.MethodGet, "/loans/2", nil),
+			httptest.NewRequest(http.MethodPut, "/loans/1/approve", nil),
+		} {
+			e.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		body := scrape(t, p)
+		assert.Contains(t, body, `loan_system_http_request_duration_seconds_count{method="GET",route="/loans/:id",status="200"} 2`)
+		assert.Contains(t, body, `loan_system_http_request_duration_seconds_count{method="PUT",route="/loans/:id/approve",status="400"} 1`)
+	})
+
+	t.Run("operations and publish failures", func(t *testing.T) {
+		p.ObserveOperation("approve_loan", nil)
+		p.ObserveOperation("approve_loan", errors.New("boom"))
+		p.PublishFailed("loan_invested")
+
+		body := scrape(t, p)
+		assert.Contains(t, body, `loan_system_usecase_operations_total{operation="approve_loan"} 2`)
+		assert.Contains(t, body, `loan_system_usecase_operation_errors_total{operation="approve_loan"} 1`)
+		assert.Contains(t, body, `loan_system_pubsub_publish_failures_total{topic="loan_invested"} 1`)
+	})
+
+	t.Run("loan book", func(t *testing.T) {
+		calls := 0
+		p.RegisterLoanBook(func(ctx context.Context) (*model.LoanStats, error) {
+			calls++
+			return model.NewLoanStats([]*model.Loan{
+				{ID: 1, Principal: 1000, State: model.StateApproved, Investments: []model.Investment{{Amount: 400}}},
+				{ID: 2, Principal: 500, State: model.StateProposed},
+			}, time.Time{}, time.Time{}, ""), nil
+		}, time.Hour)
+
+		body := scrape(t, p)
+		assert.Contains(t, body, `loan_system_loans{state="APPROVED"} 1`)
+		assert.Contains(t, body, `loan_system_loans{state="DISBURSED"} 0`)
+		assert.Contains(t, body, `loan_system_loan_principal{state="PROPOSED"} 500`)
+		assert.Contains(t, body, `loan_system_funded_principal 400`)
+
+		scrape(t, p)
+		assert.Equal(t, 1, calls, "the stats are cached between scrapes")
+	})
+
+	t.Run("loan book expires and errors are not cached", func(t *testing.T) {
+		p := metrics.NewPrometheus()
+		calls := 0
+		p.RegisterLoanBook(func(ctx context.Context) (*model.LoanStats, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("database is locked")
+			}
+			return model.NewLoanStats([]*model.Loan{{ID: 1, Principal: 700, State: model.StateProposed}}, time.Time{}, time.Time{}, ""), nil
+		}, time.Nanosecond)
+
+		rec := httptest.NewRecorder()
+		p.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+		assert.Contains(t, scrape(t, p), `loan_system_loan_principal{state="PROPOSED"} 700`)
+		scrape(t, p)
+		assert.Equal(t, 3, calls)
+	})
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: metrics.go
+//
+// Generated by this command:
+//
+//	mockgen -source=metrics.go -destination=mock/metrics_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMetrics is a mock of Metrics interface.
+type MockMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsMockRecorder
+	isgomock struct{}
+}
+
+// MockMetricsMockRecorder is the mock recorder for MockMetrics.
+type MockMetricsMockRecorder struct {
+	mock *MockMetrics
+}
+
+// NewMockMetrics creates a new mock instance.
+func NewMockMetrics(ctrl *gomock.Controller) *MockMetrics {
+	mock := &MockMetrics{ctrl: ctrl}
+	mock.recorder = &MockMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
+	return m.recorder
+}
+
+// ObserveOperation mocks base method.
+func (m *MockMetrics) ObserveOperation(operation string, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ObserveOperation", operation, err)
+}
+
+// ObserveOperation indicates an expected call of ObserveOperation.
+func (mr *MockMetricsMockRecorder) ObserveOperation(operation, err any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ObserveOperation", reflect.TypeOf((*MockMetrics)(nil).ObserveOperation), operation, err)
+}
+
+// PublishFailed mocks base method.
+func (m *MockMetrics) PublishFailed(topic string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PublishFailed", topic)
+}
+
+// PublishFailed indicates an expected call of PublishFailed.
+func (mr *MockMetricsMockRecorder) PublishFailed(topic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishFailed", reflect.TypeOf((*MockMetrics)(nil).PublishFailed), topic)
+}
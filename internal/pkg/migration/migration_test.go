@@ -0,0 +1,75 @@
+package migration_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/migration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrator(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	migrator, err := migration.NewMigrator(db)
+	assert.NoError(t, err)
+
+	t.Run("EnsureLatest on empty database", func(t *testing.T) {
+		assert.ErrorContains(t, migrator.EnsureLatest(context.TODO()), "pending")
+	})
+
+	t.Run("Up", func(t *testing.T) {
+		applied, err := migrator.Up(context.TODO())
+		assert.NoError(t, err)
+		assert.NotEmpty(t, applied)
+
+		version, err := migrator.Version(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, migrator.Latest(), version)
+		assert.NoError(t, migrator.EnsureLatest(context.TODO()))
+
+		_, err = db.Exec(`INSERT INTO loans (id, borrower_id, principal, rate, roi, state) VALUES (1, 1, 1000, 0.1, 0.1, 'PROPOSED')`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Up is idempotent", func(t *testing.T) {
+		applied, err := migrator.Up(context.TODO())
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		statuses, err := migrator.Status(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, statuses[0].Version)
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt)
+		}
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		latest := migrator.Latest()
+		rolledBack, err := migrator.Down(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, rolledBack, 1)
+		assert.Equal(t, latest, rolledBack[0].Version)
+		assert.ErrorContains(t, migrator.EnsureLatest(context.TODO()), "pending")
+	})
+
+	t.Run("Down everything", func(t *testing.T) {
+		_, err := migrator.Down(context.TODO(), migrator.Latest())
+		assert.NoError(t, err)
+
+		version, err := migrator.Version(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, version)
+
+		_, err = db.Exec(`SELECT 1 FROM loans`)
+		assert.ErrorContains(t, err, "no such table")
+	})
+}
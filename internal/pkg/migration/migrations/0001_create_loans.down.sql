@@ -0,0 +1,5 @@
+DROP TABLE disbursements;
+DROP INDEX investments_loan_id;
+DROP TABLE investments;
+DROP TABLE approvals;
+DROP TABLE loans;
@@ -0,0 +1,32 @@
+CREATE TABLE loans (
+    id             INTEGER PRIMARY KEY,
+    borrower_id    INTEGER NOT NULL,
+    principal      REAL    NOT NULL,
+    rate           REAL    NOT NULL,
+    roi            REAL    NOT NULL,
+    state          TEXT    NOT NULL,
+    agreement_link TEXT    NOT NULL DEFAULT ''
+);
+
+CREATE TABLE approvals (
+    loan_id           INTEGER PRIMARY KEY REFERENCES loans (id) ON DELETE CASCADE,
+    validator_id      INTEGER  NOT NULL,
+    proof_document_id INTEGER  NOT NULL,
+    approved_at       DATETIME NOT NULL
+);
+
+CREATE TABLE investments (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    loan_id     INTEGER NOT NULL REFERENCES loans (id) ON DELETE CASCADE,
+    investor_id INTEGER NOT NULL,
+    amount      REAL    NOT NULL
+);
+
+CREATE INDEX investments_loan_id ON investments (loan_id);
+
+CREATE TABLE disbursements (
+    loan_id               INTEGER PRIMARY KEY REFERENCES loans (id) ON DELETE CASCADE,
+    officer_id            INTEGER  NOT NULL,
+    agreement_document_id INTEGER  NOT NULL,
+    disbursed_at          DATETIME NOT NULL
+);
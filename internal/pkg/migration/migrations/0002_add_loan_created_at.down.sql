@@ -0,0 +1,7 @@
+DROP INDEX investments_investor_id;
+DROP INDEX loans_borrower_id;
+DROP INDEX loans_state;
+DROP INDEX loans_principal;
+DROP INDEX loans_created_at;
+
+ALTER TABLE loans DROP COLUMN created_at;
@@ -0,0 +1,7 @@
+ALTER TABLE loans ADD COLUMN created_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+
+CREATE INDEX loans_created_at ON loans (created_at);
+CREATE INDEX loans_principal ON loans (principal);
+CREATE INDEX loans_state ON loans (state);
+CREATE INDEX loans_borrower_id ON loans (borrower_id);
+CREATE INDEX investments_investor_id ON investments (investor_id);
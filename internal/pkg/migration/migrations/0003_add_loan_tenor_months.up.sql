@@ -0,0 +1 @@
+ALTER TABLE loans ADD COLUMN tenor_months INTEGER NOT NULL DEFAULT 12;
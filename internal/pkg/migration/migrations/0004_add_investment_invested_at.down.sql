@@ -0,0 +1 @@
+ALTER TABLE investments DROP COLUMN invested_at;
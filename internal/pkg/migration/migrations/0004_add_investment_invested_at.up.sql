@@ -0,0 +1 @@
+ALTER TABLE investments ADD COLUMN invested_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
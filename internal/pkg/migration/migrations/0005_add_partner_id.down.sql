@@ -0,0 +1,2 @@
+ALTER TABLE investments DROP COLUMN partner_id;
+ALTER TABLE loans DROP COLUMN partner_id;
@@ -0,0 +1,2 @@
+ALTER TABLE loans ADD COLUMN partner_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE investments ADD COLUMN partner_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+CREATE TABLE documents (
+    id           INTEGER PRIMARY KEY,
+    loan_id      INTEGER  NOT NULL,
+    kind         TEXT     NOT NULL,
+    file_name    TEXT     NOT NULL,
+    content_type TEXT     NOT NULL,
+    size         INTEGER  NOT NULL,
+    sha256       TEXT     NOT NULL,
+    blob_key     TEXT     NOT NULL,
+    uploaded_at  DATETIME NOT NULL
+);
+
+CREATE INDEX documents_loan_id ON documents (loan_id);
@@ -0,0 +1,16 @@
+CREATE TABLE partners (
+    id         INTEGER PRIMARY KEY,
+    name       TEXT     NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE partner_keys (
+    id         TEXT PRIMARY KEY,
+    partner_id INTEGER  NOT NULL REFERENCES partners (id) ON DELETE CASCADE,
+    secret     TEXT     NOT NULL,
+    scopes     TEXT     NOT NULL,
+    created_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
+
+CREATE INDEX partner_keys_partner_id ON partner_keys (partner_id);
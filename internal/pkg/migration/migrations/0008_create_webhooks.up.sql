@@ -0,0 +1,30 @@
+CREATE TABLE webhook_subscriptions (
+    id          INTEGER PRIMARY KEY,
+    partner_id  INTEGER  NOT NULL,
+    url         TEXT     NOT NULL,
+    event_types TEXT     NOT NULL,
+    secret      TEXT     NOT NULL,
+    created_at  DATETIME NOT NULL
+);
+
+CREATE INDEX webhook_subscriptions_partner_id ON webhook_subscriptions (partner_id);
+
+-- deliveries outlive their subscription, so its delivery log can still be read
+CREATE TABLE webhook_deliveries (
+    id              INTEGER PRIMARY KEY,
+    subscription_id INTEGER  NOT NULL,
+    partner_id      INTEGER  NOT NULL,
+    event_id        INTEGER  NOT NULL,
+    event_type      TEXT     NOT NULL,
+    payload         BLOB,
+    status          TEXT     NOT NULL,
+    attempt_count   INTEGER  NOT NULL,
+    attempts        TEXT     NOT NULL,
+    next_attempt_at DATETIME,
+    delivered_at    DATETIME,
+    created_at      DATETIME NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_partner_id ON webhook_deliveries (partner_id);
+CREATE INDEX webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id);
+CREATE INDEX webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
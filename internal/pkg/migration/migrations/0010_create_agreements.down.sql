@@ -0,0 +1,2 @@
+DROP TABLE agreement_documents;
+DROP TABLE agreements;
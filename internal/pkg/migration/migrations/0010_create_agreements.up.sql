@@ -0,0 +1,20 @@
+CREATE TABLE agreements (
+    loan_id         INTEGER  NOT NULL,
+    investor_id     INTEGER  NOT NULL,
+    amount          REAL     NOT NULL,
+    expected_return REAL     NOT NULL,
+    generated_at    DATETIME NOT NULL,
+    PRIMARY KEY (loan_id, investor_id)
+);
+
+CREATE TABLE agreement_documents (
+    loan_id      INTEGER NOT NULL,
+    investor_id  INTEGER NOT NULL,
+    format       TEXT    NOT NULL,
+    content_type TEXT    NOT NULL,
+    content_hash TEXT    NOT NULL,
+    size         INTEGER NOT NULL,
+    blob_key     TEXT    NOT NULL,
+    PRIMARY KEY (loan_id, investor_id, format),
+    FOREIGN KEY (loan_id, investor_id) REFERENCES agreements (loan_id, investor_id) ON DELETE CASCADE
+);
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"loan_system/internal/pkg/auth"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+)
+
+type Config struct {
+	Store Store
+	Limit Limit
+	// LimitFunc, when set, is called for every request instead of using
+	// Limit, so the limit can change while serving.
+	LimitFunc func() Limit
+	// Name separates the buckets of route groups sharing a store.
+	Name string
+	// Key returns who the request is counted against. Defaults to ClientKey.
+	Key func(c echo.Context) string
+}
+
+// Middleware counts requests per client against cfg.Limit and rejects them
+// with 429 once the client's bucket is empty. Every response carries the
+// RateLimit-* headers; rejections also carry Retry-After.
+func Middleware(cfg Config) echo.MiddlewareFunc {
+	if cfg.Key == nil {
+		cfg.Key = ClientKey
+	}
+	limitFunc := cfg.LimitFunc
+	if limitFunc == nil {
+		limitFunc = func() Limit { return cfg.Limit }
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if cfg.LimitFunc == nil && !cfg.Limit.Enabled() {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			limit := limitFunc()
+			if !limit.Enabled() {
+				return next(c)
+			}
+
+			result, err := cfg.Store.Take(c.Request().Context(), cfg.Name+"\x00"+cfg.Key(c), limit)
+			if err != nil {
+				return err
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderLimit, strconv.Itoa(limit.Burst))
+			header.Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderReset, ceilSeconds(result.Reset))
+
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded, retry in "+ceilSeconds(result.RetryAfter)+"s")
+			}
+			return next(c)
+		}
+	}
+}
+
+// ClientKey identifies the caller by the partner API key it signed with, its
+// authenticated user, or else its IP address.
+func ClientKey(c echo.Context) string {
+	if key := c.Request().Header.Get(auth.HeaderPartnerKey); key != "" {
+		if principal, ok := auth.FromContext(c.Request().Context()); ok && principal.Role == auth.RolePartner {
+			return "key:" + key
+		}
+	}
+	if principal, ok := auth.FromContext(c.Request().Context()); ok {
+		return fmt.Sprintf("user:%s:%d", principal.Role, principal.ID)
+	}
+	return IPKey(c)
+}
+
+// IPKey identifies the caller by its IP address only, for limits applied
+// before authentication.
+func IPKey(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,123 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"loan_system/internal/pkg/auth"
+	"loan_system/internal/pkg/ratelimit"
+	"loan_system/internal/pkg/ratelimit/mock"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMiddleware(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit, err := ratelimit.ParseLimit("2/1m")
+	assert.NoError(t, err)
+
+	e := echo.New()
+	authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("X-User") == "7" {
+				c.SetRequest(c.Request().WithContext(auth.WithPrincipal(c.Request().Context(), auth.Principal{ID: 7, Role: auth.RoleInvestor})))
+			}
+			return next(c)
+		}
+	}
+	e.Use(authenticate)
+	ok := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+	e.POST("/loans/:id/invest", ok, ratelimit.Middleware(ratelimit.Config{Store: store, Limit: limit, Name: "invest"}))
+	e.GET("/loans", ok, ratelimit.Middleware(ratelimit.Config{Store: store, Limit: limit, Name: "loans"}))
+	e.GET("/unlimited", ok, ratelimit.Middleware(ratelimit.Config{Store: store, Name: "unlimited"}))
+	var current ratelimit.Limit
+	e.GET("/reloaded", ok, ratelimit.Middleware(ratelimit.Config{Store: store, Name: "reloaded", LimitFunc: func() ratelimit.Limit { return current }}))
+
+	do := func(method, path, user, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-User", user)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rejects once the bucket is empty", func(t *testing.T) {
+		first := do(http.MethodPost, "/loans/1/invest", "7", "10.0.0.1")
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "2", first.Header().Get(ratelimit.HeaderLimit))
+		assert.Equal(t, "1", first.Header().Get(ratelimit.HeaderRemaining))
+		assert.Equal(t, "30", first.Header().Get(ratelimit.HeaderReset))
+
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loans/1/invest", "7", "10.0.0.1").Code)
+
+		// a different IP doesn't help an authenticated user
+		rejected := do(http.MethodPost, "/loans/2/invest", "7", "10.0.0.2")
+		assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+		assert.Equal(t, "30", rejected.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, "0", rejected.Header().Get(ratelimit.HeaderRemaining))
+	})
+
+	t.Run("groups have their own buckets", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/loans", "7", "10.0.0.1").Code)
+	})
+
+	t.Run("anonymous clients are keyed by IP", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loans/1/invest", "", "10.0.0.1").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loans/1/invest", "", "10.0.0.1").Code)
+		assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "/loans/1/invest", "", "10.0.0.1").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodPost, "/loans/1/invest", "", "10.0.0.3").Code)
+	})
+
+	t.Run("zero limit disables limiting", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			rec := do(http.MethodGet, "/unlimited", "7", "10.0.0.1")
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get(ratelimit.HeaderLimit))
+		}
+	})
+
+	t.Run("limit func is read per request", func(t *testing.T) {
+		assert.Empty(t, do(http.MethodGet, "/reloaded", "7", "10.0.0.1").Header().Get(ratelimit.HeaderLimit))
+
+		current = limit
+		first := do(http.MethodGet, "/reloaded", "7", "10.0.0.1")
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "2", first.Header().Get(ratelimit.HeaderLimit))
+	})
+}
+
+func TestMiddlewareStoreError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockStore(ctrl)
+	store.EXPECT().Take(gomock.Any(), gomock.Any(), gomock.Any()).Return(ratelimit.Result{}, errors.New("store down"))
+
+	e := echo.New()
+	handler := ratelimit.Middleware(ratelimit.Config{Store: store, Limit: ratelimit.Limit{Rate: 1, Burst: 1}})(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	err := handler(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder()))
+	assert.EqualError(t, err, "store down")
+}
+
+func TestClientKey(t *testing.T) {
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPost, "/loans", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "ip:10.0.0.1", ratelimit.ClientKey(e.NewContext(req, httptest.NewRecorder())))
+
+	req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: 3, Role: auth.RoleBorrower}))
+	assert.Equal(t, "user:borrower:3", ratelimit.ClientKey(e.NewContext(req, httptest.NewRecorder())))
+
+	req.Header.Set(auth.HeaderPartnerKey, "pk_1")
+	req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{ID: 9, Role: auth.RolePartner}))
+	assert.Equal(t, "key:pk_1", ratelimit.ClientKey(e.NewContext(req, httptest.NewRecorder())))
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "30/1m", want: Limit{Rate: 0.5, Burst: 30}},
+		{in: "10/1s", want: Limit{Rate: 10, Burst: 10}},
+		{in: "", want: Limit{}},
+		{in: "0", want: Limit{}},
+		{in: "30", wantErr: true},
+		{in: "x/1m", wantErr: true},
+		{in: "30/soon", wantErr: true},
+		{in: "30/0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLimit(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLimitUnmarshalText(t *testing.T) {
+	var limit Limit
+	assert.NoError(t, limit.UnmarshalText([]byte("30/1m")))
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 30}, limit)
+	assert.EqualError(t, limit.UnmarshalText([]byte("30")), `invalid rate limit "30", want <requests>/<period>`)
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 30}, limit)
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memoryStore{buckets: make(map[string]*bucket), now: func() time.Time { return now }}
+	limit := Limit{Rate: 1, Burst: 3}
+	ctx := context.Background()
+
+	t.Run("allows the burst then rejects", func(t *testing.T) {
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := store.Take(ctx, "a", limit)
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+
+		result, err := store.Take(ctx, "a", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.Reset)
+	})
+
+	t.Run("keys have their own buckets", func(t *testing.T) {
+		result, err := store.Take(ctx, "b", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		now = now.Add(1500 * time.Millisecond)
+		result, err := store.Take(ctx, "a", limit)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		result, err = store.Take(ctx, "a", limit)
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	})
+
+	t.Run("drops refilled buckets", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		_, err := store.Take(ctx, "c", limit)
+		assert.NoError(t, err)
+		assert.Len(t, store.buckets, 1)
+	})
+}
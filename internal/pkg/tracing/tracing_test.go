@@ -0,0 +1,112 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/tracing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "none"})
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "zipkin"})
+	assert.ErrorContains(t, err, `unknown tracing exporter "zipkin"`)
+
+	shutdown, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "none"})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestEnd(t *testing.T) {
+	recorder := newRecorder(t)
+
+	func() (err error) {
+		_, span := tracing.Start(context.Background(), "failing")
+		defer tracing.End(span, &err)
+		return errors.New("boom")
+	}()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "failing", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+}
+
+func TestInjectExtract(t *testing.T) {
+	newRecorder(t)
+
+	ctx, span := tracing.Start(context.Background(), "producer")
+	defer span.End()
+
+	carrier := map[string]string{}
+	tracing.Inject(ctx, carrier)
+	assert.Contains(t, carrier, "traceparent")
+
+	extracted := trace.SpanContextFromContext(tracing.Extract(context.Background(), carrier))
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := newRecorder(t)
+
+	e := echo.New()
+	e.Use(tracing.Middleware())
+	var handlerSpan trace.SpanContext
+	e.GET("/loans/:id", func(c echo.Context) error {
+		handlerSpan = trace.SpanContextFromContext(c.Request().Context())
+		return echo.NewHTTPError(http.StatusInternalServerError, "boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/loans/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GET /loans/:id", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+}
+
+func TestAttributes(t *testing.T) {
+	kvs := tracing.Attributes(
+		slog.Int64("loan_id", 7),
+		slog.Group("actor", slog.String("role", "investor"), slog.Int64("id", 9)),
+		slog.Float64("amount", 1.5),
+	)
+
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.Int64("loan_id", 7),
+		attribute.String("actor.role", "investor"),
+		attribute.Int64("actor.id", 9),
+		attribute.Float64("amount", 1.5),
+	}, kvs)
+}
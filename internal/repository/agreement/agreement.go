@@ -0,0 +1,76 @@
+package agreement
+
+import (
+	"context"
+	"errors"
+	"loan_system/internal/model"
+	"sync"
+)
+
+//go:generate mockgen -source=agreement.go -destination=mock/agreement_mock.go -package=mock
+type Repository interface {
+	Save(ctx context.Context, agreement *model.Agreement) error
+	FindByLoanID(ctx context.Context, loanID int64) ([]*model.Agreement, error)
+	FindByLoanAndInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error)
+}
+
+// ErrNotFound is returned when no agreement has been generated for the loan
+// and investor, which may still be done.
+var ErrNotFound = errors.New("agreement not found")
+
+type key struct {
+	loanID     int64
+	investorID int64
+}
+
+type repository struct {
+	mu         sync.RWMutex
+	agreements map[key]*model.Agreement
+	byLoan     map[int64][]key
+}
+
+func NewRepository() Repository {
+	return &repository{
+		agreements: make(map[key]*model.Agreement),
+		byLoan:     make(map[int64][]key),
+	}
+}
+
+// Save stores the agreement, replacing any earlier one generated for the same loan and investor.
+func (r *repository) Save(ctx context.Context, agreement *model.Agreement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := key{loanID: agreement.LoanID, investorID: agreement.InvestorID}
+	if _, exists := r.agreements[k]; !exists {
+		r.byLoan[k.loanID] = append(r.byLoan[k.loanID], k)
+	}
+
+	r.agreements[k] = agreement
+	return nil
+}
+
+func (r *repository) FindByLoanID(ctx context.Context, loanID int64) ([]*model.Agreement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := r.byLoan[loanID]
+	agreements := make([]*model.Agreement, 0, len(keys))
+	for _, k := range keys {
+		agreements = append(agreements, r.agreements[k])
+	}
+
+	return agreements, nil
+}
+
+func (r *repository) FindByLoanAndInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	agreement, exists := r.agreements[key{loanID: loanID, investorID: investorID}]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return agreement, nil
+}
@@ -0,0 +1,76 @@
+package agreement_test
+
+import (
+	"context"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/migration"
+	"loan_system/internal/repository/agreement"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	testRepository(t, agreement.NewRepository())
+}
+
+func TestSQLRepository(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migration.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	testRepository(t, agreement.NewSQLRepository(db))
+}
+
+// testRepository holds the behaviour every Repository implementation must share.
+func testRepository(t *testing.T, repo agreement.Repository) {
+	generatedAt := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Save and FindByLoanAndInvestor", func(t *testing.T) {
+		a := &model.Agreement{LoanID: 1, InvestorID: 10, Amount: 500, ExpectedReturn: 50, GeneratedAt: generatedAt, Documents: []model.AgreementDocument{
+			{Format: model.AgreementFormatHTML, ContentType: "text/html", ContentHash: "abc", Size: 10, BlobKey: "agreements/1/10.html"},
+			{Format: model.AgreementFormatPDF, ContentType: "application/pdf", ContentHash: "def", Size: 20, BlobKey: "agreements/1/10.pdf"},
+		}}
+		assert.NoError(t, repo.Save(context.TODO(), a))
+
+		found, err := repo.FindByLoanAndInvestor(context.TODO(), 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, a, found)
+	})
+
+	t.Run("Save replaces existing agreement", func(t *testing.T) {
+		assert.NoError(t, repo.Save(context.TODO(), &model.Agreement{LoanID: 1, InvestorID: 11, Amount: 100, GeneratedAt: generatedAt,
+			Documents: []model.AgreementDocument{{Format: model.AgreementFormatHTML, BlobKey: "old"}}}))
+		assert.NoError(t, repo.Save(context.TODO(), &model.Agreement{LoanID: 1, InvestorID: 11, Amount: 200, GeneratedAt: generatedAt,
+			Documents: []model.AgreementDocument{{Format: model.AgreementFormatHTML, BlobKey: "new"}}}))
+
+		found, err := repo.FindByLoanAndInvestor(context.TODO(), 1, 11)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(200), found.Amount)
+		assert.Equal(t, []model.AgreementDocument{{Format: model.AgreementFormatHTML, BlobKey: "new"}}, found.Documents)
+	})
+
+	t.Run("FindByLoanID", func(t *testing.T) {
+		agreements, err := repo.FindByLoanID(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, agreements, 2)
+
+		agreements, err = repo.FindByLoanID(context.TODO(), 2)
+		assert.NoError(t, err)
+		assert.Len(t, agreements, 0)
+	})
+
+	t.Run("Find non-existent agreement", func(t *testing.T) {
+		_, err := repo.FindByLoanAndInvestor(context.TODO(), 1, 99)
+		assert.ErrorIs(t, err, agreement.ErrNotFound)
+	})
+}
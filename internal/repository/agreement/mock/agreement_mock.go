@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: agreement.go
+//
+// Generated by this command:
+//
+//	mockgen -source=agreement.go -destination=mock/agreement_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "loan_system/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByLoanAndInvestor mocks base method.
+func (m *MockRepository) FindByLoanAndInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByLoanAndInvestor", ctx, loanID, investorID)
+	ret0, _ := ret[0].(*model.Agreement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByLoanAndInvestor indicates an expected call of FindByLoanAndInvestor.
+func (mr *MockRepositoryMockRecorder) FindByLoanAndInvestor(ctx, loanID, investorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByLoanAndInvestor", reflect.TypeOf((*MockRepository)(nil).FindByLoanAndInvestor), ctx, loanID, investorID)
+}
+
+// FindByLoanID mocks base method.
+func (m *MockRepository) FindByLoanID(ctx context.Context, loanID int64) ([]*model.Agreement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByLoanID", ctx, loanID)
+	ret0, _ := ret[0].([]*model.Agreement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByLoanID indicates an expected call of FindByLoanID.
+func (mr *MockRepositoryMockRecorder) FindByLoanID(ctx, loanID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByLoanID", reflect.TypeOf((*MockRepository)(nil).FindByLoanID), ctx, loanID)
+}
+
+// Save mocks base method.
+func (m *MockRepository) Save(ctx context.Context, agreement *model.Agreement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, agreement)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepositoryMockRecorder) Save(ctx, agreement any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepository)(nil).Save), ctx, agreement)
+}
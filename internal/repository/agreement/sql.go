@@ -0,0 +1,120 @@
+package agreement
+
+import (
+	"context"
+	"database/sql"
+	"loan_system/internal/model"
+)
+
+type sqlRepository struct {
+	db *sql.DB
+}
+
+func NewSQLRepository(db *sql.DB) Repository {
+	return &sqlRepository{db: db}
+}
+
+const selectAgreements = `SELECT loan_id, investor_id, amount, expected_return, generated_at FROM agreements`
+
+// Save stores the agreement and its documents in a single transaction,
+// replacing any earlier one generated for the same loan and investor.
+func (r *sqlRepository) Save(ctx context.Context, agreement *model.Agreement) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO agreements (loan_id, investor_id, amount, expected_return, generated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (loan_id, investor_id) DO UPDATE SET amount = excluded.amount, expected_return = excluded.expected_return, generated_at = excluded.generated_at`,
+		agreement.LoanID, agreement.InvestorID, agreement.Amount, agreement.ExpectedReturn, agreement.GeneratedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM agreement_documents WHERE loan_id = ? AND investor_id = ?`, agreement.LoanID, agreement.InvestorID); err != nil {
+		return err
+	}
+	for _, document := range agreement.Documents {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO agreement_documents (loan_id, investor_id, format, content_type, content_hash, size, blob_key) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			agreement.LoanID, agreement.InvestorID, document.Format, document.ContentType, document.ContentHash, document.Size, document.BlobKey,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *sqlRepository) FindByLoanID(ctx context.Context, loanID int64) ([]*model.Agreement, error) {
+	return r.findAgreements(ctx, selectAgreements+` WHERE loan_id = ? ORDER BY investor_id`, loanID)
+}
+
+func (r *sqlRepository) FindByLoanAndInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error) {
+	agreements, err := r.findAgreements(ctx, selectAgreements+` WHERE loan_id = ? AND investor_id = ?`, loanID, investorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(agreements) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return agreements[0], nil
+}
+
+// findAgreements runs a query over the agreements table and attaches the
+// documents of every agreement it returns.
+func (r *sqlRepository) findAgreements(ctx context.Context, query string, args ...any) ([]*model.Agreement, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agreements := make([]*model.Agreement, 0)
+	for rows.Next() {
+		agreement := new(model.Agreement)
+		if err := rows.Scan(&agreement.LoanID, &agreement.InvestorID, &agreement.Amount, &agreement.ExpectedReturn, &agreement.GeneratedAt); err != nil {
+			return nil, err
+		}
+		agreement.GeneratedAt = agreement.GeneratedAt.UTC()
+		agreements = append(agreements, agreement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, agreement := range agreements {
+		if err := r.attachDocuments(ctx, agreement); err != nil {
+			return nil, err
+		}
+	}
+	return agreements, nil
+}
+
+func (r *sqlRepository) attachDocuments(ctx context.Context, agreement *model.Agreement) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT format, content_type, content_hash, size, blob_key FROM agreement_documents WHERE loan_id = ? AND investor_id = ? ORDER BY rowid`,
+		agreement.LoanID, agreement.InvestorID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var document model.AgreementDocument
+		if err := rows.Scan(&document.Format, &document.ContentType, &document.ContentHash, &document.Size, &document.BlobKey); err != nil {
+			return err
+		}
+		agreement.Documents = append(agreement.Documents, document)
+	}
+
+	return rows.Err()
+}
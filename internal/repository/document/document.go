@@ -0,0 +1,81 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"loan_system/internal/model"
+	"log/slog"
+	"sync"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+//go:generate mockgen -source=document.go -destination=mock/document_mock.go -package=mock
+type Repository interface {
+	Save(ctx context.Context, document *model.Document) error
+	FindByID(ctx context.Context, id int64) (*model.Document, error)
+	FindByLoanID(ctx context.Context, loanID int64) ([]*model.Document, error)
+}
+
+type repository struct {
+	mu            sync.RWMutex
+	snowflakeNode *snowflake.Node
+	documents     map[int64]*model.Document
+	byLoan        map[int64][]int64
+}
+
+func NewRepository(log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(2)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &repository{
+		snowflakeNode: node,
+		documents:     make(map[int64]*model.Document),
+		byLoan:        make(map[int64][]int64),
+	}
+}
+
+func (r *repository) Save(ctx context.Context, document *model.Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if document.ID == 0 {
+		document.ID = r.snowflakeNode.Generate().Int64()
+	}
+
+	if _, exists := r.documents[document.ID]; exists {
+		return errors.New("document already exists")
+	}
+
+	r.documents[document.ID] = document
+	r.byLoan[document.LoanID] = append(r.byLoan[document.LoanID], document.ID)
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id int64) (*model.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	document, exists := r.documents[id]
+	if !exists {
+		return nil, errors.New("document not found")
+	}
+
+	return document, nil
+}
+
+func (r *repository) FindByLoanID(ctx context.Context, loanID int64) ([]*model.Document, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.byLoan[loanID]
+	documents := make([]*model.Document, 0, len(ids))
+	for _, id := range ids {
+		documents = append(documents, r.documents[id])
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,90 @@
+package document_test
+
+import (
+	"context"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/migration"
+	"loan_system/internal/repository/document"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	testRepository(t, document.NewRepository(logger.Discard()))
+}
+
+func TestSQLRepository(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migration.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	repo := document.NewSQLRepository(db, logger.Discard())
+	testRepository(t, repo)
+
+	t.Run("survives reopening", func(t *testing.T) {
+		d := &model.Document{LoanID: 3, Kind: model.DocumentKindSignedAgreement, UploadedAt: time.Now().UTC().Truncate(time.Second)}
+		require.NoError(t, repo.Save(context.TODO(), d))
+
+		found, err := document.NewSQLRepository(db, logger.Discard()).FindByID(context.TODO(), d.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, d, found)
+	})
+}
+
+// testRepository holds the behaviour every Repository implementation must share.
+func testRepository(t *testing.T, repo document.Repository) {
+	uploadedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Save and FindByID", func(t *testing.T) {
+		d := &model.Document{
+			LoanID:      1,
+			Kind:        model.DocumentKindApprovalProof,
+			FileName:    "visit.jpg",
+			ContentType: "image/jpeg",
+			Size:        42,
+			SHA256:      "abc",
+			BlobKey:     "documents/1/visit.jpg",
+			UploadedAt:  uploadedAt,
+		}
+		assert.NoError(t, repo.Save(context.TODO(), d))
+		assert.NotZero(t, d.ID)
+
+		found, err := repo.FindByID(context.TODO(), d.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, d, found)
+	})
+
+	t.Run("Save duplicate", func(t *testing.T) {
+		d := &model.Document{LoanID: 1, UploadedAt: uploadedAt.Add(time.Minute)}
+		assert.NoError(t, repo.Save(context.TODO(), d))
+		assert.ErrorContains(t, repo.Save(context.TODO(), d), "document already exists")
+	})
+
+	t.Run("FindByLoanID", func(t *testing.T) {
+		documents, err := repo.FindByLoanID(context.TODO(), 1)
+		assert.NoError(t, err)
+		if assert.Len(t, documents, 2) {
+			assert.Equal(t, "visit.jpg", documents[0].FileName)
+		}
+
+		documents, err = repo.FindByLoanID(context.TODO(), 2)
+		assert.NoError(t, err)
+		assert.Len(t, documents, 0)
+	})
+
+	t.Run("Find non-existent ID", func(t *testing.T) {
+		_, err := repo.FindByID(context.TODO(), 999999)
+		assert.ErrorContains(t, err, "document not found")
+	})
+}
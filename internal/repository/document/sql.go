@@ -0,0 +1,91 @@
+package document
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan_system/internal/model"
+	"log/slog"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+type sqlRepository struct {
+	db            *sql.DB
+	snowflakeNode *snowflake.Node
+}
+
+func NewSQLRepository(db *sql.DB, log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(2)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &sqlRepository{
+		db:            db,
+		snowflakeNode: node,
+	}
+}
+
+const selectDocuments = `SELECT id, loan_id, kind, file_name, content_type, size, sha256, blob_key, uploaded_at FROM documents`
+
+func (r *sqlRepository) Save(ctx context.Context, document *model.Document) error {
+	if document.ID == 0 {
+		document.ID = r.snowflakeNode.Generate().Int64()
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO documents (id, loan_id, kind, file_name, content_type, size, sha256, blob_key, uploaded_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		document.ID, document.LoanID, document.Kind, document.FileName, document.ContentType, document.Size, document.SHA256, document.BlobKey, document.UploadedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return errors.New("document already exists")
+	}
+	return nil
+}
+
+func (r *sqlRepository) FindByID(ctx context.Context, id int64) (*model.Document, error) {
+	documents, err := r.findDocuments(ctx, selectDocuments+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(documents) == 0 {
+		return nil, errors.New("document not found")
+	}
+
+	return documents[0], nil
+}
+
+func (r *sqlRepository) FindByLoanID(ctx context.Context, loanID int64) ([]*model.Document, error) {
+	return r.findDocuments(ctx, selectDocuments+` WHERE loan_id = ? ORDER BY uploaded_at, id`, loanID)
+}
+
+func (r *sqlRepository) findDocuments(ctx context.Context, query string, args ...any) ([]*model.Document, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := make([]*model.Document, 0)
+	for rows.Next() {
+		document := new(model.Document)
+		if err := rows.Scan(&document.ID, &document.LoanID, &document.Kind, &document.FileName, &document.ContentType, &document.Size, &document.SHA256, &document.BlobKey, &document.UploadedAt); err != nil {
+			return nil, err
+		}
+		document.UploadedAt = document.UploadedAt.UTC()
+		documents = append(documents, document)
+	}
+
+	return documents, rows.Err()
+}
@@ -0,0 +1,101 @@
+package importjob
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"loan_system/internal/model"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+//go:generate mockgen -source=importjob.go -destination=mock/importjob_mock.go -package=mock
+type Repository interface {
+	Save(ctx context.Context, job *model.ImportJob) error
+	Update(ctx context.Context, job *model.ImportJob) error
+	FindByID(ctx context.Context, id int64) (*model.ImportJob, error)
+	// DeleteFinishedBefore drops jobs that completed or failed before t.
+	DeleteFinishedBefore(ctx context.Context, t time.Time) error
+}
+
+// repository hands out copies, so a running job can be changed and stored
+// back while others read it.
+type repository struct {
+	mu            sync.RWMutex
+	snowflakeNode *snowflake.Node
+	jobs          map[int64]*model.ImportJob
+}
+
+func NewRepository(log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(6)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &repository{
+		snowflakeNode: node,
+		jobs:          make(map[int64]*model.ImportJob),
+	}
+}
+
+func (r *repository) Save(ctx context.Context, job *model.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job.ID == 0 {
+		job.ID = r.snowflakeNode.Generate().Int64()
+	}
+	if _, exists := r.jobs[job.ID]; exists {
+		return errors.New("import job already exists")
+	}
+
+	r.jobs[job.ID] = clone(job)
+	return nil
+}
+
+func (r *repository) Update(ctx context.Context, job *model.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; !exists {
+		return errors.New("import job not found")
+	}
+
+	r.jobs[job.ID] = clone(job)
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id int64) (*model.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, errors.New("import job not found")
+	}
+
+	return clone(job), nil
+}
+
+func (r *repository) DeleteFinishedBefore(ctx context.Context, t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, job := range r.jobs {
+		if job.CompletedAt != nil && job.CompletedAt.Before(t) {
+			delete(r.jobs, id)
+		}
+	}
+	return nil
+}
+
+func clone(job *model.ImportJob) *model.ImportJob {
+	cloned := *job
+	cloned.Rows = slices.Clone(job.Rows)
+	return &cloned
+}
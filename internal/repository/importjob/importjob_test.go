@@ -0,0 +1,70 @@
+package importjob_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/repository/importjob"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("Save and FindByID", func(t *testing.T) {
+		repo := importjob.NewRepository(logger.Discard())
+		job := &model.ImportJob{Status: model.ImportPending, Rows: []model.ImportRow{{Line: 2, Status: model.ImportRowPending}}}
+		require.NoError(t, repo.Save(ctx, job))
+		assert.NotZero(t, job.ID)
+
+		found, err := repo.FindByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, job, found)
+
+		assert.ErrorContains(t, repo.Save(ctx, job), "import job already exists")
+	})
+
+	t.Run("Update stores a copy", func(t *testing.T) {
+		repo := importjob.NewRepository(logger.Discard())
+		job := &model.ImportJob{Status: model.ImportPending, Rows: []model.ImportRow{{Line: 2, Status: model.ImportRowPending}}}
+		require.NoError(t, repo.Save(ctx, job))
+
+		job.Status = model.ImportRunning
+		job.Rows[0].Status = model.ImportRowCreated
+		require.NoError(t, repo.Update(ctx, job))
+		job.Rows[0].Status = model.ImportRowFailed
+
+		found, err := repo.FindByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ImportRunning, found.Status)
+		assert.Equal(t, model.ImportRowCreated, found.Rows[0].Status)
+
+		assert.ErrorContains(t, repo.Update(ctx, &model.ImportJob{ID: 1}), "import job not found")
+	})
+
+	t.Run("DeleteFinishedBefore", func(t *testing.T) {
+		repo := importjob.NewRepository(logger.Discard())
+		now := time.Now()
+		old, recent := now.Add(-time.Hour), now.Add(-time.Minute)
+		finished := &model.ImportJob{Status: model.ImportCompleted, CompletedAt: &old}
+		fresh := &model.ImportJob{Status: model.ImportCompleted, CompletedAt: &recent}
+		running := &model.ImportJob{Status: model.ImportRunning}
+		for _, job := range []*model.ImportJob{finished, fresh, running} {
+			require.NoError(t, repo.Save(ctx, job))
+		}
+
+		require.NoError(t, repo.DeleteFinishedBefore(ctx, now.Add(-30*time.Minute)))
+
+		_, err := repo.FindByID(ctx, finished.ID)
+		assert.ErrorContains(t, err, "import job not found")
+		_, err = repo.FindByID(ctx, fresh.ID)
+		assert.NoError(t, err)
+		_, err = repo.FindByID(ctx, running.ID)
+		assert.NoError(t, err)
+	})
+}
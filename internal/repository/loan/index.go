@@ -0,0 +1,105 @@
+package loan
+
+import (
+	"loan_system/internal/model"
+)
+
+type idSet map[int64]struct{}
+
+// indexKeys is the snapshot of the indexed fields of a loan at the time it was
+// last written. Callers mutate loans through the pointer they got from FindByID
+// before calling Update, so the previous values have to be kept separately.
+type indexKeys struct {
+	state       model.LoanState
+	borrowerID  int64
+	investorIDs []int64
+}
+
+func keysOf(loan *model.Loan) indexKeys {
+	return indexKeys{
+		state:       loan.State,
+		borrowerID:  loan.BorrowerID,
+		investorIDs: loan.InvestorIDs(),
+	}
+}
+
+// secondaryIndex maps state, borrower and investor to the IDs of their loans.
+// It is not safe for concurrent use; the repository guards it with its own lock.
+type secondaryIndex struct {
+	keys       map[int64]indexKeys
+	byState    map[model.LoanState]idSet
+	byBorrower map[int64]idSet
+	byInvestor map[int64]idSet
+}
+
+func newSecondaryIndex() *secondaryIndex {
+	return &secondaryIndex{
+		keys:       make(map[int64]indexKeys),
+		byState:    make(map[model.LoanState]idSet),
+		byBorrower: make(map[int64]idSet),
+		byInvestor: make(map[int64]idSet),
+	}
+}
+
+func (idx *secondaryIndex) put(loan *model.Loan) {
+	if old, exists := idx.keys[loan.ID]; exists {
+		idx.remove(loan.ID, old)
+	}
+
+	keys := keysOf(loan)
+	idx.keys[loan.ID] = keys
+	add(idx.byState, keys.state, loan.ID)
+	add(idx.byBorrower, keys.borrowerID, loan.ID)
+	for _, investorID := range keys.investorIDs {
+		add(idx.byInvestor, investorID, loan.ID)
+	}
+}
+
+func (idx *secondaryIndex) remove(id int64, keys indexKeys) {
+	del(idx.byState, keys.state, id)
+	del(idx.byBorrower, keys.borrowerID, id)
+	for _, investorID := range keys.investorIDs {
+		del(idx.byInvestor, investorID, id)
+	}
+}
+
+// candidates returns the smallest indexed ID set that covers the query's
+// filters, or false when the query filters on no indexed field.
+func (idx *secondaryIndex) candidates(query model.LoanQuery) (idSet, bool) {
+	var best idSet
+	found := false
+	consider := func(ids idSet) {
+		if !found || len(ids) < len(best) {
+			best, found = ids, true
+		}
+	}
+
+	if query.State != "" {
+		consider(idx.byState[query.State])
+	}
+	if query.BorrowerID != 0 {
+		consider(idx.byBorrower[query.BorrowerID])
+	}
+	if query.InvestorID != 0 {
+		consider(idx.byInvestor[query.InvestorID])
+	}
+
+	return best, found
+}
+
+func add[K comparable](index map[K]idSet, key K, id int64) {
+	ids, ok := index[key]
+	if !ok {
+		ids = make(idSet)
+		index[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func del[K comparable](index map[K]idSet, key K, id int64) {
+	ids := index[key]
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(index, key)
+	}
+}
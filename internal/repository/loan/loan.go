@@ -3,8 +3,9 @@ package loan
 import (
 	"context"
 	"errors"
-	"fmt"
 	"loan_system/internal/model"
+	"log/slog"
+	"sort"
 	"sync"
 
 	"github.com/bwmarrin/snowflake"
@@ -13,27 +14,49 @@ import (
 //go:generate mockgen -source=loan.go -destination=mock/loan_mock.go -package=mock
 type Repository interface {
 	FindAll(ctx context.Context) ([]*model.Loan, error)
+	Find(ctx context.Context, query model.LoanQuery) (model.LoanPage, error)
+	// Each calls fn with every loan the query matches, in its order and
+	// from its cursor, whatever its limit, and stops at the first error fn
+	// returns. Whether loans stored or changed while it runs are seen
+	// depends on the implementation.
+	Each(ctx context.Context, query model.LoanQuery, fn func(*model.Loan) error) error
 	Save(ctx context.Context, loan *model.Loan) error
+	// SaveAll stores every loan or, if any of them cannot be stored, none.
+	SaveAll(ctx context.Context, loans []*model.Loan) error
 	FindByID(ctx context.Context, id int64) (*model.Loan, error)
+	FindByState(ctx context.Context, state model.LoanState) ([]*model.Loan, error)
+	FindByBorrowerID(ctx context.Context, borrowerID int64) ([]*model.Loan, error)
+	FindByInvestorID(ctx context.Context, investorID int64) ([]*model.Loan, error)
+	// Update stores the loan unless another update was stored since it was
+	// read, in which case it fails with ErrConflict. A stored loan's Version
+	// is incremented.
 	Update(ctx context.Context, loan *model.Loan) error
+	// UpdateAll stores every loan or, if any of them cannot be stored, none.
+	UpdateAll(ctx context.Context, loans []*model.Loan) error
 }
 
+// ErrConflict is returned when a loan is updated from a copy that another
+// update has overtaken. The change should be made again to a fresh copy.
+var ErrConflict = errors.New("loan was changed by another request")
+
 type repository struct {
 	mu            sync.RWMutex
 	snowflakeNode *snowflake.Node
 	loans         map[int64]*model.Loan
+	index         *secondaryIndex
 }
 
-func NewRepository() Repository {
+func NewRepository(log *slog.Logger) Repository {
 	node, err := snowflake.NewNode(1)
 	if err != nil {
-		fmt.Println(err)
+		log.Error("create snowflake node failed", "error", err)
 		return nil
 	}
 
 	return &repository{
 		snowflakeNode: node,
 		loans:         make(map[int64]*model.Loan),
+		index:         newSecondaryIndex(),
 	}
 }
 
@@ -49,6 +72,66 @@ func (r *repository) FindAll(ctx context.Context) ([]*model.Loan, error) {
 	return loans, nil
 }
 
+func (r *repository) Find(ctx context.Context, query model.LoanQuery) (model.LoanPage, error) {
+	query = query.Normalized()
+	return page(r.matching(query), query.Limit), nil
+}
+
+// Each visits a snapshot of the matching loans, taken when it is called.
+func (r *repository) Each(ctx context.Context, query model.LoanQuery, fn func(*model.Loan) error) error {
+	for _, loan := range r.matching(query.Normalized()) {
+		if err := fn(loan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matching returns every loan matching the normalized query's filters and
+// cursor, sorted in query order.
+func (r *repository) matching(query model.LoanQuery) []*model.Loan {
+	r.mu.RLock()
+	matched := make([]*model.Loan, 0)
+	include := func(loan *model.Loan) {
+		if !query.Matches(loan) {
+			return
+		}
+		if query.After != nil && !query.Less(*query.After, model.NewLoanCursor(loan)) {
+			return
+		}
+		matched = append(matched, loan)
+	}
+
+	if ids, ok := r.index.candidates(query); ok {
+		for id := range ids {
+			include(r.loans[id])
+		}
+	} else {
+		for _, loan := range r.loans {
+			include(loan)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return query.Less(model.NewLoanCursor(matched[i]), model.NewLoanCursor(matched[j]))
+	})
+	return matched
+}
+
+// page trims loans sorted in query order to limit and derives the cursor for the next page.
+func page(loans []*model.Loan, limit int) model.LoanPage {
+	if len(loans) <= limit {
+		return model.LoanPage{Loans: loans}
+	}
+
+	loans = loans[:limit]
+	return model.LoanPage{
+		Loans:      loans,
+		NextCursor: model.NewLoanCursor(loans[limit-1]).Encode(),
+	}
+}
+
 func (r *repository) Save(ctx context.Context, loan *model.Loan) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -62,6 +145,32 @@ func (r *repository) Save(ctx context.Context, loan *model.Loan) error {
 	}
 
 	r.loans[loan.ID] = loan
+	r.index.put(loan)
+	return nil
+}
+
+func (r *repository) SaveAll(ctx context.Context, loans []*model.Loan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[int64]bool, len(loans))
+	for _, loan := range loans {
+		if loan.ID == 0 {
+			continue
+		}
+		if _, exists := r.loans[loan.ID]; exists || seen[loan.ID] {
+			return errors.New("loan already exists")
+		}
+		seen[loan.ID] = true
+	}
+
+	for _, loan := range loans {
+		if loan.ID == 0 {
+			loan.ID = r.snowflakeNode.Generate().Int64()
+		}
+		r.loans[loan.ID] = loan
+		r.index.put(loan)
+	}
 	return nil
 }
 
@@ -78,13 +187,59 @@ func (r *repository) FindByID(ctx context.Context, id int64) (*model.Loan, error
 }
 
 func (r *repository) Update(ctx context.Context, loan *model.Loan) error {
+	return r.UpdateAll(ctx, []*model.Loan{loan})
+}
+
+func (r *repository) UpdateAll(ctx context.Context, loans []*model.Loan) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.loans[loan.ID]; !exists {
-		return errors.New("loan not found")
+	for _, loan := range loans {
+		stored, exists := r.loans[loan.ID]
+		if !exists {
+			return errors.New("loan not found")
+		}
+		if stored.Version != loan.Version {
+			return ErrConflict
+		}
 	}
 
-	r.loans[loan.ID] = loan
+	for _, loan := range loans {
+		loan.Version++
+		r.loans[loan.ID] = loan
+		r.index.put(loan)
+	}
 	return nil
 }
+
+func (r *repository) FindByState(ctx context.Context, state model.LoanState) ([]*model.Loan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.lookup(r.index.byState[state]), nil
+}
+
+func (r *repository) FindByBorrowerID(ctx context.Context, borrowerID int64) ([]*model.Loan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.lookup(r.index.byBorrower[borrowerID]), nil
+}
+
+func (r *repository) FindByInvestorID(ctx context.Context, investorID int64) ([]*model.Loan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.lookup(r.index.byInvestor[investorID]), nil
+}
+
+// lookup resolves indexed IDs to loans ordered by ID. The caller must hold the read lock.
+func (r *repository) lookup(ids idSet) []*model.Loan {
+	loans := make([]*model.Loan, 0, len(ids))
+	for id := range ids {
+		loans = append(loans, r.loans[id])
+	}
+
+	sort.Slice(loans, func(i, j int) bool { return loans[i].ID < loans[j].ID })
+	return loans
+}
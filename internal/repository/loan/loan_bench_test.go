@@ -0,0 +1,66 @@
+package loan_test
+
+import (
+	"context"
+	"fmt"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/repository/loan"
+	"testing"
+)
+
+var benchmarkSizes = []int{1_000, 10_000, 100_000, 1_000_000}
+
+// populate fills a repository with n loans spread over n/10 borrowers and
+// n/10 investors, so every lookup below returns ten loans whatever n is.
+func populate(b *testing.B, n int) loan.Repository {
+	b.Helper()
+
+	repo := loan.NewRepository(logger.Discard())
+	states := []model.LoanState{model.StateProposed, model.StateApproved, model.StateInvested, model.StateDisbursed}
+	for i := 0; i < n; i++ {
+		l := &model.Loan{
+			ID:          int64(i + 1),
+			BorrowerID:  int64(i%(n/10) + 1),
+			Principal:   1000,
+			State:       states[i%len(states)],
+			Investments: []model.Investment{{InvestorID: int64(i%(n/10) + 1), Amount: 500}},
+		}
+		if err := repo.Save(context.Background(), l); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return repo
+}
+
+func BenchmarkRepository_Lookups(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		repo := populate(b, n)
+		ctx := context.Background()
+
+		b.Run(fmt.Sprintf("FindByBorrowerID/%d", n), func(b *testing.B) {
+			for i := 0; b.Loop(); i++ {
+				loans, _ := repo.FindByBorrowerID(ctx, int64(i%(n/10)+1))
+				if len(loans) != 10 {
+					b.Fatalf("got %d loans", len(loans))
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("FindByInvestorID/%d", n), func(b *testing.B) {
+			for i := 0; b.Loop(); i++ {
+				loans, _ := repo.FindByInvestorID(ctx, int64(i%(n/10)+1))
+				if len(loans) != 10 {
+					b.Fatalf("got %d loans", len(loans))
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("FindByBorrowerAndState/%d", n), func(b *testing.B) {
+			for i := 0; b.Loop(); i++ {
+				_, _ = repo.Find(ctx, model.LoanQuery{BorrowerID: int64(i%(n/10) + 1), State: model.StateApproved})
+			}
+		})
+	}
+}
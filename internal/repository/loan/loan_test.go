@@ -2,17 +2,47 @@ package loan_test
 
 import (
 	"context"
+	"errors"
 	"loan_system/internal/model"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/migration"
 	"loan_system/internal/repository/loan"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestRepository(t *testing.T) {
-	repo := loan.NewRepository()
+	testRepository(t, loan.NewRepository(logger.Discard()))
+	testRepositoryFind(t, loan.NewRepository(logger.Discard()))
+	testRepositoryLookups(t, loan.NewRepository(logger.Discard()))
+}
+
+func TestSQLRepository(t *testing.T) {
+	testRepository(t, newSQLRepository(t))
+	testRepositoryFind(t, newSQLRepository(t))
+	testRepositoryLookups(t, newSQLRepository(t))
+}
 
+func newSQLRepository(t *testing.T) loan.Repository {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migration.NewMigrator(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	assert.NoError(t, err)
+
+	return loan.NewSQLRepository(db, logger.Discard())
+}
+
+// testRepository holds the behaviour every Repository implementation must share.
+func testRepository(t *testing.T, repo loan.Repository) {
 	t.Run("FindAll", func(t *testing.T) {
 		loans, err := repo.FindAll(context.TODO())
 		assert.NoError(t, err)
@@ -20,7 +50,7 @@ func TestRepository(t *testing.T) {
 	})
 
 	t.Run("Save and FindByID", func(t *testing.T) {
-		l := &model.Loan{Principal: 1000}
+		l := &model.Loan{Principal: 1000, TenorMonths: 6}
 		err := repo.Save(context.TODO(), l)
 		assert.NoError(t, err)
 		assert.NotZero(t, l.ID)
@@ -28,6 +58,7 @@ func TestRepository(t *testing.T) {
 		found, err := repo.FindByID(context.TODO(), l.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, l.Principal, found.Principal)
+		assert.Equal(t, 6, found.TenorMonths)
 	})
 
 	t.Run("Update existing loan", func(t *testing.T) {
@@ -45,6 +76,112 @@ func TestRepository(t *testing.T) {
 		assert.Equal(t, float64(3000), updated.Principal)
 	})
 
+	t.Run("Update loan with approval, investments and disbursement", func(t *testing.T) {
+		approvedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		l := &model.Loan{BorrowerID: 1, Principal: 1000, Rate: 0.1, ROI: 0.08, State: model.StateProposed, AgreementLink: "https://example.com", PartnerID: 8}
+		assert.NoError(t, repo.Save(context.TODO(), l))
+
+		l.State = model.StateDisbursed
+		l.Approval = &model.Approval{ValidatorID: 2, ProofDocumentID: 3, ApprovedAt: approvedAt}
+		l.Investments = []model.Investment{{InvestorID: 4, Amount: 600, InvestedAt: approvedAt}, {InvestorID: 5, Amount: 400, InvestedAt: approvedAt.Add(time.Minute), PartnerID: 8}}
+		l.Disbursement = &model.Disbursement{OfficerID: 6, AgreementDocumentID: 7, DisbursedAt: approvedAt.Add(time.Hour)}
+		assert.NoError(t, repo.Update(context.TODO(), l))
+
+		found, err := repo.FindByID(context.TODO(), l.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, l.State, found.State)
+		assert.Equal(t, l.AgreementLink, found.AgreementLink)
+		assert.Equal(t, l.PartnerID, found.PartnerID)
+		assert.Equal(t, l.Investments, found.Investments)
+		assert.Equal(t, l.Approval.ValidatorID, found.Approval.ValidatorID)
+		assert.True(t, l.Approval.ApprovedAt.Equal(found.Approval.ApprovedAt))
+		assert.Equal(t, l.Disbursement.AgreementDocumentID, found.Disbursement.AgreementDocumentID)
+		assert.True(t, l.Disbursement.DisbursedAt.Equal(found.Disbursement.DisbursedAt))
+
+		loans, err := repo.FindAll(context.TODO())
+		assert.NoError(t, err)
+		assert.Contains(t, loanIDs(loans), l.ID)
+	})
+
+	t.Run("Save duplicate loan", func(t *testing.T) {
+		l := &model.Loan{Principal: 100}
+		assert.NoError(t, repo.Save(context.TODO(), l))
+		assert.ErrorContains(t, repo.Save(context.TODO(), l), "loan already exists")
+	})
+
+	t.Run("SaveAll", func(t *testing.T) {
+		loans := []*model.Loan{{Principal: 100}, {Principal: 200}}
+		assert.NoError(t, repo.SaveAll(context.TODO(), loans))
+		for _, l := range loans {
+			assert.NotZero(t, l.ID)
+			_, err := repo.FindByID(context.TODO(), l.ID)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("SaveAll stores nothing when one loan fails", func(t *testing.T) {
+		existing := &model.Loan{Principal: 100}
+		assert.NoError(t, repo.Save(context.TODO(), existing))
+
+		fresh := &model.Loan{ID: existing.ID + 1, Principal: 200}
+		err := repo.SaveAll(context.TODO(), []*model.Loan{fresh, {ID: existing.ID, Principal: 300}})
+		assert.ErrorContains(t, err, "loan already exists")
+
+		_, err = repo.FindByID(context.TODO(), fresh.ID)
+		assert.ErrorContains(t, err, "loan not found")
+	})
+
+	t.Run("UpdateAll", func(t *testing.T) {
+		loans := []*model.Loan{{Principal: 100, State: model.StateProposed}, {Principal: 200, State: model.StateProposed}}
+		assert.NoError(t, repo.SaveAll(context.TODO(), loans))
+
+		approved := []*model.Loan{}
+		for _, l := range loans {
+			updated := *l
+			updated.State = model.StateApproved
+			approved = append(approved, &updated)
+		}
+		assert.NoError(t, repo.UpdateAll(context.TODO(), approved))
+		for _, l := range loans {
+			found, err := repo.FindByID(context.TODO(), l.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, model.StateApproved, found.State)
+		}
+	})
+
+	t.Run("UpdateAll changes nothing when one loan fails", func(t *testing.T) {
+		l := &model.Loan{Principal: 100, State: model.StateProposed}
+		assert.NoError(t, repo.Save(context.TODO(), l))
+
+		updated := *l
+		updated.State = model.StateApproved
+		err := repo.UpdateAll(context.TODO(), []*model.Loan{&updated, {ID: 999999, State: model.StateApproved}})
+		assert.ErrorContains(t, err, "loan not found")
+
+		found, err := repo.FindByID(context.TODO(), l.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StateProposed, found.State)
+	})
+
+	t.Run("Update refuses a stale copy", func(t *testing.T) {
+		l := &model.Loan{Principal: 100, State: model.StateApproved}
+		assert.NoError(t, repo.Save(context.TODO(), l))
+
+		first, second := *l, *l
+		first.Investments = []model.Investment{{InvestorID: 1, Amount: 40}}
+		second.Investments = []model.Investment{{InvestorID: 2, Amount: 60}}
+		assert.NoError(t, repo.Update(context.TODO(), &first))
+		assert.Equal(t, int64(1), first.Version)
+		assert.ErrorIs(t, repo.Update(context.TODO(), &second), loan.ErrConflict)
+		assert.ErrorIs(t, repo.UpdateAll(context.TODO(), []*model.Loan{&second}), loan.ErrConflict)
+		assert.Zero(t, second.Version)
+
+		found, err := repo.FindByID(context.TODO(), l.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, first.Investments, found.Investments)
+		assert.Equal(t, int64(1), found.Version)
+	})
+
 	t.Run("Concurrent access", func(t *testing.T) {
 		var wg sync.WaitGroup
 		for i := 0; i < 100; i++ {
@@ -73,3 +210,168 @@ func TestRepository(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func loanIDs(loans []*model.Loan) []int64 {
+	ids := make([]int64, 0, len(loans))
+	for _, l := range loans {
+		ids = append(ids, l.ID)
+	}
+	return ids
+}
+
+func testRepositoryFind(t *testing.T, repo loan.Repository) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixtures := []*model.Loan{
+		{ID: 1, BorrowerID: 10, Principal: 500, State: model.StateProposed, CreatedAt: base},
+		{ID: 2, BorrowerID: 10, Principal: 3000, State: model.StateApproved, CreatedAt: base.Add(24 * time.Hour),
+			Investments: []model.Investment{{InvestorID: 100, Amount: 1000}}},
+		{ID: 3, BorrowerID: 20, Principal: 1500, State: model.StateApproved, CreatedAt: base.Add(48 * time.Hour),
+			Investments: []model.Investment{{InvestorID: 100, Amount: 500}, {InvestorID: 200, Amount: 500}}},
+		{ID: 4, BorrowerID: 20, Principal: 1500, State: model.StateInvested, CreatedAt: base.Add(72 * time.Hour),
+			Investments: []model.Investment{{InvestorID: 200, Amount: 1500}}},
+		{ID: 5, BorrowerID: 30, Principal: 800, State: model.StateProposed, CreatedAt: base.Add(96 * time.Hour)},
+	}
+	for _, l := range fixtures {
+		assert.NoError(t, repo.Save(context.TODO(), l))
+	}
+
+	tests := []struct {
+		name  string
+		query model.LoanQuery
+		want  []int64
+	}{
+		{name: "no filter", query: model.LoanQuery{}, want: []int64{1, 2, 3, 4, 5}},
+		{name: "by state", query: model.LoanQuery{State: model.StateApproved}, want: []int64{2, 3}},
+		{name: "by borrower", query: model.LoanQuery{BorrowerID: 20}, want: []int64{3, 4}},
+		{name: "by investor", query: model.LoanQuery{InvestorID: 100}, want: []int64{2, 3}},
+		{name: "by principal range", query: model.LoanQuery{MinPrincipal: 800, MaxPrincipal: 1500}, want: []int64{3, 4, 5}},
+		{name: "by created range", query: model.LoanQuery{CreatedFrom: base.Add(24 * time.Hour), CreatedTo: base.Add(72 * time.Hour)}, want: []int64{2, 3, 4}},
+		{name: "sort by principal", query: model.LoanQuery{SortBy: model.LoanSortPrincipal}, want: []int64{1, 5, 3, 4, 2}},
+		{name: "sort by principal descending", query: model.LoanQuery{SortBy: model.LoanSortPrincipal, Descending: true}, want: []int64{2, 4, 3, 5, 1}},
+		{name: "sort by created descending", query: model.LoanQuery{SortBy: model.LoanSortCreatedAt, Descending: true}, want: []int64{5, 4, 3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run("Find "+tt.name, func(t *testing.T) {
+			page, err := repo.Find(context.TODO(), tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, loanIDs(page.Loans))
+			assert.Empty(t, page.NextCursor)
+		})
+	}
+
+	for _, sortBy := range []model.LoanSortField{model.LoanSortID, model.LoanSortPrincipal, model.LoanSortCreatedAt} {
+		t.Run("Find paginates by "+string(sortBy), func(t *testing.T) {
+			query := model.LoanQuery{SortBy: sortBy, Descending: true, Limit: 2}
+			all, err := repo.Find(context.TODO(), model.LoanQuery{SortBy: sortBy, Descending: true})
+			assert.NoError(t, err)
+
+			var got []int64
+			pages := 0
+			for {
+				page, err := repo.Find(context.TODO(), query)
+				assert.NoError(t, err)
+				assert.LessOrEqual(t, len(page.Loans), 2)
+				got = append(got, loanIDs(page.Loans)...)
+				pages++
+
+				if page.NextCursor == "" {
+					break
+				}
+				query.After, err = model.DecodeLoanCursor(page.NextCursor)
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, 3, pages)
+			assert.Equal(t, loanIDs(all.Loans), got)
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run("Each "+tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Limit = 1
+			var got []int64
+			err := repo.Each(context.TODO(), query, func(l *model.Loan) error {
+				got = append(got, l.ID)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("Each from cursor", func(t *testing.T) {
+		var got []int64
+		query := model.LoanQuery{SortBy: model.LoanSortPrincipal, After: &model.LoanCursor{ID: 5, Principal: 800}}
+		assert.NoError(t, repo.Each(context.TODO(), query, func(l *model.Loan) error {
+			got = append(got, l.ID)
+			return nil
+		}))
+		assert.Equal(t, []int64{3, 4, 2}, got)
+	})
+
+	t.Run("Each stops at error", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.Each(context.TODO(), model.LoanQuery{}, func(l *model.Loan) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func testRepositoryLookups(t *testing.T, repo loan.Repository) {
+	proposed := &model.Loan{BorrowerID: 1, Principal: 1000, State: model.StateProposed}
+	approved := &model.Loan{BorrowerID: 1, Principal: 1000, State: model.StateApproved}
+	other := &model.Loan{BorrowerID: 2, Principal: 1000, State: model.StateApproved}
+	for _, l := range []*model.Loan{proposed, approved, other} {
+		assert.NoError(t, repo.Save(context.TODO(), l))
+	}
+
+	t.Run("FindByState", func(t *testing.T) {
+		loans, err := repo.FindByState(context.TODO(), model.StateApproved)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{approved.ID, other.ID}, loanIDs(loans))
+
+		loans, err = repo.FindByState(context.TODO(), model.StateDisbursed)
+		assert.NoError(t, err)
+		assert.Empty(t, loans)
+	})
+
+	t.Run("FindByBorrowerID", func(t *testing.T) {
+		loans, err := repo.FindByBorrowerID(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{proposed.ID, approved.ID}, loanIDs(loans))
+	})
+
+	t.Run("Update moves loan between indexes", func(t *testing.T) {
+		l, err := repo.FindByID(context.TODO(), approved.ID)
+		assert.NoError(t, err)
+
+		// mutate through the returned pointer like the usecases do
+		assert.NoError(t, l.AddInvestment(model.Investment{InvestorID: 9, Amount: 400}))
+		assert.NoError(t, l.AddInvestment(model.Investment{InvestorID: 8, Amount: 600}))
+		assert.NoError(t, repo.Update(context.TODO(), l))
+
+		loans, err := repo.FindByState(context.TODO(), model.StateApproved)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{other.ID}, loanIDs(loans))
+
+		loans, err = repo.FindByState(context.TODO(), model.StateInvested)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{approved.ID}, loanIDs(loans))
+
+		for _, investorID := range []int64{8, 9} {
+			loans, err = repo.FindByInvestorID(context.TODO(), investorID)
+			assert.NoError(t, err)
+			assert.Equal(t, []int64{approved.ID}, loanIDs(loans))
+		}
+
+		page, err := repo.Find(context.TODO(), model.LoanQuery{State: model.StateApproved, BorrowerID: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{other.ID}, loanIDs(page.Loans))
+	})
+}
@@ -41,6 +41,35 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Each mocks base method.
+func (m *MockRepository) Each(ctx context.Context, query model.LoanQuery, fn func(*model.Loan) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Each", ctx, query, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Each indicates an expected call of Each.
+func (mr *MockRepositoryMockRecorder) Each(ctx, query, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Each", reflect.TypeOf((*MockRepository)(nil).Each), ctx, query, fn)
+}
+
+// Find mocks base method.
+func (m *MockRepository) Find(ctx context.Context, query model.LoanQuery) (model.LoanPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, query)
+	ret0, _ := ret[0].(model.LoanPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockRepositoryMockRecorder) Find(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRepository)(nil).Find), ctx, query)
+}
+
 // FindAll mocks base method.
 func (m *MockRepository) FindAll(ctx context.Context) ([]*model.Loan, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +85,21 @@ func (mr *MockRepositoryMockRecorder) FindAll(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRepository)(nil).FindAll), ctx)
 }
 
+// FindByBorrowerID mocks base method.
+func (m *MockRepository) FindByBorrowerID(ctx context.Context, borrowerID int64) ([]*model.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByBorrowerID", ctx, borrowerID)
+	ret0, _ := ret[0].([]*model.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByBorrowerID indicates an expected call of FindByBorrowerID.
+func (mr *MockRepositoryMockRecorder) FindByBorrowerID(ctx, borrowerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByBorrowerID", reflect.TypeOf((*MockRepository)(nil).FindByBorrowerID), ctx, borrowerID)
+}
+
 // FindByID mocks base method.
 func (m *MockRepository) FindByID(ctx context.Context, id int64) (*model.Loan, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +115,36 @@ func (mr *MockRepositoryMockRecorder) FindByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), ctx, id)
 }
 
+// FindByInvestorID mocks base method.
+func (m *MockRepository) FindByInvestorID(ctx context.Context, investorID int64) ([]*model.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByInvestorID", ctx, investorID)
+	ret0, _ := ret[0].([]*model.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByInvestorID indicates an expected call of FindByInvestorID.
+func (mr *MockRepositoryMockRecorder) FindByInvestorID(ctx, investorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByInvestorID", reflect.TypeOf((*MockRepository)(nil).FindByInvestorID), ctx, investorID)
+}
+
+// FindByState mocks base method.
+func (m *MockRepository) FindByState(ctx context.Context, state model.LoanState) ([]*model.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByState", ctx, state)
+	ret0, _ := ret[0].([]*model.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByState indicates an expected call of FindByState.
+func (mr *MockRepositoryMockRecorder) FindByState(ctx, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByState", reflect.TypeOf((*MockRepository)(nil).FindByState), ctx, state)
+}
+
 // Save mocks base method.
 func (m *MockRepository) Save(ctx context.Context, loan *model.Loan) error {
 	m.ctrl.T.Helper()
@@ -85,6 +159,20 @@ func (mr *MockRepositoryMockRecorder) Save(ctx, loan any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepository)(nil).Save), ctx, loan)
 }
 
+// SaveAll mocks base method.
+func (m *MockRepository) SaveAll(ctx context.Context, loans []*model.Loan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAll", ctx, loans)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAll indicates an expected call of SaveAll.
+func (mr *MockRepositoryMockRecorder) SaveAll(ctx, loans any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAll", reflect.TypeOf((*MockRepository)(nil).SaveAll), ctx, loans)
+}
+
 // Update mocks base method.
 func (m *MockRepository) Update(ctx context.Context, loan *model.Loan) error {
 	m.ctrl.T.Helper()
@@ -98,3 +186,17 @@ func (mr *MockRepositoryMockRecorder) Update(ctx, loan any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, loan)
 }
+
+// UpdateAll mocks base method.
+func (m *MockRepository) UpdateAll(ctx context.Context, loans []*model.Loan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAll", ctx, loans)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAll indicates an expected call of UpdateAll.
+func (mr *MockRepositoryMockRecorder) UpdateAll(ctx, loans any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAll", reflect.TypeOf((*MockRepository)(nil).UpdateAll), ctx, loans)
+}
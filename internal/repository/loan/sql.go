@@ -0,0 +1,459 @@
+package loan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"loan_system/internal/model"
+	"log/slog"
+	"strings"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+type sqlRepository struct {
+	db            *sql.DB
+	snowflakeNode *snowflake.Node
+}
+
+func NewSQLRepository(db *sql.DB, log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(1)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &sqlRepository{
+		db:            db,
+		snowflakeNode: node,
+	}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx so reads can join a running transaction.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+const selectLoans = `SELECT id, borrower_id, principal, rate, roi, state, agreement_link, tenor_months, created_at, partner_id, version FROM loans`
+
+var sortColumns = map[model.LoanSortField]string{
+	model.LoanSortID:        "id",
+	model.LoanSortPrincipal: "principal",
+	model.LoanSortCreatedAt: "created_at",
+}
+
+func (r *sqlRepository) FindAll(ctx context.Context) ([]*model.Loan, error) {
+	return r.findLoans(ctx, r.db, selectLoans+` ORDER BY id`)
+}
+
+func (r *sqlRepository) Find(ctx context.Context, query model.LoanQuery) (model.LoanPage, error) {
+	query = query.Normalized()
+
+	column, ok := sortColumns[query.SortBy]
+	if !ok {
+		return model.LoanPage{}, fmt.Errorf("unsupported sort field %s", query.SortBy)
+	}
+
+	where := make([]string, 0)
+	args := make([]any, 0)
+	if query.State != "" {
+		where = append(where, `state = ?`)
+		args = append(args, query.State)
+	}
+	if query.BorrowerID != 0 {
+		where = append(where, `borrower_id = ?`)
+		args = append(args, query.BorrowerID)
+	}
+	if query.InvestorID != 0 {
+		where = append(where, `id IN (SELECT loan_id FROM investments WHERE investor_id = ?)`)
+		args = append(args, query.InvestorID)
+	}
+	if query.MinPrincipal != 0 {
+		where = append(where, `principal >= ?`)
+		args = append(args, query.MinPrincipal)
+	}
+	if query.MaxPrincipal != 0 {
+		where = append(where, `principal <= ?`)
+		args = append(args, query.MaxPrincipal)
+	}
+	if !query.CreatedFrom.IsZero() {
+		where = append(where, `created_at >= ?`)
+		args = append(args, query.CreatedFrom.UTC())
+	}
+	if !query.CreatedTo.IsZero() {
+		where = append(where, `created_at <= ?`)
+		args = append(args, query.CreatedTo.UTC())
+	}
+
+	direction, op := "ASC", ">"
+	if query.Descending {
+		direction, op = "DESC", "<"
+	}
+
+	if after := query.After; after != nil {
+		var value any
+		switch query.SortBy {
+		case model.LoanSortPrincipal:
+			value = after.Principal
+		case model.LoanSortCreatedAt:
+			value = after.CreatedAt.UTC()
+		}
+
+		if value == nil {
+			where = append(where, `id `+op+` ?`)
+			args = append(args, after.ID)
+		} else {
+			where = append(where, `(`+column+` `+op+` ? OR (`+column+` = ? AND id `+op+` ?))`)
+			args = append(args, value, value, after.ID)
+		}
+	}
+
+	statement := selectLoans
+	if len(where) > 0 {
+		statement += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	if column != "id" {
+		statement += ` ORDER BY ` + column + ` ` + direction + `, id ` + direction
+	} else {
+		statement += ` ORDER BY id ` + direction
+	}
+	// fetch one extra row to learn whether another page follows
+	statement += ` LIMIT ?`
+	args = append(args, query.Limit+1)
+
+	loans, err := r.findLoans(ctx, r.db, statement, args...)
+	if err != nil {
+		return model.LoanPage{}, err
+	}
+
+	return page(loans, query.Limit), nil
+}
+
+// Each reads the loans a page at a time through Find, so only one page is
+// held at once. Each page is its own query rather than part of one snapshot:
+// a loan stored or changed between pages is seen in its new state, or missed
+// if it now sorts before the cursor.
+func (r *sqlRepository) Each(ctx context.Context, query model.LoanQuery, fn func(*model.Loan) error) error {
+	query.Limit = model.MaxLoanQueryLimit
+	for {
+		page, err := r.Find(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, loan := range page.Loans {
+			if err := fn(loan); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		if query.After, err = model.DecodeLoanCursor(page.NextCursor); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *sqlRepository) Save(ctx context.Context, loan *model.Loan) error {
+	return r.SaveAll(ctx, []*model.Loan{loan})
+}
+
+// SaveAll inserts the loans and their child rows in a single transaction.
+func (r *sqlRepository) SaveAll(ctx context.Context, loans []*model.Loan) error {
+	for _, loan := range loans {
+		if loan.ID == 0 {
+			loan.ID = r.snowflakeNode.Generate().Int64()
+		}
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		for _, loan := range loans {
+			if err := r.insert(ctx, tx, loan); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *sqlRepository) insert(ctx context.Context, tx *sql.Tx, loan *model.Loan) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM loans WHERE id = ?)`, loan.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("loan already exists")
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO loans (id, borrower_id, principal, rate, roi, state, agreement_link, tenor_months, created_at, partner_id, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		loan.ID, loan.BorrowerID, loan.Principal, loan.Rate, loan.ROI, loan.State, loan.AgreementLink, loan.TenorMonths, loan.CreatedAt.UTC(), loan.PartnerID, loan.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	return r.saveChildren(ctx, tx, loan)
+}
+
+func (r *sqlRepository) FindByID(ctx context.Context, id int64) (*model.Loan, error) {
+	loans, err := r.findLoans(ctx, r.db, selectLoans+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(loans) == 0 {
+		return nil, errors.New("loan not found")
+	}
+
+	return loans[0], nil
+}
+
+func (r *sqlRepository) FindByState(ctx context.Context, state model.LoanState) ([]*model.Loan, error) {
+	return r.findLoans(ctx, r.db, selectLoans+` WHERE state = ? ORDER BY id`, state)
+}
+
+func (r *sqlRepository) FindByBorrowerID(ctx context.Context, borrowerID int64) ([]*model.Loan, error) {
+	return r.findLoans(ctx, r.db, selectLoans+` WHERE borrower_id = ? ORDER BY id`, borrowerID)
+}
+
+func (r *sqlRepository) FindByInvestorID(ctx context.Context, investorID int64) ([]*model.Loan, error) {
+	return r.findLoans(ctx, r.db, selectLoans+` WHERE id IN (SELECT loan_id FROM investments WHERE investor_id = ?) ORDER BY id`, investorID)
+}
+
+// Update rewrites the loan row and all of its child rows in a single transaction.
+func (r *sqlRepository) Update(ctx context.Context, loan *model.Loan) error {
+	return r.UpdateAll(ctx, []*model.Loan{loan})
+}
+
+// UpdateAll rewrites the loans in a single transaction. The versions are
+// only incremented once it commits.
+func (r *sqlRepository) UpdateAll(ctx context.Context, loans []*model.Loan) error {
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		for _, loan := range loans {
+			if err := r.update(ctx, tx, loan); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, loan := range loans {
+		loan.Version++
+	}
+	return nil
+}
+
+func (r *sqlRepository) update(ctx context.Context, tx *sql.Tx, loan *model.Loan) error {
+	result, err := tx.ExecContext(ctx,
+		`UPDATE loans SET borrower_id = ?, principal = ?, rate = ?, roi = ?, state = ?, agreement_link = ?, tenor_months = ?, created_at = ?, partner_id = ?, version = version + 1 WHERE id = ? AND version = ?`,
+		loan.BorrowerID, loan.Principal, loan.Rate, loan.ROI, loan.State, loan.AgreementLink, loan.TenorMonths, loan.CreatedAt.UTC(), loan.PartnerID, loan.ID, loan.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM loans WHERE id = ?)`, loan.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrConflict
+		}
+		return errors.New("loan not found")
+	}
+
+	for _, table := range []string{"approvals", "investments", "disbursements"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE loan_id = ?`, loan.ID); err != nil {
+			return err
+		}
+	}
+
+	return r.saveChildren(ctx, tx, loan)
+}
+
+func (r *sqlRepository) saveChildren(ctx context.Context, q queryer, loan *model.Loan) error {
+	if loan.Approval != nil {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO approvals (loan_id, validator_id, proof_document_id, approved_at) VALUES (?, ?, ?, ?)`,
+			loan.ID, loan.Approval.ValidatorID, loan.Approval.ProofDocumentID, loan.Approval.ApprovedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, inv := range loan.Investments {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO investments (loan_id, investor_id, amount, invested_at, partner_id) VALUES (?, ?, ?, ?, ?)`,
+			loan.ID, inv.InvestorID, inv.Amount, inv.InvestedAt.UTC(), inv.PartnerID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if loan.Disbursement != nil {
+		_, err := q.ExecContext(ctx,
+			`INSERT INTO disbursements (loan_id, officer_id, agreement_document_id, disbursed_at) VALUES (?, ?, ?, ?)`,
+			loan.ID, loan.Disbursement.OfficerID, loan.Disbursement.AgreementDocumentID, loan.Disbursement.DisbursedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findLoans runs a query over the loans table and attaches the child rows of every loan it returns.
+func (r *sqlRepository) findLoans(ctx context.Context, q queryer, query string, args ...any) ([]*model.Loan, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loans := make([]*model.Loan, 0)
+	byID := make(map[int64]*model.Loan)
+	for rows.Next() {
+		loan := new(model.Loan)
+		if err := rows.Scan(&loan.ID, &loan.BorrowerID, &loan.Principal, &loan.Rate, &loan.ROI, &loan.State, &loan.AgreementLink, &loan.TenorMonths, &loan.CreatedAt, &loan.PartnerID, &loan.Version); err != nil {
+			return nil, err
+		}
+		loans = append(loans, loan)
+		byID[loan.ID] = loan
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(loans) == 0 {
+		return loans, nil
+	}
+
+	if err := r.attachApprovals(ctx, q, byID); err != nil {
+		return nil, err
+	}
+	if err := r.attachInvestments(ctx, q, byID); err != nil {
+		return nil, err
+	}
+	if err := r.attachDisbursements(ctx, q, byID); err != nil {
+		return nil, err
+	}
+
+	return loans, nil
+}
+
+func (r *sqlRepository) attachApprovals(ctx context.Context, q queryer, loans map[int64]*model.Loan) error {
+	query, args := childQuery(`SELECT loan_id, validator_id, proof_document_id, approved_at FROM approvals`, loans)
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanID int64
+		approval := new(model.Approval)
+		if err := rows.Scan(&loanID, &approval.ValidatorID, &approval.ProofDocumentID, &approval.ApprovedAt); err != nil {
+			return err
+		}
+		if loan, ok := loans[loanID]; ok {
+			loan.Approval = approval
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *sqlRepository) attachInvestments(ctx context.Context, q queryer, loans map[int64]*model.Loan) error {
+	query, args := childQuery(`SELECT loan_id, investor_id, amount, invested_at, partner_id FROM investments`, loans)
+	rows, err := q.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanID int64
+		var inv model.Investment
+		if err := rows.Scan(&loanID, &inv.InvestorID, &inv.Amount, &inv.InvestedAt, &inv.PartnerID); err != nil {
+			return err
+		}
+		inv.InvestedAt = inv.InvestedAt.UTC()
+		if loan, ok := loans[loanID]; ok {
+			loan.Investments = append(loan.Investments, inv)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *sqlRepository) attachDisbursements(ctx context.Context, q queryer, loans map[int64]*model.Loan) error {
+	query, args := childQuery(`SELECT loan_id, officer_id, agreement_document_id, disbursed_at FROM disbursements`, loans)
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var loanID int64
+		disbursement := new(model.Disbursement)
+		if err := rows.Scan(&loanID, &disbursement.OfficerID, &disbursement.AgreementDocumentID, &disbursement.DisbursedAt); err != nil {
+			return err
+		}
+		if loan, ok := loans[loanID]; ok {
+			loan.Disbursement = disbursement
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *sqlRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// childQuery restricts a child table query to the given loans. Loading a large
+// set falls back to reading the whole table instead of binding every ID.
+func childQuery(query string, loans map[int64]*model.Loan) (string, []any) {
+	const maxBoundIDs = 500
+	if len(loans) > maxBoundIDs {
+		return query, nil
+	}
+
+	args := make([]any, 0, len(loans))
+	placeholders := make([]byte, 0, len(loans)*2)
+	for id := range loans {
+		if len(args) > 0 {
+			placeholders = append(placeholders, ',')
+		}
+		placeholders = append(placeholders, '?')
+		args = append(args, id)
+	}
+
+	return query + ` WHERE loan_id IN (` + string(placeholders) + `)`, args
+}
@@ -0,0 +1,114 @@
+package loan
+
+import (
+	"context"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type tracingRepository struct {
+	next Repository
+}
+
+// WithTracing wraps every call to the repository in a span.
+func WithTracing(next Repository) Repository {
+	return &tracingRepository{next: next}
+}
+
+func (r *tracingRepository) FindAll(ctx context.Context) (loans []*model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.FindAll")
+	defer tracing.End(span, &err)
+
+	loans, err = r.next.FindAll(ctx)
+	span.SetAttributes(attribute.Int("loan.count", len(loans)))
+	return loans, err
+}
+
+func (r *tracingRepository) Find(ctx context.Context, query model.LoanQuery) (page model.LoanPage, err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.Find",
+		attribute.String("loan.query.sort", string(query.SortBy)),
+		attribute.Int("loan.query.limit", query.Limit),
+	)
+	defer tracing.End(span, &err)
+
+	page, err = r.next.Find(ctx, query)
+	span.SetAttributes(attribute.Int("loan.count", len(page.Loans)))
+	return page, err
+}
+
+func (r *tracingRepository) Each(ctx context.Context, query model.LoanQuery, fn func(*model.Loan) error) (err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.Each", attribute.String("loan.query.sort", string(query.SortBy)))
+	defer tracing.End(span, &err)
+
+	count := 0
+	err = r.next.Each(ctx, query, func(loan *model.Loan) error {
+		count++
+		return fn(loan)
+	})
+	span.SetAttributes(attribute.Int("loan.count", count))
+	return err
+}
+
+func (r *tracingRepository) Save(ctx context.Context, loan *model.Loan) (err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.Save")
+	defer tracing.End(span, &err)
+
+	err = r.next.Save(ctx, loan)
+	span.SetAttributes(attribute.Int64("loan.id", loan.ID))
+	return err
+}
+
+func (r *tracingRepository) SaveAll(ctx context.Context, loans []*model.Loan) (err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.SaveAll", attribute.Int("loan.count", len(loans)))
+	defer tracing.End(span, &err)
+
+	return r.next.SaveAll(ctx, loans)
+}
+
+func (r *tracingRepository) FindByID(ctx context.Context, id int64) (loan *model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.FindByID", attribute.Int64("loan.id", id))
+	defer tracing.End(span, &err)
+
+	return r.next.FindByID(ctx, id)
+}
+
+func (r *tracingRepository) FindByState(ctx context.Context, state model.LoanState) (loans []*model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.FindByState", attribute.String("loan.state", string(state)))
+	defer tracing.End(span, &err)
+
+	return r.next.FindByState(ctx, state)
+}
+
+func (r *tracingRepository) FindByBorrowerID(ctx context.Context, borrowerID int64) (loans []*model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.FindByBorrowerID", attribute.Int64("loan.borrower_id", borrowerID))
+	defer tracing.End(span, &err)
+
+	return r.next.FindByBorrowerID(ctx, borrowerID)
+}
+
+func (r *tracingRepository) FindByInvestorID(ctx context.Context, investorID int64) (loans []*model.Loan, err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.FindByInvestorID", attribute.Int64("loan.investor_id", investorID))
+	defer tracing.End(span, &err)
+
+	return r.next.FindByInvestorID(ctx, investorID)
+}
+
+func (r *tracingRepository) Update(ctx context.Context, loan *model.Loan) (err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.Update",
+		attribute.Int64("loan.id", loan.ID),
+		attribute.String("loan.state", string(loan.State)),
+	)
+	defer tracing.End(span, &err)
+
+	return r.next.Update(ctx, loan)
+}
+
+func (r *tracingRepository) UpdateAll(ctx context.Context, loans []*model.Loan) (err error) {
+	ctx, span := tracing.Start(ctx, "loan.Repository.UpdateAll", attribute.Int("loan.count", len(loans)))
+	defer tracing.End(span, &err)
+
+	return r.next.UpdateAll(ctx, loans)
+}
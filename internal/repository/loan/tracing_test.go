@@ -0,0 +1,40 @@
+package loan_test
+
+import (
+	"context"
+	"testing"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/repository/loan"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	repo := loan.WithTracing(loan.NewRepository(logger.Discard()))
+	testRepository(t, repo)
+
+	l := &model.Loan{Principal: 100}
+	assert.NoError(t, repo.Save(context.TODO(), l))
+	_, err := repo.FindByID(context.TODO(), l.ID)
+	assert.NoError(t, err)
+	_, err = repo.FindByID(context.TODO(), 1)
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	last := spans[len(spans)-3:]
+	assert.Equal(t, "loan.Repository.Save", last[0].Name())
+	assert.Equal(t, "loan.Repository.FindByID", last[1].Name())
+	assert.Equal(t, codes.Unset, last[1].Status().Code)
+	assert.Equal(t, codes.Error, last[2].Status().Code)
+}
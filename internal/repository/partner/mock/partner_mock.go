@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: partner.go
+//
+// Generated by this command:
+//
+//	mockgen -source=partner.go -destination=mock/partner_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "loan_system/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByID mocks base method.
+func (m *MockRepository) FindByID(ctx context.Context, id int64) (*model.Partner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(*model.Partner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockRepositoryMockRecorder) FindByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), ctx, id)
+}
+
+// FindKey mocks base method.
+func (m *MockRepository) FindKey(ctx context.Context, id string) (*model.PartnerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKey", ctx, id)
+	ret0, _ := ret[0].(*model.PartnerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindKey indicates an expected call of FindKey.
+func (mr *MockRepositoryMockRecorder) FindKey(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKey", reflect.TypeOf((*MockRepository)(nil).FindKey), ctx, id)
+}
+
+// FindKeysByPartnerID mocks base method.
+func (m *MockRepository) FindKeysByPartnerID(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindKeysByPartnerID", ctx, partnerID)
+	ret0, _ := ret[0].([]*model.PartnerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindKeysByPartnerID indicates an expected call of FindKeysByPartnerID.
+func (mr *MockRepositoryMockRecorder) FindKeysByPartnerID(ctx, partnerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindKeysByPartnerID", reflect.TypeOf((*MockRepository)(nil).FindKeysByPartnerID), ctx, partnerID)
+}
+
+// Save mocks base method.
+func (m *MockRepository) Save(ctx context.Context, partner *model.Partner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepositoryMockRecorder) Save(ctx, partner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepository)(nil).Save), ctx, partner)
+}
+
+// SaveKey mocks base method.
+func (m *MockRepository) SaveKey(ctx context.Context, key *model.PartnerKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKey indicates an expected call of SaveKey.
+func (mr *MockRepositoryMockRecorder) SaveKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKey", reflect.TypeOf((*MockRepository)(nil).SaveKey), ctx, key)
+}
+
+// UpdateKey mocks base method.
+func (m *MockRepository) UpdateKey(ctx context.Context, key *model.PartnerKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateKey indicates an expected call of UpdateKey.
+func (mr *MockRepositoryMockRecorder) UpdateKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKey", reflect.TypeOf((*MockRepository)(nil).UpdateKey), ctx, key)
+}
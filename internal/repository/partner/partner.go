@@ -0,0 +1,131 @@
+package partner
+
+import (
+	"context"
+	"errors"
+	"loan_system/internal/model"
+	"log/slog"
+	"sync"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+//go:generate mockgen -source=partner.go -destination=mock/partner_mock.go -package=mock
+type Repository interface {
+	Save(ctx context.Context, partner *model.Partner) error
+	FindByID(ctx context.Context, id int64) (*model.Partner, error)
+	SaveKey(ctx context.Context, key *model.PartnerKey) error
+	UpdateKey(ctx context.Context, key *model.PartnerKey) error
+	FindKey(ctx context.Context, id string) (*model.PartnerKey, error)
+	FindKeysByPartnerID(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error)
+}
+
+type repository struct {
+	mu            sync.RWMutex
+	snowflakeNode *snowflake.Node
+	partners      map[int64]*model.Partner
+	keys          map[string]*model.PartnerKey
+	byPartner     map[int64][]string
+}
+
+func NewRepository(log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(3)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &repository{
+		snowflakeNode: node,
+		partners:      make(map[int64]*model.Partner),
+		keys:          make(map[string]*model.PartnerKey),
+		byPartner:     make(map[int64][]string),
+	}
+}
+
+func (r *repository) Save(ctx context.Context, partner *model.Partner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if partner.ID == 0 {
+		partner.ID = r.snowflakeNode.Generate().Int64()
+	}
+
+	if _, exists := r.partners[partner.ID]; exists {
+		return errors.New("partner already exists")
+	}
+
+	r.partners[partner.ID] = partner
+	return nil
+}
+
+func (r *repository) FindByID(ctx context.Context, id int64) (*model.Partner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	partner, exists := r.partners[id]
+	if !exists {
+		return nil, errors.New("partner not found")
+	}
+
+	return partner, nil
+}
+
+func (r *repository) SaveKey(ctx context.Context, key *model.PartnerKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.partners[key.PartnerID]; !exists {
+		return errors.New("partner not found")
+	}
+	if _, exists := r.keys[key.ID]; exists {
+		return errors.New("partner key already exists")
+	}
+
+	stored := *key
+	r.keys[key.ID] = &stored
+	r.byPartner[key.PartnerID] = append(r.byPartner[key.PartnerID], key.ID)
+	return nil
+}
+
+func (r *repository) UpdateKey(ctx context.Context, key *model.PartnerKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.keys[key.ID]; !exists {
+		return errors.New("partner key not found")
+	}
+
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+// FindKey returns a copy of the key, so callers may clear the secret before
+// responding without touching the stored one.
+func (r *repository) FindKey(ctx context.Context, id string) (*model.PartnerKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, errors.New("partner key not found")
+	}
+
+	found := *key
+	return &found, nil
+}
+
+func (r *repository) FindKeysByPartnerID(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.byPartner[partnerID]
+	keys := make([]*model.PartnerKey, 0, len(ids))
+	for _, id := range ids {
+		found := *r.keys[id]
+		keys = append(keys, &found)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,100 @@
+package partner_test
+
+import (
+	"context"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/migration"
+	"loan_system/internal/repository/partner"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	testRepository(t, partner.NewRepository(logger.Discard()))
+}
+
+func TestSQLRepository(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migration.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	testRepository(t, partner.NewSQLRepository(db, logger.Discard()))
+}
+
+// testRepository holds the behaviour every Repository implementation must share.
+func testRepository(t *testing.T, repo partner.Repository) {
+	createdAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	p := &model.Partner{Name: "Koperasi Maju", CreatedAt: createdAt}
+
+	t.Run("Save and FindByID", func(t *testing.T) {
+		assert.NoError(t, repo.Save(context.TODO(), p))
+		assert.NotZero(t, p.ID)
+
+		found, err := repo.FindByID(context.TODO(), p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, p, found)
+
+		assert.ErrorContains(t, repo.Save(context.TODO(), p), "partner already exists")
+	})
+
+	t.Run("SaveKey and FindKey", func(t *testing.T) {
+		key := &model.PartnerKey{
+			ID:        "pk_1",
+			PartnerID: p.ID,
+			Secret:    "secret",
+			Scopes:    []model.PartnerScope{model.PartnerScopeLoansCreate, model.PartnerScopeWebhooks},
+			CreatedAt: createdAt,
+		}
+		assert.NoError(t, repo.SaveKey(context.TODO(), key))
+		assert.ErrorContains(t, repo.SaveKey(context.TODO(), key), "partner key already exists")
+
+		found, err := repo.FindKey(context.TODO(), "pk_1")
+		assert.NoError(t, err)
+		assert.Equal(t, key, found)
+
+		// callers get copies
+		found.Secret = ""
+		again, err := repo.FindKey(context.TODO(), "pk_1")
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", again.Secret)
+	})
+
+	t.Run("UpdateKey", func(t *testing.T) {
+		key, err := repo.FindKey(context.TODO(), "pk_1")
+		assert.NoError(t, err)
+
+		revokedAt := createdAt.Add(time.Hour)
+		key.RevokedAt = &revokedAt
+		assert.NoError(t, repo.UpdateKey(context.TODO(), key))
+
+		keys, err := repo.FindKeysByPartnerID(context.TODO(), p.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, keys, 1) {
+			assert.False(t, keys[0].Active())
+			assert.Equal(t, key, keys[0])
+		}
+	})
+
+	t.Run("SaveKey for unknown partner", func(t *testing.T) {
+		assert.ErrorContains(t, repo.SaveKey(context.TODO(), &model.PartnerKey{ID: "pk_2", PartnerID: 1}), "partner not found")
+	})
+
+	t.Run("Find non-existent", func(t *testing.T) {
+		_, err := repo.FindByID(context.TODO(), 999999)
+		assert.ErrorContains(t, err, "partner not found")
+		_, err = repo.FindKey(context.TODO(), "missing")
+		assert.ErrorContains(t, err, "partner key not found")
+		assert.ErrorContains(t, repo.UpdateKey(context.TODO(), &model.PartnerKey{ID: "missing"}), "partner key not found")
+	})
+}
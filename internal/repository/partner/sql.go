@@ -0,0 +1,173 @@
+package partner
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"loan_system/internal/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+type sqlRepository struct {
+	db            *sql.DB
+	snowflakeNode *snowflake.Node
+}
+
+func NewSQLRepository(db *sql.DB, log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(3)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &sqlRepository{
+		db:            db,
+		snowflakeNode: node,
+	}
+}
+
+const selectKeys = `SELECT id, partner_id, secret, scopes, created_at, revoked_at FROM partner_keys`
+
+func (r *sqlRepository) Save(ctx context.Context, partner *model.Partner) error {
+	if partner.ID == 0 {
+		partner.ID = r.snowflakeNode.Generate().Int64()
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO partners (id, name, created_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		partner.ID, partner.Name, partner.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("partner already exists")
+	}
+	return nil
+}
+
+func (r *sqlRepository) FindByID(ctx context.Context, id int64) (*model.Partner, error) {
+	partner := new(model.Partner)
+	err := r.db.QueryRowContext(ctx, `SELECT id, name, created_at FROM partners WHERE id = ?`, id).
+		Scan(&partner.ID, &partner.Name, &partner.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("partner not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	partner.CreatedAt = partner.CreatedAt.UTC()
+	return partner, nil
+}
+
+func (r *sqlRepository) SaveKey(ctx context.Context, key *model.PartnerKey) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM partners WHERE id = ?)`, key.PartnerID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("partner not found")
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO partner_keys (id, partner_id, secret, scopes, created_at, revoked_at) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		key.ID, key.PartnerID, key.Secret, joinScopes(key.Scopes), key.CreatedAt.UTC(), revokedAt(key),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("partner key already exists")
+	}
+	return nil
+}
+
+func (r *sqlRepository) UpdateKey(ctx context.Context, key *model.PartnerKey) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE partner_keys SET secret = ?, scopes = ?, revoked_at = ? WHERE id = ?`,
+		key.Secret, joinScopes(key.Scopes), revokedAt(key), key.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("partner key not found")
+	}
+	return nil
+}
+
+func (r *sqlRepository) FindKey(ctx context.Context, id string) (*model.PartnerKey, error) {
+	keys, err := r.findKeys(ctx, selectKeys+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("partner key not found")
+	}
+
+	return keys[0], nil
+}
+
+func (r *sqlRepository) FindKeysByPartnerID(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error) {
+	return r.findKeys(ctx, selectKeys+` WHERE partner_id = ? ORDER BY created_at, id`, partnerID)
+}
+
+func (r *sqlRepository) findKeys(ctx context.Context, query string, args ...any) ([]*model.PartnerKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*model.PartnerKey, 0)
+	for rows.Next() {
+		key := new(model.PartnerKey)
+		var scopes string
+		var revoked sql.NullTime
+		if err := rows.Scan(&key.ID, &key.PartnerID, &key.Secret, &scopes, &key.CreatedAt, &revoked); err != nil {
+			return nil, err
+		}
+		key.CreatedAt = key.CreatedAt.UTC()
+		for _, scope := range strings.Split(scopes, " ") {
+			if scope != "" {
+				key.Scopes = append(key.Scopes, model.PartnerScope(scope))
+			}
+		}
+		if revoked.Valid {
+			at := revoked.Time.UTC()
+			key.RevokedAt = &at
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// joinScopes stores the scopes space separated, as none contains a space.
+func joinScopes(scopes []model.PartnerScope) string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, " ")
+}
+
+func revokedAt(key *model.PartnerKey) *time.Time {
+	if key.RevokedAt == nil {
+		return nil
+	}
+	at := key.RevokedAt.UTC()
+	return &at
+}
@@ -0,0 +1,62 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler receives a published message. It runs on the publisher's goroutine,
+// so it must hand slow work off rather than block.
+type Handler func(ctx context.Context, data []byte, metadata map[string]string)
+
+// Broker publishes to the wrapped publisher and then hands every message to
+// the in-process handlers subscribed to its topic.
+type Broker struct {
+	next Mock
+
+	mu       sync.RWMutex
+	handlers map[string]map[int]Handler
+	nextID   int
+}
+
+func NewBroker(next Mock) *Broker {
+	return &Broker{next: next, handlers: make(map[string]map[int]Handler)}
+}
+
+func (b *Broker) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
+	if err := b.next.Publish(ctx, topic, data, metadata); err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.handlers[topic]))
+	for _, handler := range b.handlers[topic] {
+		handlers = append(handlers, handler)
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(ctx, data, metadata)
+	}
+	return nil
+}
+
+// Subscribe calls handler for every later message on topic until the
+// returned func is called.
+func (b *Broker) Subscribe(topic string, handler Handler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.handlers[topic] == nil {
+		b.handlers[topic] = make(map[int]Handler)
+	}
+	id := b.nextID
+	b.nextID++
+	b.handlers[topic][id] = handler
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers[topic], id)
+	}
+}
@@ -0,0 +1,52 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"loan_system/internal/repository/pubsub"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker(t *testing.T) {
+	t.Run("delivers to subscribers of the topic", func(t *testing.T) {
+		broker := pubsub.NewBroker(failingPublisher{})
+
+		var events, others []string
+		broker.Subscribe("loan_events", func(_ context.Context, data []byte, metadata map[string]string) {
+			events = append(events, string(data)+" "+metadata["traceparent"])
+		})
+		broker.Subscribe("loan_invested", func(_ context.Context, data []byte, _ map[string]string) {
+			others = append(others, string(data))
+		})
+
+		assert.NoError(t, broker.Publish(context.Background(), "loan_events", []byte("approved"), map[string]string{"traceparent": "00-1"}))
+
+		assert.Equal(t, []string{"approved 00-1"}, events)
+		assert.Empty(t, others)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		broker := pubsub.NewBroker(failingPublisher{})
+
+		var received int
+		unsubscribe := broker.Subscribe("loan_events", func(context.Context, []byte, map[string]string) { received++ })
+		assert.NoError(t, broker.Publish(context.Background(), "loan_events", nil, nil))
+		unsubscribe()
+		assert.NoError(t, broker.Publish(context.Background(), "loan_events", nil, nil))
+
+		assert.Equal(t, 1, received)
+	})
+
+	t.Run("failed publish is not delivered", func(t *testing.T) {
+		broker := pubsub.NewBroker(failingPublisher{err: errors.New("broker down")})
+
+		var received int
+		broker.Subscribe("loan_events", func(context.Context, []byte, map[string]string) { received++ })
+
+		assert.EqualError(t, broker.Publish(context.Background(), "loan_events", nil, nil), "broker down")
+		assert.Zero(t, received)
+	})
+}
@@ -0,0 +1,25 @@
+package pubsub
+
+import (
+	"context"
+
+	"loan_system/internal/pkg/metrics"
+)
+
+type metricsPublisher struct {
+	next    Mock
+	metrics metrics.Metrics
+}
+
+// WithMetrics counts failed publishes of the wrapped publisher.
+func WithMetrics(next Mock, m metrics.Metrics) Mock {
+	return &metricsPublisher{next: next, metrics: m}
+}
+
+func (p *metricsPublisher) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
+	err := p.next.Publish(ctx, topic, data, metadata)
+	if err != nil {
+		p.metrics.PublishFailed(topic)
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metricsmock "loan_system/internal/pkg/metrics/mock"
+	"loan_system/internal/repository/pubsub"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type failingPublisher struct{ err error }
+
+func (p failingPublisher) Publish(context.Context, string, []byte, map[string]string) error {
+	return p.err
+}
+
+func TestWithMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	metricsMock := metricsmock.NewMockMetrics(ctrl)
+
+	t.Run("success", func(t *testing.T) {
+		publisher := pubsub.WithMetrics(failingPublisher{}, metricsMock)
+		assert.NoError(t, publisher.Publish(context.Background(), "loan_invested", []byte("{}"), nil))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		metricsMock.EXPECT().PublishFailed("loan_invested")
+
+		publisher := pubsub.WithMetrics(failingPublisher{err: errors.New("broker down")}, metricsMock)
+		assert.EqualError(t, publisher.Publish(context.Background(), "loan_invested", []byte("{}"), nil), "broker down")
+	})
+}
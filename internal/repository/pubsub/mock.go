@@ -2,21 +2,25 @@ package pubsub
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 )
 
+// Mock publishes data to a topic. Metadata travels next to the payload, like
+// message attributes or headers on a real broker, and may be nil.
 type Mock interface {
-	Publish(ctx context.Context, topic string, data []byte) error
+	Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error
 }
 
-type mock struct{}
+type mock struct {
+	log *slog.Logger
+}
 
-func NewMock() Mock {
-	return &mock{}
+func NewMock(log *slog.Logger) Mock {
+	return &mock{log: log}
 }
 
-func (m *mock) Publish(ctx context.Context, topic string, data []byte) error {
+func (m *mock) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) error {
 	// mock publish
-	fmt.Println("mock publish to topic:", topic, "data:", string(data))
+	m.log.InfoContext(ctx, "mock publish", "topic", topic, "data", string(data), "metadata", metadata)
 	return nil
 }
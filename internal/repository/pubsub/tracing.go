@@ -0,0 +1,34 @@
+package pubsub
+
+import (
+	"context"
+	"maps"
+
+	"loan_system/internal/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type tracingPublisher struct {
+	next Mock
+}
+
+// WithTracing wraps every publish in a producer span and copies the trace
+// context into the event metadata so consumers can continue the trace.
+func WithTracing(next Mock) Mock {
+	return &tracingPublisher{next: next}
+}
+
+func (p *tracingPublisher) Publish(ctx context.Context, topic string, data []byte, metadata map[string]string) (err error) {
+	ctx, span := tracing.Start(ctx, "publish "+topic,
+		attribute.String("messaging.destination.name", topic),
+		attribute.Int("messaging.message.body.size", len(data)),
+	)
+	defer tracing.End(span, &err)
+
+	carrier := make(map[string]string, len(metadata)+2)
+	maps.Copy(carrier, metadata)
+	tracing.Inject(ctx, carrier)
+
+	return p.next.Publish(ctx, topic, data, carrier)
+}
@@ -0,0 +1,55 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+
+	"loan_system/internal/pkg/config"
+	"loan_system/internal/pkg/tracing"
+	"loan_system/internal/repository/pubsub"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordingPublisher struct {
+	metadata map[string]string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, _ string, _ []byte, metadata map[string]string) error {
+	p.metadata = metadata
+	return nil
+}
+
+func TestWithTracing(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), config.Tracing{Exporter: "none"})
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := tracing.Start(context.Background(), "invest")
+	defer parent.End()
+
+	next := &recordingPublisher{}
+	metadata := map[string]string{"source": "test"}
+	err = pubsub.WithTracing(next).Publish(ctx, "loan_invested", []byte("{}"), metadata)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "test", next.metadata["source"])
+	assert.NotContains(t, metadata, "traceparent")
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "publish loan_invested", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+
+	consumer := trace.SpanContextFromContext(tracing.Extract(context.Background(), next.metadata))
+	assert.Equal(t, spans[0].SpanContext().TraceID(), consumer.TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), consumer.SpanID())
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"loan_system/internal/model"
+)
+
+//go:generate mockgen -source=blob.go -destination=mock/blob_mock.go -package=mock
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) (model.Blob, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+type localBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) (BlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &localBlobStore{root: root}, nil
+}
+
+func (s *localBlobStore) Put(ctx context.Context, key string, r io.Reader) (model.Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return model.Blob{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return model.Blob{}, err
+	}
+
+	// write to a temporary file first so readers never observe a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return model.Blob{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		tmp.Close()
+		return model.Blob{}, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return model.Blob{}, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return model.Blob{}, err
+	}
+
+	return model.Blob{
+		Key:    key,
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+func (s *localBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("blob not found")
+	}
+
+	return f, err
+}
+
+func (s *localBlobStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", errors.New("invalid blob key")
+	}
+
+	return filepath.Join(s.root, cleaned), nil
+}
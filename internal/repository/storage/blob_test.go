@@ -0,0 +1,65 @@
+package storage_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"loan_system/internal/repository/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	store, err := storage.NewLocalBlobStore(t.TempDir())
+	assert.NoError(t, err)
+
+	t.Run("Put and Open", func(t *testing.T) {
+		content := "agreement letter"
+		sum := sha256.Sum256([]byte(content))
+
+		blob, err := store.Put(context.TODO(), "agreements/1/2.html", strings.NewReader(content))
+		assert.NoError(t, err)
+		assert.Equal(t, "agreements/1/2.html", blob.Key)
+		assert.Equal(t, int64(len(content)), blob.Size)
+		assert.Equal(t, hex.EncodeToString(sum[:]), blob.SHA256)
+
+		rc, err := store.Open(context.TODO(), blob.Key)
+		assert.NoError(t, err)
+		defer rc.Close()
+
+		data, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("Put overwrites existing blob", func(t *testing.T) {
+		_, err := store.Put(context.TODO(), "overwrite.txt", strings.NewReader("first"))
+		assert.NoError(t, err)
+		_, err = store.Put(context.TODO(), "overwrite.txt", strings.NewReader("second"))
+		assert.NoError(t, err)
+
+		rc, err := store.Open(context.TODO(), "overwrite.txt")
+		assert.NoError(t, err)
+		defer rc.Close()
+
+		data, _ := io.ReadAll(rc)
+		assert.Equal(t, "second", string(data))
+	})
+
+	t.Run("Open non-existent blob", func(t *testing.T) {
+		_, err := store.Open(context.TODO(), "missing.txt")
+		assert.ErrorContains(t, err, "blob not found")
+	})
+
+	t.Run("Reject key outside root", func(t *testing.T) {
+		_, err := store.Put(context.TODO(), "../escape.txt", strings.NewReader("x"))
+		assert.ErrorContains(t, err, "invalid blob key")
+
+		_, err = store.Open(context.TODO(), "/etc/passwd")
+		assert.ErrorContains(t, err, "invalid blob key")
+	})
+}
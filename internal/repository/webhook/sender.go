@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+//go:generate mockgen -source=sender.go -destination=mock/sender_mock.go -package=mock
+type Sender interface {
+	// Send POSTs body to url and returns the response status code. An error
+	// means no response was received.
+	Send(ctx context.Context, url string, header http.Header, body []byte) (int, error)
+}
+
+type httpSender struct {
+	client        *http.Client
+	allowInsecure bool
+}
+
+// NewHTTPSender sends with a client that gives up on a receiver after
+// timeout and does not follow redirects. Unless allowInsecure is set, it
+// only sends to https URLs and refuses to connect to addresses that are not
+// public. The address is checked as it is dialled, so a host name that
+// resolves to a public address when subscribing and to an internal one later
+// is still refused.
+func NewHTTPSender(timeout time.Duration, allowInsecure bool) Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowInsecure {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublic(addrPort.Addr()) {
+				return fmt.Errorf("refusing to connect to non-public address %s", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			// no proxy, so the dialled address is the receiver's
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConnsPerHost: 4,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowInsecure: allowInsecure,
+	}
+}
+
+// IsPublic reports whether addr may be sent webhooks: it must not be
+// loopback, private, link-local (which includes cloud metadata endpoints
+// such as 169.254.169.254), shared, unspecified or multicast.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which like the private
+// ranges is only reachable from inside a network.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func (s *httpSender) Send(ctx context.Context, target string, header http.Header, body []byte) (int, error) {
+	if !s.allowInsecure {
+		if parsed, err := url.Parse(target); err != nil || parsed.Scheme != "https" {
+			return 0, fmt.Errorf("refusing to send to %q: webhook urls must use https", target)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header = header.Clone()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
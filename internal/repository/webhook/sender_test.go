@@ -0,0 +1,121 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"loan_system/internal/repository/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSender(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/fail":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	// the receiver is on loopback
+	sender := webhook.NewHTTPSender(100*time.Millisecond, true)
+	header := http.Header{"Content-Type": {"application/json"}, "X-Webhook-Id": {"1"}}
+
+	t.Run("delivered", func(t *testing.T) {
+		status, err := sender.Send(context.Background(), receiver.URL+"/ok", header, []byte(`{"id":1}`))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, `{"id":1}`, gotBody)
+		assert.Equal(t, "1", gotHeader.Get("X-Webhook-Id"))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		status, err := sender.Send(context.Background(), receiver.URL+"/fail", header, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		status, err := sender.Send(context.Background(), receiver.URL+"/redirect", header, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusFound, status)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := sender.Send(context.Background(), receiver.URL+"/slow", header, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTPSenderRefusesInternalReceivers(t *testing.T) {
+	receiver := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the receiver should not have been reached")
+	}))
+	defer receiver.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the receiver should not have been reached")
+	}))
+	defer plain.Close()
+
+	sender := webhook.NewHTTPSender(time.Second, false)
+
+	t.Run("http", func(t *testing.T) {
+		_, err := sender.Send(context.Background(), plain.URL, http.Header{}, nil)
+		assert.ErrorContains(t, err, "must use https")
+	})
+
+	t.Run("loopback", func(t *testing.T) {
+		_, err := sender.Send(context.Background(), receiver.URL, http.Header{}, nil)
+		assert.ErrorContains(t, err, "refusing to connect to non-public address 127.0.0.1")
+	})
+
+	t.Run("host name resolving to loopback", func(t *testing.T) {
+		_, port, _ := strings.Cut(strings.TrimPrefix(receiver.URL, "https://"), ":")
+		_, err := sender.Send(context.Background(), "https://localhost:"+port, http.Header{}, nil)
+		assert.ErrorContains(t, err, "refusing to connect to non-public address")
+	})
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, webhook.IsPublic(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
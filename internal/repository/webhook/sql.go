@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"loan_system/internal/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+type sqlRepository struct {
+	db            *sql.DB
+	snowflakeNode *snowflake.Node
+}
+
+func NewSQLRepository(db *sql.DB, log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(5)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &sqlRepository{
+		db:            db,
+		snowflakeNode: node,
+	}
+}
+
+const (
+	selectSubscriptions = `SELECT id, partner_id, url, event_types, secret, created_at FROM webhook_subscriptions`
+	selectDeliveries    = `SELECT id, subscription_id, partner_id, event_id, event_type, payload, status, attempt_count, attempts, next_attempt_at, delivered_at, created_at FROM webhook_deliveries`
+)
+
+func (r *sqlRepository) SaveSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	if subscription.ID == 0 {
+		subscription.ID = r.snowflakeNode.Generate().Int64()
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhook_subscriptions (id, partner_id, url, event_types, secret, created_at) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		subscription.ID, subscription.PartnerID, subscription.URL, joinEventTypes(subscription.EventTypes), subscription.Secret, subscription.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("webhook subscription already exists")
+	}
+	return nil
+}
+
+func (r *sqlRepository) FindSubscriptionByID(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	subscriptions, err := r.findSubscriptions(ctx, selectSubscriptions+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, errors.New("webhook subscription not found")
+	}
+
+	return subscriptions[0], nil
+}
+
+func (r *sqlRepository) FindSubscriptions(ctx context.Context, partnerID int64) ([]*model.WebhookSubscription, error) {
+	if partnerID == 0 {
+		return r.findSubscriptions(ctx, selectSubscriptions+` ORDER BY created_at, id`)
+	}
+	return r.findSubscriptions(ctx, selectSubscriptions+` WHERE partner_id = ? ORDER BY created_at, id`, partnerID)
+}
+
+// DeleteSubscription keeps the subscription's deliveries, so its delivery log
+// can still be read.
+func (r *sqlRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("webhook subscription not found")
+	}
+	return nil
+}
+
+func (r *sqlRepository) SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	if delivery.ID == 0 {
+		delivery.ID = r.snowflakeNode.Generate().Int64()
+	}
+	attempts, err := json.Marshal(delivery.Attempts)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (id, subscription_id, partner_id, event_id, event_type, payload, status, attempt_count, attempts, next_attempt_at, delivered_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		delivery.ID, delivery.SubscriptionID, delivery.PartnerID, delivery.EventID, delivery.EventType, []byte(delivery.Payload),
+		delivery.Status, delivery.AttemptCount, string(attempts), utc(delivery.NextAttemptAt), utc(delivery.DeliveredAt), delivery.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("webhook delivery already exists")
+	}
+	return nil
+}
+
+func (r *sqlRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	attempts, err := json.Marshal(delivery.Attempts)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status = ?, attempt_count = ?, attempts = ?, next_attempt_at = ?, delivered_at = ? WHERE id = ?`,
+		delivery.Status, delivery.AttemptCount, string(attempts), utc(delivery.NextAttemptAt), utc(delivery.DeliveredAt), delivery.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return errors.New("webhook delivery not found")
+	}
+	return nil
+}
+
+func (r *sqlRepository) FindDeliveryByID(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	deliveries, err := r.findDeliveries(ctx, selectDeliveries+` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, errors.New("webhook delivery not found")
+	}
+
+	return deliveries[0], nil
+}
+
+func (r *sqlRepository) FindDeliveries(ctx context.Context, query model.WebhookDeliveryQuery) ([]*model.WebhookDelivery, error) {
+	var where []string
+	var args []any
+	if query.PartnerID != 0 {
+		where = append(where, `partner_id = ?`)
+		args = append(args, query.PartnerID)
+	}
+	if query.SubscriptionID != 0 {
+		where = append(where, `subscription_id = ?`)
+		args = append(args, query.SubscriptionID)
+	}
+	if query.Status != "" {
+		where = append(where, `status = ?`)
+		args = append(args, query.Status)
+	}
+
+	statement := selectDeliveries
+	if len(where) > 0 {
+		statement += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	return r.findDeliveries(ctx, statement+` ORDER BY created_at, id`, args...)
+}
+
+func (r *sqlRepository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	return r.findDeliveries(ctx,
+		selectDeliveries+` WHERE status = ? AND next_attempt_at <= ? ORDER BY created_at, id LIMIT ?`,
+		model.WebhookDeliveryPending, now.UTC(), limit,
+	)
+}
+
+func (r *sqlRepository) findSubscriptions(ctx context.Context, query string, args ...any) ([]*model.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]*model.WebhookSubscription, 0)
+	for rows.Next() {
+		subscription := new(model.WebhookSubscription)
+		var eventTypes string
+		if err := rows.Scan(&subscription.ID, &subscription.PartnerID, &subscription.URL, &eventTypes, &subscription.Secret, &subscription.CreatedAt); err != nil {
+			return nil, err
+		}
+		subscription.CreatedAt = subscription.CreatedAt.UTC()
+		for _, eventType := range strings.Split(eventTypes, " ") {
+			if eventType != "" {
+				subscription.EventTypes = append(subscription.EventTypes, model.LoanEventType(eventType))
+			}
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (r *sqlRepository) findDeliveries(ctx context.Context, query string, args ...any) ([]*model.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := new(model.WebhookDelivery)
+		var payload []byte
+		var attempts string
+		var nextAttemptAt, deliveredAt sql.NullTime
+		if err := rows.Scan(
+			&delivery.ID, &delivery.SubscriptionID, &delivery.PartnerID, &delivery.EventID, &delivery.EventType, &payload,
+			&delivery.Status, &delivery.AttemptCount, &attempts, &nextAttemptAt, &deliveredAt, &delivery.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(attempts), &delivery.Attempts); err != nil {
+			return nil, err
+		}
+		delivery.Payload = payload
+		delivery.CreatedAt = delivery.CreatedAt.UTC()
+		delivery.NextAttemptAt = nullTime(nextAttemptAt)
+		delivery.DeliveredAt = nullTime(deliveredAt)
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// joinEventTypes stores the event types space separated, as none contains a
+// space.
+func joinEventTypes(eventTypes []model.LoanEventType) string {
+	names := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		names[i] = string(eventType)
+	}
+	return strings.Join(names, " ")
+}
+
+func utc(at *time.Time) *time.Time {
+	if at == nil {
+		return nil
+	}
+	converted := at.UTC()
+	return &converted
+}
+
+func nullTime(at sql.NullTime) *time.Time {
+	if !at.Valid {
+		return nil
+	}
+	converted := at.Time.UTC()
+	return &converted
+}
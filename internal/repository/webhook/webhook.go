@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"loan_system/internal/model"
+
+	"github.com/bwmarrin/snowflake"
+)
+
+//go:generate mockgen -source=webhook.go -destination=mock/webhook_mock.go -package=mock
+type Repository interface {
+	SaveSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	FindSubscriptionByID(ctx context.Context, id int64) (*model.WebhookSubscription, error)
+	// FindSubscriptions returns the partner's subscriptions, or every
+	// subscription when partnerID is 0.
+	FindSubscriptions(ctx context.Context, partnerID int64) ([]*model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	FindDeliveryByID(ctx context.Context, id int64) (*model.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, query model.WebhookDeliveryQuery) ([]*model.WebhookDelivery, error)
+	// FindDueDeliveries returns up to limit pending deliveries whose next
+	// attempt is due at now.
+	FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error)
+}
+
+// repository keeps subscriptions and deliveries in creation order and hands
+// out copies, so callers may clear secrets or change a delivery before
+// storing it back.
+type repository struct {
+	mu                sync.RWMutex
+	snowflakeNode     *snowflake.Node
+	subscriptions     map[int64]*model.WebhookSubscription
+	subscriptionOrder []int64
+	deliveries        map[int64]*model.WebhookDelivery
+	deliveryOrder     []int64
+}
+
+func NewRepository(log *slog.Logger) Repository {
+	node, err := snowflake.NewNode(5)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &repository{
+		snowflakeNode: node,
+		subscriptions: make(map[int64]*model.WebhookSubscription),
+		deliveries:    make(map[int64]*model.WebhookDelivery),
+	}
+}
+
+func (r *repository) SaveSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if subscription.ID == 0 {
+		subscription.ID = r.snowflakeNode.Generate().Int64()
+	}
+	if _, exists := r.subscriptions[subscription.ID]; exists {
+		return errors.New("webhook subscription already exists")
+	}
+
+	stored := *subscription
+	r.subscriptions[subscription.ID] = &stored
+	r.subscriptionOrder = append(r.subscriptionOrder, subscription.ID)
+	return nil
+}
+
+func (r *repository) FindSubscriptionByID(ctx context.Context, id int64) (*model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists {
+		return nil, errors.New("webhook subscription not found")
+	}
+
+	found := *subscription
+	return &found, nil
+}
+
+func (r *repository) FindSubscriptions(ctx context.Context, partnerID int64) ([]*model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := make([]*model.WebhookSubscription, 0)
+	for _, id := range r.subscriptionOrder {
+		subscription := r.subscriptions[id]
+		if partnerID != 0 && subscription.PartnerID != partnerID {
+			continue
+		}
+		found := *subscription
+		subscriptions = append(subscriptions, &found)
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteSubscription keeps the subscription's deliveries, so its delivery log
+// can still be read.
+func (r *repository) DeleteSubscription(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subscriptions[id]; !exists {
+		return errors.New("webhook subscription not found")
+	}
+
+	delete(r.subscriptions, id)
+	r.subscriptionOrder = slices.DeleteFunc(r.subscriptionOrder, func(other int64) bool { return other == id })
+	return nil
+}
+
+func (r *repository) SaveDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.ID == 0 {
+		delivery.ID = r.snowflakeNode.Generate().Int64()
+	}
+	if _, exists := r.deliveries[delivery.ID]; exists {
+		return errors.New("webhook delivery already exists")
+	}
+
+	r.deliveries[delivery.ID] = copyDelivery(delivery)
+	r.deliveryOrder = append(r.deliveryOrder, delivery.ID)
+	return nil
+}
+
+func (r *repository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.deliveries[delivery.ID]; !exists {
+		return errors.New("webhook delivery not found")
+	}
+
+	r.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+func (r *repository) FindDeliveryByID(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, exists := r.deliveries[id]
+	if !exists {
+		return nil, errors.New("webhook delivery not found")
+	}
+
+	return copyDelivery(delivery), nil
+}
+
+func (r *repository) FindDeliveries(ctx context.Context, query model.WebhookDeliveryQuery) ([]*model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for _, id := range r.deliveryOrder {
+		delivery := r.deliveries[id]
+		if query.PartnerID != 0 && delivery.PartnerID != query.PartnerID {
+			continue
+		}
+		if query.SubscriptionID != 0 && delivery.SubscriptionID != query.SubscriptionID {
+			continue
+		}
+		if query.Status != "" && delivery.Status != query.Status {
+			continue
+		}
+		deliveries = append(deliveries, copyDelivery(delivery))
+	}
+
+	return deliveries, nil
+}
+
+func (r *repository) FindDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]*model.WebhookDelivery, 0)
+	for _, id := range r.deliveryOrder {
+		if len(deliveries) == limit {
+			break
+		}
+		delivery := r.deliveries[id]
+		if delivery.Status != model.WebhookDeliveryPending || delivery.NextAttemptAt == nil || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		deliveries = append(deliveries, copyDelivery(delivery))
+	}
+
+	return deliveries, nil
+}
+
+func copyDelivery(delivery *model.WebhookDelivery) *model.WebhookDelivery {
+	copied := *delivery
+	copied.Attempts = slices.Clone(delivery.Attempts)
+	return &copied
+}
@@ -0,0 +1,155 @@
+package webhook_test
+
+import (
+	"context"
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/migration"
+	"loan_system/internal/repository/webhook"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	testRepository(t, webhook.NewRepository(logger.Discard()))
+}
+
+func TestSQLRepository(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migration.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	testRepository(t, webhook.NewSQLRepository(db, logger.Discard()))
+}
+
+// testRepository holds the behaviour every Repository implementation must share.
+func testRepository(t *testing.T, repo webhook.Repository) {
+	createdAt := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	first := &model.WebhookSubscription{PartnerID: 9, URL: "https://partner.example/hooks", EventTypes: []model.LoanEventType{model.LoanEventApproved, model.LoanEventDisbursed}, Secret: "whsec_1", CreatedAt: createdAt}
+	second := &model.WebhookSubscription{PartnerID: 8, URL: "https://other.example/hooks", EventTypes: []model.LoanEventType{model.LoanEventDisbursed}, Secret: "whsec_2", CreatedAt: createdAt}
+
+	t.Run("SaveSubscription and FindSubscriptionByID", func(t *testing.T) {
+		assert.NoError(t, repo.SaveSubscription(context.TODO(), first))
+		assert.NoError(t, repo.SaveSubscription(context.TODO(), second))
+		assert.NotZero(t, first.ID)
+
+		found, err := repo.FindSubscriptionByID(context.TODO(), first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, first, found)
+
+		// callers get copies
+		found.Secret = ""
+		found, _ = repo.FindSubscriptionByID(context.TODO(), first.ID)
+		assert.Equal(t, "whsec_1", found.Secret)
+
+		assert.ErrorContains(t, repo.SaveSubscription(context.TODO(), first), "webhook subscription already exists")
+	})
+
+	t.Run("FindSubscriptions", func(t *testing.T) {
+		all, err := repo.FindSubscriptions(context.TODO(), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.WebhookSubscription{first, second}, all)
+
+		mine, err := repo.FindSubscriptions(context.TODO(), 9)
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.WebhookSubscription{first}, mine)
+	})
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Second)
+	later := now.Add(time.Minute)
+	pending := &model.WebhookDelivery{SubscriptionID: first.ID, PartnerID: 9, EventID: 1, EventType: model.LoanEventApproved, Payload: []byte(`{"id":1}`), Status: model.WebhookDeliveryPending, NextAttemptAt: &due, CreatedAt: createdAt}
+	waiting := &model.WebhookDelivery{SubscriptionID: first.ID, PartnerID: 9, EventID: 2, Status: model.WebhookDeliveryPending, NextAttemptAt: &later, CreatedAt: createdAt}
+	failed := &model.WebhookDelivery{SubscriptionID: second.ID, PartnerID: 8, EventID: 3, Status: model.WebhookDeliveryFailed, CreatedAt: createdAt}
+
+	t.Run("SaveDelivery and FindDeliveryByID", func(t *testing.T) {
+		for _, delivery := range []*model.WebhookDelivery{pending, waiting, failed} {
+			assert.NoError(t, repo.SaveDelivery(context.TODO(), delivery))
+		}
+
+		found, err := repo.FindDeliveryByID(context.TODO(), pending.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, pending, found)
+
+		_, err = repo.FindDeliveryByID(context.TODO(), 42)
+		assert.ErrorContains(t, err, "webhook delivery not found")
+	})
+
+	t.Run("FindDueDeliveries", func(t *testing.T) {
+		found, err := repo.FindDueDeliveries(context.TODO(), now, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.WebhookDelivery{pending}, found)
+
+		found, err = repo.FindDueDeliveries(context.TODO(), later, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*model.WebhookDelivery{pending}, found)
+	})
+
+	t.Run("UpdateDelivery", func(t *testing.T) {
+		found, _ := repo.FindDeliveryByID(context.TODO(), pending.ID)
+		found.Status = model.WebhookDeliverySucceeded
+		found.AttemptCount = 1
+		found.Attempts = append(found.Attempts, model.WebhookAttempt{At: now, StatusCode: 200})
+		found.NextAttemptAt = nil
+		found.DeliveredAt = &now
+		assert.NoError(t, repo.UpdateDelivery(context.TODO(), found))
+
+		// the attempt log is copied too
+		found.Attempts[0].StatusCode = 500
+		stored, _ := repo.FindDeliveryByID(context.TODO(), pending.ID)
+		assert.Equal(t, 200, stored.Attempts[0].StatusCode)
+		found.Attempts[0].StatusCode = 200
+		assert.Equal(t, found, stored)
+
+		assert.ErrorContains(t, repo.UpdateDelivery(context.TODO(), &model.WebhookDelivery{ID: 42}), "webhook delivery not found")
+	})
+
+	t.Run("FindDeliveries", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			query model.WebhookDeliveryQuery
+			want  []int64
+		}{
+			{name: "all", query: model.WebhookDeliveryQuery{}, want: []int64{1, 2, 3}},
+			{name: "by partner", query: model.WebhookDeliveryQuery{PartnerID: 9}, want: []int64{1, 2}},
+			{name: "by subscription", query: model.WebhookDeliveryQuery{SubscriptionID: second.ID}, want: []int64{3}},
+			{name: "dead letters", query: model.WebhookDeliveryQuery{Status: model.WebhookDeliveryFailed}, want: []int64{3}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := repo.FindDeliveries(context.TODO(), tt.query)
+				assert.NoError(t, err)
+				var eventIDs []int64
+				for _, delivery := range found {
+					eventIDs = append(eventIDs, delivery.EventID)
+				}
+				assert.Equal(t, tt.want, eventIDs)
+			})
+		}
+	})
+
+	t.Run("DeleteSubscription keeps deliveries", func(t *testing.T) {
+		assert.NoError(t, repo.DeleteSubscription(context.TODO(), first.ID))
+		assert.ErrorContains(t, repo.DeleteSubscription(context.TODO(), first.ID), "webhook subscription not found")
+
+		_, err := repo.FindSubscriptionByID(context.TODO(), first.ID)
+		assert.ErrorContains(t, err, "webhook subscription not found")
+		remaining, _ := repo.FindSubscriptions(context.TODO(), 0)
+		assert.Equal(t, []*model.WebhookSubscription{second}, remaining)
+
+		deliveries, _ := repo.FindDeliveries(context.TODO(), model.WebhookDeliveryQuery{SubscriptionID: first.ID})
+		assert.Len(t, deliveries, 2)
+	})
+}
@@ -0,0 +1,130 @@
+package agreement
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"loan_system/internal/model"
+	renderer "loan_system/internal/pkg/agreement"
+	"loan_system/internal/repository/agreement"
+	"loan_system/internal/repository/loan"
+	"loan_system/internal/repository/storage"
+)
+
+var formats = []model.AgreementFormat{model.AgreementFormatHTML, model.AgreementFormatPDF}
+
+//go:generate mockgen -source=agreement.go -destination=mock/agreement_mock.go -package=mock
+type Usecase interface {
+	Generate(ctx context.Context, loan *model.Loan) ([]*model.Agreement, error)
+	FindByInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error)
+	OpenDocument(ctx context.Context, agreement *model.Agreement, format model.AgreementFormat) (io.ReadCloser, error)
+}
+
+type usecase struct {
+	repo  agreement.Repository
+	loans loan.Repository
+	blobs storage.BlobStore
+	now   func() time.Time
+}
+
+func NewUsecase(repo agreement.Repository, loans loan.Repository, blobs storage.BlobStore) Usecase {
+	return &usecase{repo: repo, loans: loans, blobs: blobs, now: time.Now}
+}
+
+// Generate renders one agreement letter per investor of a fully invested, or
+// disbursed, loan and stores every format in the blob store.
+func (uc *usecase) Generate(ctx context.Context, loan *model.Loan) ([]*model.Agreement, error) {
+	if !funded(loan) {
+		return nil, errors.New("agreements can only be generated for invested loans")
+	}
+
+	generatedAt := uc.now().UTC()
+	agreements := make([]*model.Agreement, 0, len(loan.Investments))
+	for _, investorID := range loan.InvestorIDs() {
+		letter := renderer.NewLetter(loan, investorID, generatedAt)
+		agreement := &model.Agreement{
+			LoanID:         loan.ID,
+			InvestorID:     investorID,
+			Amount:         letter.Amount,
+			ExpectedReturn: letter.ExpectedReturn,
+			GeneratedAt:    generatedAt,
+		}
+
+		for _, format := range formats {
+			content, err := renderer.Render(letter, format)
+			if err != nil {
+				return nil, fmt.Errorf("render %s agreement failed: %w", format, err)
+			}
+
+			key := fmt.Sprintf("agreements/%d/%d.%s", loan.ID, investorID, format)
+			blob, err := uc.blobs.Put(ctx, key, bytes.NewReader(content))
+			if err != nil {
+				return nil, fmt.Errorf("store %s agreement failed: %w", format, err)
+			}
+
+			agreement.Documents = append(agreement.Documents, model.AgreementDocument{
+				Format:      format,
+				ContentType: renderer.ContentType(format),
+				ContentHash: blob.SHA256,
+				Size:        blob.Size,
+				BlobKey:     blob.Key,
+			})
+		}
+
+		if err := uc.repo.Save(ctx, agreement); err != nil {
+			return nil, err
+		}
+		agreements = append(agreements, agreement)
+	}
+
+	return agreements, nil
+}
+
+// FindByInvestor returns the investor's agreement. The agreements of a funded
+// loan that has none, such as when generating them failed once it was fully
+// invested, are generated first.
+func (uc *usecase) FindByInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error) {
+	found, err := uc.repo.FindByLoanAndInvestor(ctx, loanID, investorID)
+	if !errors.Is(err, agreement.ErrNotFound) {
+		return found, err
+	}
+
+	stored, err := uc.loans.FindByID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if !funded(stored) || !slices.Contains(stored.InvestorIDs(), investorID) {
+		return nil, agreement.ErrNotFound
+	}
+
+	generated, err := uc.Generate(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	for _, letter := range generated {
+		if letter.InvestorID == investorID {
+			return letter, nil
+		}
+	}
+	return nil, agreement.ErrNotFound
+}
+
+// funded reports whether the loan is fully invested, so its agreements can
+// be generated.
+func funded(loan *model.Loan) bool {
+	return loan.State == model.StateInvested || loan.State == model.StateDisbursed
+}
+
+func (uc *usecase) OpenDocument(ctx context.Context, agreement *model.Agreement, format model.AgreementFormat) (io.ReadCloser, error) {
+	document, ok := agreement.Document(format)
+	if !ok {
+		return nil, errors.New("agreement document not found")
+	}
+
+	return uc.blobs.Open(ctx, document.BlobKey)
+}
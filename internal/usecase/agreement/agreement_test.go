@@ -0,0 +1,107 @@
+package agreement_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	agreementrepo "loan_system/internal/repository/agreement"
+	loanrepo "loan_system/internal/repository/loan"
+	"loan_system/internal/repository/storage"
+	"loan_system/internal/usecase/agreement"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgreementUsecase(t *testing.T) {
+	blobs, err := storage.NewLocalBlobStore(t.TempDir())
+	assert.NoError(t, err)
+
+	loans := loanrepo.NewRepository(logger.Discard())
+	uc := agreement.NewUsecase(agreementrepo.NewRepository(), loans, blobs)
+
+	loan := &model.Loan{
+		ID:        1,
+		Principal: 1000,
+		ROI:       0.1,
+		State:     model.StateInvested,
+		Investments: []model.Investment{
+			{InvestorID: 10, Amount: 600},
+			{InvestorID: 20, Amount: 400},
+		},
+	}
+
+	assert.NoError(t, loans.Save(context.Background(), loan))
+
+	t.Run("Generate", func(t *testing.T) {
+		agreements, err := uc.Generate(context.Background(), loan)
+		assert.NoError(t, err)
+		assert.Len(t, agreements, 2)
+
+		assert.Equal(t, int64(10), agreements[0].InvestorID)
+		assert.Equal(t, float64(600), agreements[0].Amount)
+		assert.Equal(t, float64(60), agreements[0].ExpectedReturn)
+		assert.Len(t, agreements[0].Documents, 2)
+		for _, doc := range agreements[0].Documents {
+			assert.Len(t, doc.ContentHash, 64)
+			assert.NotZero(t, doc.Size)
+		}
+	})
+
+	t.Run("Generate InvalidState", func(t *testing.T) {
+		_, err := uc.Generate(context.Background(), &model.Loan{ID: 2, State: model.StateApproved})
+		assert.ErrorContains(t, err, "invested loans")
+	})
+
+	t.Run("FindByInvestor and OpenDocument", func(t *testing.T) {
+		found, err := uc.FindByInvestor(context.Background(), 1, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(400), found.Amount)
+
+		rc, err := uc.OpenDocument(context.Background(), found, model.AgreementFormatHTML)
+		assert.NoError(t, err)
+		defer rc.Close()
+
+		html, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		assert.Contains(t, string(html), "investor #20 in loan #1")
+	})
+
+	t.Run("FindByInvestor not found", func(t *testing.T) {
+		_, err := uc.FindByInvestor(context.Background(), 1, 30)
+		assert.ErrorContains(t, err, "agreement not found")
+	})
+
+	t.Run("FindByInvestor generates missing agreements", func(t *testing.T) {
+		disbursed := &model.Loan{
+			ID:          3,
+			Principal:   1000,
+			ROI:         0.1,
+			State:       model.StateDisbursed,
+			Investments: []model.Investment{{InvestorID: 10, Amount: 1000}},
+		}
+		assert.NoError(t, loans.Save(context.Background(), disbursed))
+
+		found, err := uc.FindByInvestor(context.Background(), 3, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1000), found.Amount)
+		assert.Len(t, found.Documents, 2)
+
+		_, err = uc.FindByInvestor(context.Background(), 3, 20)
+		assert.ErrorIs(t, err, agreementrepo.ErrNotFound, "only investors of the loan have one")
+	})
+
+	t.Run("FindByInvestor of a loan not yet funded", func(t *testing.T) {
+		assert.NoError(t, loans.Save(context.Background(), &model.Loan{ID: 4, State: model.StateApproved, Investments: []model.Investment{{InvestorID: 10, Amount: 100}}}))
+
+		_, err := uc.FindByInvestor(context.Background(), 4, 10)
+		assert.ErrorIs(t, err, agreementrepo.ErrNotFound)
+	})
+
+	t.Run("OpenDocument unknown format", func(t *testing.T) {
+		_, err := uc.OpenDocument(context.Background(), &model.Agreement{}, model.AgreementFormatPDF)
+		assert.ErrorContains(t, err, "agreement document not found")
+	})
+}
@@ -0,0 +1,88 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: agreement.go
+//
+// Generated by this command:
+//
+//	mockgen -source=agreement.go -destination=mock/agreement_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	model "loan_system/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsecase is a mock of Usecase interface.
+type MockUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockUsecaseMockRecorder is the mock recorder for MockUsecase.
+type MockUsecaseMockRecorder struct {
+	mock *MockUsecase
+}
+
+// NewMockUsecase creates a new mock instance.
+func NewMockUsecase(ctrl *gomock.Controller) *MockUsecase {
+	mock := &MockUsecase{ctrl: ctrl}
+	mock.recorder = &MockUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsecase) EXPECT() *MockUsecaseMockRecorder {
+	return m.recorder
+}
+
+// FindByInvestor mocks base method.
+func (m *MockUsecase) FindByInvestor(ctx context.Context, loanID, investorID int64) (*model.Agreement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByInvestor", ctx, loanID, investorID)
+	ret0, _ := ret[0].(*model.Agreement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByInvestor indicates an expected call of FindByInvestor.
+func (mr *MockUsecaseMockRecorder) FindByInvestor(ctx, loanID, investorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByInvestor", reflect.TypeOf((*MockUsecase)(nil).FindByInvestor), ctx, loanID, investorID)
+}
+
+// Generate mocks base method.
+func (m *MockUsecase) Generate(ctx context.Context, loan *model.Loan) ([]*model.Agreement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate", ctx, loan)
+	ret0, _ := ret[0].([]*model.Agreement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate.
+func (mr *MockUsecaseMockRecorder) Generate(ctx, loan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockUsecase)(nil).Generate), ctx, loan)
+}
+
+// OpenDocument mocks base method.
+func (m *MockUsecase) OpenDocument(ctx context.Context, agreement *model.Agreement, format model.AgreementFormat) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDocument", ctx, agreement, format)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDocument indicates an expected call of OpenDocument.
+func (mr *MockUsecaseMockRecorder) OpenDocument(ctx, agreement, format any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDocument", reflect.TypeOf((*MockUsecase)(nil).OpenDocument), ctx, agreement, format)
+}
@@ -0,0 +1,32 @@
+package borrower
+
+import (
+	"context"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/loan"
+)
+
+//go:generate mockgen -source=borrower.go -destination=mock/borrower_mock.go -package=mock
+type Usecase interface {
+	Dashboard(ctx context.Context, borrowerID int64) (*model.BorrowerDashboard, error)
+}
+
+type usecase struct {
+	loans loan.Repository
+	now   func() time.Time
+}
+
+func NewUsecase(loans loan.Repository) Usecase {
+	return &usecase{loans: loans, now: time.Now}
+}
+
+func (uc *usecase) Dashboard(ctx context.Context, borrowerID int64) (*model.BorrowerDashboard, error) {
+	loans, err := uc.loans.FindByBorrowerID(ctx, borrowerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewBorrowerDashboard(borrowerID, loans, uc.now().UTC()), nil
+}
@@ -0,0 +1,46 @@
+package borrower_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/usecase/borrower"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBorrowerUsecase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loanMock := loanrepo.NewMockRepository(ctrl)
+	uc := borrower.NewUsecase(loanMock)
+
+	t.Run("Dashboard", func(t *testing.T) {
+		loanMock.EXPECT().FindByBorrowerID(gomock.Any(), int64(5)).Return([]*model.Loan{
+			{ID: 1, BorrowerID: 5, Principal: 1200, TenorMonths: 12, State: model.StateDisbursed,
+				Disbursement: &model.Disbursement{DisbursedAt: time.Now().UTC()}},
+			{ID: 2, BorrowerID: 5, Principal: 500, State: model.StateProposed},
+		}, nil)
+
+		dashboard, err := uc.Dashboard(context.Background(), 5)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), dashboard.BorrowerID)
+		assert.Len(t, dashboard.Active, 2)
+		assert.Empty(t, dashboard.Historical)
+		assert.Equal(t, 1200.0, dashboard.Summary.TotalOutstanding)
+		assert.Equal(t, 1, dashboard.Summary.NextInstallment.Number)
+	})
+
+	t.Run("Dashboard repository error", func(t *testing.T) {
+		loanMock.EXPECT().FindByBorrowerID(gomock.Any(), int64(5)).Return(nil, errors.New("boom"))
+
+		_, err := uc.Dashboard(context.Background(), 5)
+		assert.EqualError(t, err, "boom")
+	})
+}
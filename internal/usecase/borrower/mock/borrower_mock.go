@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: borrower.go
+//
+// Generated by this command:
+//
+//	mockgen -source=borrower.go -destination=mock/borrower_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "loan_system/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsecase is a mock of Usecase interface.
+type MockUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockUsecaseMockRecorder is the mock recorder for MockUsecase.
+type MockUsecaseMockRecorder struct {
+	mock *MockUsecase
+}
+
+// NewMockUsecase creates a new mock instance.
+func NewMockUsecase(ctrl *gomock.Controller) *MockUsecase {
+	mock := &MockUsecase{ctrl: ctrl}
+	mock.recorder = &MockUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsecase) EXPECT() *MockUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Dashboard mocks base method.
+func (m *MockUsecase) Dashboard(ctx context.Context, borrowerID int64) (*model.BorrowerDashboard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dashboard", ctx, borrowerID)
+	ret0, _ := ret[0].(*model.BorrowerDashboard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Dashboard indicates an expected call of Dashboard.
+func (mr *MockUsecaseMockRecorder) Dashboard(ctx, borrowerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dashboard", reflect.TypeOf((*MockUsecase)(nil).Dashboard), ctx, borrowerID)
+}
@@ -0,0 +1,94 @@
+package document
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/document"
+	"loan_system/internal/repository/loan"
+	"loan_system/internal/repository/storage"
+)
+
+// allowedContentTypes lists the MIME types accepted for uploaded documents,
+// detected from the file content rather than the client supplied header.
+var allowedContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+//go:generate mockgen -source=document.go -destination=mock/document_mock.go -package=mock
+type Usecase interface {
+	Upload(ctx context.Context, document *model.Document, content io.Reader) error
+	FindByID(ctx context.Context, id int64) (*model.Document, error)
+}
+
+type usecase struct {
+	repo    document.Repository
+	loans   loan.Repository
+	blobs   storage.BlobStore
+	maxSize int64
+	now     func() time.Time
+}
+
+func NewUsecase(repo document.Repository, loans loan.Repository, blobs storage.BlobStore, maxSize int64) Usecase {
+	return &usecase{repo: repo, loans: loans, blobs: blobs, maxSize: maxSize, now: time.Now}
+}
+
+// Upload validates and stores the content for a document whose LoanID, Kind,
+// FileName and Size are already set, filling in the remaining fields.
+func (uc *usecase) Upload(ctx context.Context, document *model.Document, content io.Reader) error {
+	if document.Size <= 0 {
+		return errors.New("document is empty")
+	}
+	if document.Size > uc.maxSize {
+		return fmt.Errorf("document exceeds maximum size of %d bytes", uc.maxSize)
+	}
+
+	if _, err := uc.loans.FindByID(ctx, document.LoanID); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(io.LimitReader(content, uc.maxSize), 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("read document failed: %w", err)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !allowedContentTypes[contentType] {
+		return fmt.Errorf("unsupported document type %s", contentType)
+	}
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("documents/%d/%s", document.LoanID, hex.EncodeToString(suffix))
+	blob, err := uc.blobs.Put(ctx, key, reader)
+	if err != nil {
+		return fmt.Errorf("store document failed: %w", err)
+	}
+
+	document.ContentType = contentType
+	document.Size = blob.Size
+	document.SHA256 = blob.SHA256
+	document.BlobKey = blob.Key
+	document.UploadedAt = uc.now().UTC()
+
+	return uc.repo.Save(ctx, document)
+}
+
+func (uc *usecase) FindByID(ctx context.Context, id int64) (*model.Document, error) {
+	return uc.repo.FindByID(ctx, id)
+}
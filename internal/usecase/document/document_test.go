@@ -0,0 +1,76 @@
+package document_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	documentrepo "loan_system/internal/repository/document"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/repository/storage"
+	"loan_system/internal/usecase/document"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDocumentUsecase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs, err := storage.NewLocalBlobStore(t.TempDir())
+	assert.NoError(t, err)
+
+	loanMock := loanrepo.NewMockRepository(ctrl)
+	uc := document.NewUsecase(documentrepo.NewRepository(logger.Discard()), loanMock, blobs, 1024)
+
+	pdf := []byte("%PDF-1.4\n%proof of approval")
+
+	t.Run("Upload", func(t *testing.T) {
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1}, nil)
+
+		d := &model.Document{LoanID: 1, Kind: model.DocumentKindApprovalProof, FileName: "proof.pdf", Size: int64(len(pdf))}
+		err := uc.Upload(context.Background(), d, bytes.NewReader(pdf))
+		assert.NoError(t, err)
+		assert.NotZero(t, d.ID)
+		assert.Equal(t, "application/pdf", d.ContentType)
+		assert.Len(t, d.SHA256, 64)
+		assert.False(t, d.UploadedAt.IsZero())
+
+		found, err := uc.FindByID(context.Background(), d.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, d, found)
+	})
+
+	t.Run("Upload unsupported type", func(t *testing.T) {
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1}, nil)
+
+		content := []byte("#!/bin/sh\necho hello")
+		d := &model.Document{LoanID: 1, Kind: model.DocumentKindApprovalProof, Size: int64(len(content))}
+		err := uc.Upload(context.Background(), d, bytes.NewReader(content))
+		assert.ErrorContains(t, err, "unsupported document type text/plain")
+	})
+
+	t.Run("Upload too large", func(t *testing.T) {
+		d := &model.Document{LoanID: 1, Kind: model.DocumentKindApprovalProof, Size: 2048}
+		err := uc.Upload(context.Background(), d, bytes.NewReader(pdf))
+		assert.ErrorContains(t, err, "exceeds maximum size")
+	})
+
+	t.Run("Upload empty", func(t *testing.T) {
+		d := &model.Document{LoanID: 1, Kind: model.DocumentKindApprovalProof}
+		err := uc.Upload(context.Background(), d, bytes.NewReader(nil))
+		assert.ErrorContains(t, err, "document is empty")
+	})
+
+	t.Run("Upload loan not found", func(t *testing.T) {
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(nil, errors.New("loan not found"))
+
+		d := &model.Document{LoanID: 2, Kind: model.DocumentKindApprovalProof, Size: int64(len(pdf))}
+		err := uc.Upload(context.Background(), d, bytes.NewReader(pdf))
+		assert.ErrorContains(t, err, "loan not found")
+	})
+}
@@ -0,0 +1,192 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"loan_system/internal/model"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupSize bounds the rows the Parquet writer buffers before
+// writing them out as a row group.
+const parquetRowGroupSize = 10000
+
+// encoder writes loans in an export format. Close writes whatever is still
+// buffered and must be called once every loan has been encoded.
+type encoder interface {
+	Encode(loan *model.Loan) error
+	Close() error
+}
+
+func newEncoder(format model.ExportFormat, w io.Writer) encoder {
+	switch format {
+	case model.ExportNDJSON:
+		return &ndjsonEncoder{json.NewEncoder(w)}
+	case model.ExportParquet:
+		return &parquetEncoder{parquet.NewGenericWriter[parquetLoan](w, parquet.MaxRowsPerRowGroup(parquetRowGroupSize))}
+	default:
+		return &csvEncoder{w: csv.NewWriter(w)}
+	}
+}
+
+type ndjsonEncoder struct {
+	json *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(loan *model.Loan) error {
+	return e.json.Encode(loan)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+// csvColumns are the columns of a CSV export, the loan's followed by its
+// approval, disbursement and investment.
+var csvColumns = []string{
+	"loan_id", "borrower_id", "partner_id", "state", "principal", "rate", "roi", "tenor_months", "agreement_link", "created_at",
+	"validator_id", "proof_document_id", "approved_at",
+	"officer_id", "agreement_document_id", "disbursed_at",
+	"investor_id", "investment_amount", "invested_at", "investment_partner_id",
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (e *csvEncoder) Encode(loan *model.Loan) error {
+	if !e.header {
+		if err := e.w.Write(csvColumns); err != nil {
+			return err
+		}
+		e.header = true
+	}
+
+	record := []string{
+		formatID(loan.ID), formatID(loan.BorrowerID), formatID(loan.PartnerID), string(loan.State),
+		formatFloat(loan.Principal), formatFloat(loan.Rate), formatFloat(loan.ROI), strconv.Itoa(loan.TenorMonths),
+		loan.AgreementLink, formatTime(loan.CreatedAt),
+		"", "", "",
+		"", "", "",
+	}
+	if approval := loan.Approval; approval != nil {
+		record[10], record[11], record[12] = formatID(approval.ValidatorID), formatID(approval.ProofDocumentID), formatTime(approval.ApprovedAt)
+	}
+	if disbursement := loan.Disbursement; disbursement != nil {
+		record[13], record[14], record[15] = formatID(disbursement.OfficerID), formatID(disbursement.AgreementDocumentID), formatTime(disbursement.DisbursedAt)
+	}
+
+	if len(loan.Investments) == 0 {
+		return e.w.Write(append(record, "", "", "", ""))
+	}
+	for _, investment := range loan.Investments {
+		row := append(record[:len(record):len(record)],
+			formatID(investment.InvestorID), formatFloat(investment.Amount), formatTime(investment.InvestedAt), formatID(investment.PartnerID))
+		if err := e.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the header of an export without loans, so it still names
+// its columns, and flushes the rows.
+func (e *csvEncoder) Close() error {
+	if !e.header {
+		if err := e.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// formatID leaves IDs that are not set empty rather than 0.
+func formatID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// parquetLoan is a loan as a Parquet row. IDs that are not set and the
+// steps a loan has not reached yet are null.
+type parquetLoan struct {
+	ID                  int64               `parquet:"loan_id"`
+	BorrowerID          int64               `parquet:"borrower_id,optional"`
+	PartnerID           int64               `parquet:"partner_id,optional"`
+	State               string              `parquet:"state,dict"`
+	Principal           float64             `parquet:"principal"`
+	Rate                float64             `parquet:"rate"`
+	ROI                 float64             `parquet:"roi"`
+	TenorMonths         int32               `parquet:"tenor_months"`
+	AgreementLink       string              `parquet:"agreement_link"`
+	CreatedAt           time.Time           `parquet:"created_at,timestamp(microsecond)"`
+	ValidatorID         int64               `parquet:"validator_id,optional"`
+	ProofDocumentID     int64               `parquet:"proof_document_id,optional"`
+	ApprovedAt          *time.Time          `parquet:"approved_at,optional,timestamp(microsecond)"`
+	OfficerID           int64               `parquet:"officer_id,optional"`
+	AgreementDocumentID int64               `parquet:"agreement_document_id,optional"`
+	DisbursedAt         *time.Time          `parquet:"disbursed_at,optional,timestamp(microsecond)"`
+	Investments         []parquetInvestment `parquet:"investments,list"`
+}
+
+type parquetInvestment struct {
+	InvestorID int64     `parquet:"investor_id"`
+	Amount     float64   `parquet:"amount"`
+	InvestedAt time.Time `parquet:"invested_at,timestamp(microsecond)"`
+	PartnerID  int64     `parquet:"partner_id,optional"`
+}
+
+type parquetEncoder struct {
+	w *parquet.GenericWriter[parquetLoan]
+}
+
+func (e *parquetEncoder) Encode(loan *model.Loan) error {
+	row := parquetLoan{
+		ID:            loan.ID,
+		BorrowerID:    loan.BorrowerID,
+		PartnerID:     loan.PartnerID,
+		State:         string(loan.State),
+		Principal:     loan.Principal,
+		Rate:          loan.Rate,
+		ROI:           loan.ROI,
+		TenorMonths:   int32(loan.TenorMonths),
+		AgreementLink: loan.AgreementLink,
+		CreatedAt:     loan.CreatedAt,
+		Investments:   make([]parquetInvestment, 0, len(loan.Investments)),
+	}
+	if approval := loan.Approval; approval != nil {
+		row.ValidatorID, row.ProofDocumentID, row.ApprovedAt = approval.ValidatorID, approval.ProofDocumentID, &approval.ApprovedAt
+	}
+	if disbursement := loan.Disbursement; disbursement != nil {
+		row.OfficerID, row.AgreementDocumentID, row.DisbursedAt = disbursement.OfficerID, disbursement.AgreementDocumentID, &disbursement.DisbursedAt
+	}
+	for _, investment := range loan.Investments {
+		row.Investments = append(row.Investments, parquetInvestment(investment))
+	}
+
+	_, err := e.w.Write([]parquetLoan{row})
+	return err
+}
+
+func (e *parquetEncoder) Close() error {
+	return e.w.Close()
+}
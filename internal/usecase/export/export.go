@@ -0,0 +1,36 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/loan"
+)
+
+//go:generate mockgen -source=export.go -destination=mock/export_mock.go -package=mock
+type Usecase interface {
+	// Export writes every loan matching query to w in format. The loans
+	// are encoded as they are read, rather than collected first: the SQL
+	// backend holds a page of them at a time, the memory backend a snapshot
+	// of the matching loans.
+	Export(ctx context.Context, query model.LoanQuery, format model.ExportFormat, w io.Writer) error
+}
+
+type usecase struct {
+	loans loan.Repository
+}
+
+func NewUsecase(loans loan.Repository) Usecase {
+	return &usecase{loans: loans}
+}
+
+func (uc *usecase) Export(ctx context.Context, query model.LoanQuery, format model.ExportFormat, w io.Writer) error {
+	enc := newEncoder(format, w)
+
+	if err := uc.loans.Each(ctx, query, enc.Encode); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
@@ -0,0 +1,142 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/repository/loan"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/usecase/export"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportUsecase(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	repo := loan.NewRepository(logger.Discard())
+	disbursed := &model.Loan{
+		ID: 1, BorrowerID: 123, Principal: 1000, Rate: 0.05, ROI: 0.07, TenorMonths: 12, State: model.StateDisbursed,
+		AgreementLink: "https://example.com/a", CreatedAt: createdAt,
+		Approval: &model.Approval{ValidatorID: 456, ProofDocumentID: 3, ApprovedAt: createdAt.Add(time.Hour)},
+		Investments: []model.Investment{
+			{InvestorID: 789, Amount: 600, InvestedAt: createdAt.Add(2 * time.Hour)},
+			{InvestorID: 790, Amount: 400, InvestedAt: createdAt.Add(3 * time.Hour), PartnerID: 9},
+		},
+		Disbursement: &model.Disbursement{OfficerID: 101, AgreementDocumentID: 4, DisbursedAt: createdAt.Add(4 * time.Hour)},
+	}
+	require.NoError(t, repo.Save(context.Background(), disbursed))
+	for i := 0; i < model.MaxLoanQueryLimit+5; i++ {
+		require.NoError(t, repo.Save(context.Background(), &model.Loan{
+			ID: int64(i + 2), BorrowerID: 124, Principal: 500, State: model.StateProposed, CreatedAt: createdAt.AddDate(0, 0, i),
+		}))
+	}
+	uc := export.NewUsecase(repo)
+	total := model.MaxLoanQueryLimit + 6
+
+	t.Run("Export CSV flattens investments", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, uc.Export(context.Background(), model.LoanQuery{}, model.ExportCSV, &out))
+
+		records, err := csv.NewReader(&out).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, total+2)
+		assert.Equal(t, "loan_id", records[0][0])
+		assert.Equal(t, []string{
+			"1", "123", "", "DISBURSED", "1000", "0.05", "0.07", "12", "https://example.com/a", "2025-01-02T10:00:00Z",
+			"456", "3", "2025-01-02T11:00:00Z",
+			"101", "4", "2025-01-02T14:00:00Z",
+			"789", "600", "2025-01-02T12:00:00Z", "",
+		}, records[1])
+		assert.Equal(t, []string{"790", "400", "2025-01-02T13:00:00Z", "9"}, records[2][16:])
+		assert.Equal(t, records[1][:16], records[2][:16])
+		assert.Equal(t, []string{"2", "124", "", "PROPOSED", "500", "0", "0", "0", "", "2025-01-02T10:00:00Z", "", "", "", "", "", "", "", "", "", ""}, records[3])
+	})
+
+	t.Run("Export CSV of no loans has the header", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, uc.Export(context.Background(), model.LoanQuery{BorrowerID: 999}, model.ExportCSV, &out))
+
+		records, err := csv.NewReader(&out).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Len(t, records[0], 20)
+	})
+
+	t.Run("Export NDJSON filters and sorts", func(t *testing.T) {
+		var out bytes.Buffer
+		query := model.LoanQuery{BorrowerID: 124, SortBy: model.LoanSortCreatedAt, Descending: true}
+		require.NoError(t, uc.Export(context.Background(), query, model.ExportNDJSON, &out))
+
+		var ids []int64
+		scanner := bufio.NewScanner(&out)
+		for scanner.Scan() {
+			var loan model.Loan
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &loan))
+			ids = append(ids, loan.ID)
+		}
+		require.Len(t, ids, total-1)
+		assert.Equal(t, int64(total), ids[0])
+		assert.Equal(t, int64(2), ids[len(ids)-1])
+	})
+
+	t.Run("Export Parquet", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, uc.Export(context.Background(), model.LoanQuery{}, model.ExportParquet, &out))
+
+		type investment struct {
+			InvestorID int64   `parquet:"investor_id"`
+			Amount     float64 `parquet:"amount"`
+			PartnerID  *int64  `parquet:"partner_id,optional"`
+		}
+		type row struct {
+			ID          int64        `parquet:"loan_id"`
+			State       string       `parquet:"state"`
+			PartnerID   *int64       `parquet:"partner_id,optional"`
+			ValidatorID *int64       `parquet:"validator_id,optional"`
+			ApprovedAt  *time.Time   `parquet:"approved_at,optional,timestamp(microsecond)"`
+			Investments []investment `parquet:"investments,list"`
+		}
+		rows, err := parquet.Read[row](bytes.NewReader(out.Bytes()), int64(out.Len()))
+		require.NoError(t, err)
+		require.Len(t, rows, total)
+
+		assert.Equal(t, int64(1), rows[0].ID)
+		assert.Equal(t, "DISBURSED", rows[0].State)
+		assert.Nil(t, rows[0].PartnerID)
+		require.NotNil(t, rows[0].ValidatorID)
+		assert.Equal(t, int64(456), *rows[0].ValidatorID)
+		require.NotNil(t, rows[0].ApprovedAt)
+		assert.True(t, createdAt.Add(time.Hour).Equal(*rows[0].ApprovedAt))
+		require.Len(t, rows[0].Investments, 2)
+		assert.Equal(t, 400.0, rows[0].Investments[1].Amount)
+		assert.Equal(t, int64(9), *rows[0].Investments[1].PartnerID)
+
+		assert.Nil(t, rows[1].ValidatorID)
+		assert.Nil(t, rows[1].ApprovedAt)
+		assert.Empty(t, rows[1].Investments)
+	})
+
+	t.Run("Export repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := loanrepo.NewMockRepository(ctrl)
+		repoMock.EXPECT().Each(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+
+		var out bytes.Buffer
+		err := export.NewUsecase(repoMock).Export(context.Background(), model.LoanQuery{}, model.ExportCSV, &out)
+		assert.EqualError(t, err, "boom")
+		assert.Zero(t, out.Len())
+	})
+}
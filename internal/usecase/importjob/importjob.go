@@ -0,0 +1,181 @@
+package importjob
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/importjob"
+	"loan_system/internal/usecase/loan"
+)
+
+//go:generate mockgen -source=importjob.go -destination=mock/importjob_mock.go -package=mock
+type Usecase interface {
+	// Start stores the job and creates the loans of its rows in the
+	// background. Rows that already failed while the file was read are
+	// reported as they are.
+	Start(ctx context.Context, job *model.ImportJob) error
+	Job(ctx context.Context, id int64) (*model.ImportJob, error)
+	// Wait blocks until every started job has finished.
+	Wait()
+}
+
+// A best-effort import saves its progress after progressRows rows or
+// progressInterval, whichever comes first, rather than after every row, as
+// each save stores the whole job.
+const (
+	progressRows     = 100
+	progressInterval = time.Second
+)
+
+type usecase struct {
+	repo  importjob.Repository
+	loans loan.Usecase
+	// retention is how long finished jobs can still be read
+	retention time.Duration
+	log       *slog.Logger
+	now       func() time.Time
+	running   sync.WaitGroup
+}
+
+func NewUsecase(repo importjob.Repository, loans loan.Usecase, retention time.Duration, log *slog.Logger) Usecase {
+	return &usecase{repo: repo, loans: loans, retention: retention, log: log, now: time.Now}
+}
+
+func (uc *usecase) Start(ctx context.Context, job *model.ImportJob) error {
+	if err := uc.repo.DeleteFinishedBefore(ctx, uc.now().Add(-uc.retention)); err != nil {
+		return err
+	}
+
+	job.Status = model.ImportPending
+	tally(job)
+	job.CreatedAt = uc.now().UTC()
+	if err := uc.repo.Save(ctx, job); err != nil {
+		return err
+	}
+
+	// the job outlives the request that started it
+	ctx = context.WithoutCancel(ctx)
+	run := *job
+	uc.running.Add(1)
+	go func() {
+		defer uc.running.Done()
+		uc.run(ctx, &run)
+	}()
+	return nil
+}
+
+func (uc *usecase) Job(ctx context.Context, id int64) (*model.ImportJob, error) {
+	return uc.repo.FindByID(ctx, id)
+}
+
+func (uc *usecase) Wait() {
+	uc.running.Wait()
+}
+
+func (uc *usecase) run(ctx context.Context, job *model.ImportJob) {
+	job.Rows = append([]model.ImportRow(nil), job.Rows...)
+	job.Status = model.ImportRunning
+	uc.update(ctx, job)
+
+	if job.Mode == model.ImportAllOrNothing {
+		uc.createAll(ctx, job)
+	} else {
+		uc.createEach(ctx, job)
+	}
+
+	tally(job)
+	job.Status = model.ImportCompleted
+	if job.Created == 0 && job.Total > 0 {
+		job.Status = model.ImportFailed
+	}
+	completedAt := uc.now().UTC()
+	job.CompletedAt = &completedAt
+	uc.update(ctx, job)
+
+	uc.log.InfoContext(ctx, "loan import finished", "import_id", job.ID, "status", job.Status, "created", job.Created, "failed", job.Failed)
+}
+
+// createAll stores the loans of every row in one go, and only if no row
+// failed to be read.
+func (uc *usecase) createAll(ctx context.Context, job *model.ImportJob) {
+	var loans []*model.Loan
+	for _, row := range job.Rows {
+		if row.Status == model.ImportRowFailed {
+			uc.skipPending(job)
+			return
+		}
+		loans = append(loans, row.Loan)
+	}
+
+	if err := uc.loans.CreateLoans(ctx, loans); err != nil {
+		for i := range job.Rows {
+			job.Rows[i].Status = model.ImportRowFailed
+			job.Rows[i].Error = fmt.Sprintf("no loans were created: %v", err)
+		}
+		return
+	}
+
+	for i := range job.Rows {
+		job.Rows[i].Status = model.ImportRowCreated
+		job.Rows[i].LoanID = job.Rows[i].Loan.ID
+	}
+}
+
+// createEach stores the loan of every row that was read, one at a time,
+// recording progress as it goes. run saves whatever is left once it is done.
+func (uc *usecase) createEach(ctx context.Context, job *model.ImportJob) {
+	unsaved, savedAt := 0, uc.now()
+	for i := range job.Rows {
+		row := &job.Rows[i]
+		if row.Status != model.ImportRowPending {
+			continue
+		}
+
+		if err := uc.loans.CreateLoan(ctx, row.Loan); err != nil {
+			row.Status = model.ImportRowFailed
+			row.Error = err.Error()
+		} else {
+			row.Status = model.ImportRowCreated
+			row.LoanID = row.Loan.ID
+		}
+
+		unsaved++
+		if unsaved >= progressRows || uc.now().Sub(savedAt) >= progressInterval {
+			uc.update(ctx, job)
+			unsaved, savedAt = 0, uc.now()
+		}
+	}
+}
+
+func (uc *usecase) skipPending(job *model.ImportJob) {
+	for i := range job.Rows {
+		if job.Rows[i].Status == model.ImportRowPending {
+			job.Rows[i].Status = model.ImportRowSkipped
+		}
+	}
+}
+
+// update stores the job's progress. A failure only delays what pollers see,
+// so it is logged rather than stopping the import.
+func (uc *usecase) update(ctx context.Context, job *model.ImportJob) {
+	tally(job)
+	if err := uc.repo.Update(ctx, job); err != nil {
+		uc.log.WarnContext(ctx, "update import job failed", "import_id", job.ID, "error", err)
+	}
+}
+
+func tally(job *model.ImportJob) {
+	job.Total, job.Created, job.Failed = len(job.Rows), 0, 0
+	for _, row := range job.Rows {
+		switch row.Status {
+		case model.ImportRowCreated:
+			job.Created++
+		case model.ImportRowFailed:
+			job.Failed++
+		}
+	}
+}
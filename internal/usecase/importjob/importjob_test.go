@@ -0,0 +1,136 @@
+package importjob_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	importjobrepo "loan_system/internal/repository/importjob"
+	"loan_system/internal/usecase/importjob"
+	loanmock "loan_system/internal/usecase/loan/mock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// countingRepository counts how often a job's progress is stored.
+type countingRepository struct {
+	importjobrepo.Repository
+	updates atomic.Int32
+}
+
+func (r *countingRepository) Update(ctx context.Context, job *model.ImportJob) error {
+	r.updates.Add(1)
+	return r.Repository.Update(ctx, job)
+}
+
+func rows() []model.ImportRow {
+	return []model.ImportRow{
+		{Line: 2, Status: model.ImportRowPending, Loan: &model.Loan{Principal: 100}},
+		{Line: 3, Status: model.ImportRowPending, Loan: &model.Loan{Principal: 200}},
+	}
+}
+
+func TestImportUsecase(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	loans := loanmock.NewMockUsecase(ctrl)
+	uc := importjob.NewUsecase(importjobrepo.NewRepository(logger.Discard()), loans, time.Hour, logger.Discard())
+
+	run := func(t *testing.T, job *model.ImportJob) *model.ImportJob {
+		require.NoError(t, uc.Start(ctx, job))
+		assert.Equal(t, model.ImportPending, job.Status)
+		uc.Wait()
+
+		finished, err := uc.Job(ctx, job.ID)
+		require.NoError(t, err)
+		assert.True(t, finished.Finished())
+		assert.NotNil(t, finished.CompletedAt)
+		return finished
+	}
+
+	t.Run("all or nothing creates every loan", func(t *testing.T) {
+		loans.EXPECT().CreateLoans(gomock.Any(), gomock.Len(2)).DoAndReturn(func(_ context.Context, created []*model.Loan) error {
+			for i, l := range created {
+				l.ID = int64(i + 1)
+			}
+			return nil
+		})
+
+		job := run(t, &model.ImportJob{Mode: model.ImportAllOrNothing, Rows: rows()})
+		assert.Equal(t, model.ImportCompleted, job.Status)
+		assert.Equal(t, 2, job.Total)
+		assert.Equal(t, 2, job.Created)
+		assert.Equal(t, int64(1), job.Rows[0].LoanID)
+		assert.Equal(t, int64(2), job.Rows[1].LoanID)
+	})
+
+	t.Run("all or nothing skips every row when one is invalid", func(t *testing.T) {
+		invalid := rows()
+		invalid[1] = model.ImportRow{Line: 3, Status: model.ImportRowFailed, Error: "principal is required"}
+
+		job := run(t, &model.ImportJob{Mode: model.ImportAllOrNothing, Rows: invalid})
+		assert.Equal(t, model.ImportFailed, job.Status)
+		assert.Equal(t, 0, job.Created)
+		assert.Equal(t, 1, job.Failed)
+		assert.Equal(t, model.ImportRowSkipped, job.Rows[0].Status)
+		assert.Equal(t, "principal is required", job.Rows[1].Error)
+	})
+
+	t.Run("all or nothing fails every row when storing fails", func(t *testing.T) {
+		loans.EXPECT().CreateLoans(gomock.Any(), gomock.Any()).Return(errors.New("database is locked"))
+
+		job := run(t, &model.ImportJob{Mode: model.ImportAllOrNothing, Rows: rows()})
+		assert.Equal(t, model.ImportFailed, job.Status)
+		assert.Equal(t, 2, job.Failed)
+		assert.Equal(t, "no loans were created: database is locked", job.Rows[0].Error)
+	})
+
+	t.Run("best effort creates the rows it can", func(t *testing.T) {
+		all := append(rows(), model.ImportRow{Line: 4, Status: model.ImportRowFailed, Error: "rate is required"})
+		loans.EXPECT().CreateLoan(gomock.Any(), all[0].Loan).DoAndReturn(func(_ context.Context, l *model.Loan) error {
+			l.ID = 7
+			return nil
+		})
+		loans.EXPECT().CreateLoan(gomock.Any(), all[1].Loan).Return(errors.New("database is locked"))
+
+		job := run(t, &model.ImportJob{Mode: model.ImportBestEffort, Rows: all})
+		assert.Equal(t, model.ImportCompleted, job.Status)
+		assert.Equal(t, 3, job.Total)
+		assert.Equal(t, 1, job.Created)
+		assert.Equal(t, 2, job.Failed)
+		assert.Equal(t, int64(7), job.Rows[0].LoanID)
+		assert.Equal(t, "database is locked", job.Rows[1].Error)
+		assert.Equal(t, model.ImportRowFailed, job.Rows[2].Status)
+	})
+
+	t.Run("best effort saves progress in batches", func(t *testing.T) {
+		repo := &countingRepository{Repository: importjobrepo.NewRepository(logger.Discard())}
+		uc := importjob.NewUsecase(repo, loans, time.Hour, logger.Discard())
+		many := make([]model.ImportRow, 250)
+		for i := range many {
+			many[i] = model.ImportRow{Line: i + 2, Status: model.ImportRowPending, Loan: &model.Loan{Principal: 100}}
+		}
+		loans.EXPECT().CreateLoan(gomock.Any(), gomock.Any()).Return(nil).Times(len(many))
+
+		job := &model.ImportJob{Mode: model.ImportBestEffort, Rows: many}
+		require.NoError(t, uc.Start(ctx, job))
+		uc.Wait()
+
+		finished, err := uc.Job(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 250, finished.Created)
+		// once when it starts, after 100 and 200 rows, and when it finishes
+		assert.Equal(t, int32(4), repo.updates.Load())
+	})
+
+	t.Run("Job not found", func(t *testing.T) {
+		_, err := uc.Job(ctx, 1)
+		assert.ErrorContains(t, err, "import job not found")
+	})
+}
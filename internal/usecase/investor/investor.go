@@ -0,0 +1,30 @@
+package investor
+
+import (
+	"context"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/loan"
+)
+
+//go:generate mockgen -source=investor.go -destination=mock/investor_mock.go -package=mock
+type Usecase interface {
+	Portfolio(ctx context.Context, investorID int64) (*model.Portfolio, error)
+}
+
+type usecase struct {
+	loans loan.Repository
+}
+
+func NewUsecase(loans loan.Repository) Usecase {
+	return &usecase{loans: loans}
+}
+
+func (uc *usecase) Portfolio(ctx context.Context, investorID int64) (*model.Portfolio, error) {
+	loans, err := uc.loans.FindByInvestorID(ctx, investorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPortfolio(investorID, loans), nil
+}
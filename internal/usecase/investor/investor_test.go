@@ -0,0 +1,42 @@
+package investor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"loan_system/internal/model"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/usecase/investor"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInvestorUsecase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loanMock := loanrepo.NewMockRepository(ctrl)
+	uc := investor.NewUsecase(loanMock)
+
+	t.Run("Portfolio", func(t *testing.T) {
+		loanMock.EXPECT().FindByInvestorID(gomock.Any(), int64(7)).Return([]*model.Loan{
+			{ID: 1, BorrowerID: 3, Principal: 1000, ROI: 0.1, State: model.StateApproved, Investments: []model.Investment{{InvestorID: 7, Amount: 250}}},
+		}, nil)
+
+		portfolio, err := uc.Portfolio(context.Background(), 7)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), portfolio.InvestorID)
+		assert.Len(t, portfolio.Positions, 1)
+		assert.InDelta(t, 25, portfolio.Positions[0].SharePercentage, 1e-9)
+		assert.InDelta(t, 275, portfolio.Totals.Outstanding, 1e-9)
+	})
+
+	t.Run("Portfolio repository error", func(t *testing.T) {
+		loanMock.EXPECT().FindByInvestorID(gomock.Any(), int64(7)).Return(nil, errors.New("boom"))
+
+		_, err := uc.Portfolio(context.Background(), 7)
+		assert.EqualError(t, err, "boom")
+	})
+}
@@ -3,99 +3,414 @@ package loan
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"time"
 
 	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/metrics"
+	"loan_system/internal/pkg/tracing"
+	"loan_system/internal/repository/document"
 	"loan_system/internal/repository/loan"
 	"loan_system/internal/repository/pubsub"
+	"loan_system/internal/usecase/agreement"
+
+	"github.com/bwmarrin/snowflake"
 )
 
 //go:generate mockgen -source=loan.go -destination=mock/loan_mock.go -package=mock
 type Usecase interface {
 	FindAll(ctx context.Context) ([]*model.Loan, error)
+	Find(ctx context.Context, query model.LoanQuery) (model.LoanPage, error)
 	FindByID(ctx context.Context, id int64) (*model.Loan, error)
 	CreateLoan(ctx context.Context, loan *model.Loan) error
+	// CreateLoans proposes every loan or, if any of them cannot be stored, none.
+	CreateLoans(ctx context.Context, loans []*model.Loan) error
 	ApproveLoan(ctx context.Context, loanID int64, approval model.Approval) (loan *model.Loan, err error)
 	AddInvestment(ctx context.Context, loanID int64, investment model.Investment) (loan *model.Loan, err error)
 	DisburseLoan(ctx context.Context, loanID int64, disbursement model.Disbursement) (loan *model.Loan, err error)
+	// ApproveLoans and DisburseLoans change every loan of items with the
+	// same approval or disbursement, each against its own document, which
+	// must belong to that loan. When atomic, no loan is changed unless every
+	// one can be.
+	ApproveLoans(ctx context.Context, items []model.BatchItem, approval model.Approval, atomic bool) (results []model.BatchResult, err error)
+	DisburseLoans(ctx context.Context, items []model.BatchItem, disbursement model.Disbursement, atomic bool) (results []model.BatchResult, err error)
 }
 
 type usecase struct {
-	repo   loan.Repository
-	pubsub pubsub.Mock
+	repo       loan.Repository
+	documents  document.Repository
+	pubsub     pubsub.Mock
+	agreements agreement.Usecase
+	metrics    metrics.Metrics
+	log        *slog.Logger
+	// events numbers loan events so consumers can order them
+	events *snowflake.Node
+}
+
+func NewUsecase(repo loan.Repository, documents document.Repository, pubsub pubsub.Mock, agreements agreement.Usecase, metrics metrics.Metrics, log *slog.Logger) Usecase {
+	node, err := snowflake.NewNode(4)
+	if err != nil {
+		log.Error("create snowflake node failed", "error", err)
+		return nil
+	}
+
+	return &usecase{repo: repo, documents: documents, pubsub: pubsub, agreements: agreements, metrics: metrics, log: log, events: node}
 }
 
-func NewUsecase(repo loan.Repository, pubsub pubsub.Mock) Usecase {
-	return &usecase{repo: repo, pubsub: pubsub}
+// start begins a span for the operation and attaches the operation and attrs
+// to every log line written with the returned context. The returned func is
+// deferred with a pointer to the named error result and reports it to the
+// span, the metrics and the log.
+func (uc *usecase) start(ctx context.Context, operation string, attrs ...slog.Attr) (context.Context, func(*error)) {
+	ctx = logger.WithAttrs(ctx, append([]slog.Attr{slog.String("operation", operation)}, attrs...)...)
+	ctx, span := tracing.Start(ctx, "loan.Usecase."+operation, tracing.Attributes(attrs...)...)
+	return ctx, func(err *error) {
+		uc.metrics.ObserveOperation(operation, *err)
+		tracing.End(span, err)
+		if *err != nil {
+			uc.log.ErrorContext(ctx, "operation failed", "error", *err)
+		}
+	}
 }
 
-func (uc *usecase) FindAll(ctx context.Context) ([]*model.Loan, error) {
+func (uc *usecase) FindAll(ctx context.Context) (loans []*model.Loan, err error) {
+	ctx, end := uc.start(ctx, "find_all")
+	defer end(&err)
 	return uc.repo.FindAll(ctx)
 }
 
-func (uc *usecase) FindByID(ctx context.Context, id int64) (*model.Loan, error) {
+func (uc *usecase) Find(ctx context.Context, query model.LoanQuery) (page model.LoanPage, err error) {
+	ctx, end := uc.start(ctx, "find")
+	defer end(&err)
+	return uc.repo.Find(ctx, query)
+}
+
+func (uc *usecase) FindByID(ctx context.Context, id int64) (loan *model.Loan, err error) {
+	ctx, end := uc.start(ctx, "find_by_id", slog.Int64("loan_id", id))
+	defer end(&err)
 	return uc.repo.FindByID(ctx, id)
 }
 
-func (uc *usecase) CreateLoan(ctx context.Context, loan *model.Loan) error {
-	loan.State = model.StateProposed
+func (uc *usecase) CreateLoan(ctx context.Context, loan *model.Loan) (err error) {
+	ctx, end := uc.start(ctx, "create_loan", logger.Actor("borrower", loan.BorrowerID))
+	defer end(&err)
+
+	propose(loan, time.Now().UTC())
+	if err := uc.repo.Save(ctx, loan); err != nil {
+		return err
+	}
+
+	uc.log.InfoContext(ctx, "loan proposed", "loan_id", loan.ID)
+	uc.publishEvent(ctx, model.LoanEventProposed, loan)
+	return nil
+}
+
+func (uc *usecase) CreateLoans(ctx context.Context, loans []*model.Loan) (err error) {
+	ctx, end := uc.start(ctx, "create_loans", slog.Int("count", len(loans)))
+	defer end(&err)
+
+	now := time.Now().UTC()
+	for _, loan := range loans {
+		propose(loan, now)
+	}
+	if err := uc.repo.SaveAll(ctx, loans); err != nil {
+		return err
+	}
+
+	uc.log.InfoContext(ctx, "loans proposed")
+	for _, loan := range loans {
+		uc.publishEvent(ctx, model.LoanEventProposed, loan)
+	}
+	return nil
+}
 
-	return uc.repo.Save(ctx, loan)
+// propose readies a new loan to be stored.
+func propose(loan *model.Loan, now time.Time) {
+	loan.State = model.StateProposed
+	loan.CreatedAt = now
+	if loan.TenorMonths == 0 {
+		loan.TenorMonths = model.DefaultTenorMonths
+	}
 }
 
 func (uc *usecase) ApproveLoan(ctx context.Context, loanID int64, approval model.Approval) (loan *model.Loan, err error) {
-	loan, err = uc.repo.FindByID(ctx, loanID)
+	ctx, end := uc.start(ctx, "approve_loan", slog.Int64("loan_id", loanID), logger.Actor("field_validator", approval.ValidatorID))
+	defer end(&err)
+
+	if approval.ApprovedAt.IsZero() {
+		approval.ApprovedAt = time.Now().UTC()
+	}
+	loan, err = uc.update(ctx, loanID, func(loan *model.Loan) error {
+		if err := uc.requireDocument(ctx, loanID, approval.ProofDocumentID, model.DocumentKindApprovalProof); err != nil {
+			return fmt.Errorf("approval failed: %w", err)
+		}
+		if err := loan.Approve(approval); err != nil {
+			return fmt.Errorf("approval failed: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := loan.Approve(approval); err != nil {
-		return nil, fmt.Errorf("approval failed: %w", err)
+	uc.log.InfoContext(ctx, "loan approved")
+	uc.publishEvent(ctx, model.LoanEventApproved, loan)
+	return loan, nil
+}
+
+func (uc *usecase) AddInvestment(ctx context.Context, loanID int64, investment model.Investment) (loan *model.Loan, err error) {
+	ctx, end := uc.start(ctx, "add_investment", slog.Int64("loan_id", loanID), logger.Actor("investor", investment.InvestorID))
+	defer end(&err)
+
+	investment.InvestedAt = time.Now().UTC()
+	loan, err = uc.update(ctx, loanID, func(loan *model.Loan) error {
+		if err := loan.AddInvestment(investment); err != nil {
+			return fmt.Errorf("investment failed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return loan, uc.repo.Update(ctx, loan)
+	uc.log.InfoContext(ctx, "investment added", "amount", investment.Amount, "state", loan.State)
+	uc.publishEvent(ctx, model.LoanEventInvestmentAdded, loan)
+	if loan.State == model.StateInvested {
+		uc.notifyInvested(ctx, loan)
+		uc.publishEvent(ctx, model.LoanEventInvested, loan)
+	}
+	return loan, nil
 }
 
-func (uc *usecase) AddInvestment(ctx context.Context, loanID int64, investment model.Investment) (loan *model.Loan, err error) {
-	loan, err = uc.repo.FindByID(ctx, loanID)
+// notifyInvested generates the agreement letters of a loan that has just been
+// fully invested and tells its investors where to find them. The investment
+// is already stored, so a failure is logged rather than failing it, which
+// would have the investment retried; the letters are then generated when
+// first requested.
+func (uc *usecase) notifyInvested(ctx context.Context, loan *model.Loan) {
+	if _, err := uc.agreements.Generate(ctx, loan); err != nil {
+		uc.log.ErrorContext(ctx, "generate agreements failed", "error", err)
+		return
+	}
+
+	jsonData, err := json.Marshal(&model.LoanAgreement{LoanID: loan.ID})
+	if err == nil {
+		// send trigger to pubsub to notify investor regarding agreement link
+		err = uc.pubsub.Publish(ctx, "loan_invested", jsonData, nil)
+	}
+	if err != nil {
+		uc.log.ErrorContext(ctx, "publish loan invested failed", "error", err)
+	}
+}
+
+// update applies change to a copy of the stored loan, so the stored one is
+// left as it was if change or the update fails, and stores the copy. When
+// another request stored the loan in between, it starts over from the loan
+// that request stored, so neither change is lost.
+func (uc *usecase) update(ctx context.Context, loanID int64, change func(*model.Loan) error) (*model.Loan, error) {
+	for {
+		stored, err := uc.repo.FindByID(ctx, loanID)
+		if err != nil {
+			return nil, err
+		}
+
+		changed := clone(stored)
+		if err := change(changed); err != nil {
+			return nil, err
+		}
+
+		err = uc.repo.Update(ctx, changed)
+		if err == nil {
+			return changed, nil
+		}
+		if !errors.Is(err, loan.ErrConflict) {
+			return nil, err
+		}
+		uc.log.DebugContext(ctx, "loan changed concurrently, retrying")
+	}
+}
+
+// clone copies a loan deeply enough that changing its state or adding
+// investments leaves the original untouched.
+func clone(loan *model.Loan) *model.Loan {
+	copied := *loan
+	copied.Investments = slices.Clone(loan.Investments)
+	return &copied
+}
+
+func (uc *usecase) DisburseLoan(ctx context.Context, loanID int64, disbursement model.Disbursement) (loan *model.Loan, err error) {
+	ctx, end := uc.start(ctx, "disburse_loan", slog.Int64("loan_id", loanID), logger.Actor("field_officer", disbursement.OfficerID))
+	defer end(&err)
+
+	if disbursement.DisbursedAt.IsZero() {
+		disbursement.DisbursedAt = time.Now().UTC()
+	}
+	loan, err = uc.update(ctx, loanID, func(loan *model.Loan) error {
+		if err := uc.requireDocument(ctx, loanID, disbursement.AgreementDocumentID, model.DocumentKindSignedAgreement); err != nil {
+			return fmt.Errorf("disburse failed: %w", err)
+		}
+		if err := loan.Disburse(disbursement); err != nil {
+			return fmt.Errorf("disburse failed: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := loan.AddInvestment(investment); err != nil {
-		return nil, fmt.Errorf("investment failed: %w", err)
+	uc.log.InfoContext(ctx, "loan disbursed")
+	uc.publishEvent(ctx, model.LoanEventDisbursed, loan)
+	return loan, nil
+}
+
+func (uc *usecase) ApproveLoans(ctx context.Context, items []model.BatchItem, approval model.Approval, atomic bool) (results []model.BatchResult, err error) {
+	ctx, end := uc.start(ctx, "approve_loans", slog.Int("count", len(items)), logger.Actor("field_validator", approval.ValidatorID))
+	defer end(&err)
+
+	if approval.ApprovedAt.IsZero() {
+		approval.ApprovedAt = time.Now().UTC()
 	}
+	return uc.transitionAll(ctx, items, atomic, model.LoanEventApproved, func(loan *model.Loan, documentID int64) error {
+		if err := uc.requireDocument(ctx, loan.ID, documentID, model.DocumentKindApprovalProof); err != nil {
+			return fmt.Errorf("approval failed: %w", err)
+		}
+		approval := approval
+		approval.ProofDocumentID = documentID
+		if err := loan.Approve(approval); err != nil {
+			return fmt.Errorf("approval failed: %w", err)
+		}
+		return nil
+	})
+}
 
-	if loan.State == model.StateInvested {
-		agreement := model.LoanAgreement{
-			LoanID: loan.ID,
+func (uc *usecase) DisburseLoans(ctx context.Context, items []model.BatchItem, disbursement model.Disbursement, atomic bool) (results []model.BatchResult, err error) {
+	ctx, end := uc.start(ctx, "disburse_loans", slog.Int("count", len(items)), logger.Actor("field_officer", disbursement.OfficerID))
+	defer end(&err)
+
+	if disbursement.DisbursedAt.IsZero() {
+		disbursement.DisbursedAt = time.Now().UTC()
+	}
+	return uc.transitionAll(ctx, items, atomic, model.LoanEventDisbursed, func(loan *model.Loan, documentID int64) error {
+		if err := uc.requireDocument(ctx, loan.ID, documentID, model.DocumentKindSignedAgreement); err != nil {
+			return fmt.Errorf("disburse failed: %w", err)
+		}
+		disbursement := disbursement
+		disbursement.AgreementDocumentID = documentID
+		if err := loan.Disburse(disbursement); err != nil {
+			return fmt.Errorf("disburse failed: %w", err)
 		}
+		return nil
+	})
+}
 
-		jsonData, err := json.Marshal(&agreement)
+// transitionAll applies transition, with the item's document, to a copy of
+// every loan, so a loan that fails, or is skipped, is left as it was. Each
+// changed loan is stored on its own, or all of them together when atomic.
+func (uc *usecase) transitionAll(ctx context.Context, items []model.BatchItem, atomic bool, eventType model.LoanEventType, transition func(loan *model.Loan, documentID int64) error) ([]model.BatchResult, error) {
+	var results []model.BatchResult
+	if atomic {
+		var err error
+		for {
+			results, err = uc.transitionTogether(ctx, items, transition)
+			if !errors.Is(err, loan.ErrConflict) {
+				break
+			}
+			uc.log.DebugContext(ctx, "loans changed concurrently, retrying")
+		}
 		if err != nil {
-			return nil, fmt.Errorf("marshal agreement failed: %w", err)
+			return nil, err
+		}
+	} else {
+		results = make([]model.BatchResult, len(items))
+		for i, item := range items {
+			changed, err := uc.update(ctx, item.LoanID, func(loan *model.Loan) error {
+				return transition(loan, item.DocumentID)
+			})
+			if err != nil {
+				results[i] = model.BatchResult{LoanID: item.LoanID, Status: model.BatchFailed, Error: err.Error()}
+				continue
+			}
+			results[i] = model.BatchResult{LoanID: item.LoanID, Status: model.BatchSucceeded, Loan: changed}
 		}
+	}
 
-		// send trigger to pubsub to notify investor regarding agreement link
+	succeeded := 0
+	for _, result := range results {
+		if result.Status == model.BatchSucceeded {
+			succeeded++
+			uc.publishEvent(ctx, eventType, result.Loan)
+		}
+	}
+	uc.log.InfoContext(ctx, "loans changed", "event_type", eventType, "succeeded", succeeded, "failed", len(results)-succeeded)
+	return results, nil
+}
+
+// transitionTogether changes every loan or none. UpdateAll refuses the
+// whole batch with loan.ErrConflict if any loan was stored since it was read,
+// so no other request's change to them is overwritten.
+func (uc *usecase) transitionTogether(ctx context.Context, items []model.BatchItem, transition func(loan *model.Loan, documentID int64) error) ([]model.BatchResult, error) {
+	results := make([]model.BatchResult, len(items))
+	changed := make([]*model.Loan, 0, len(items))
+	failed := false
+	for i, item := range items {
+		results[i] = model.BatchResult{LoanID: item.LoanID, Status: model.BatchFailed}
 
-		if err := uc.pubsub.Publish(ctx, "loan_invested", jsonData); err != nil {
-			return nil, fmt.Errorf("publish loan invested failed: %w", err)
+		loan, err := uc.repo.FindByID(ctx, item.LoanID)
+		if err == nil {
+			loan = clone(loan)
+			err = transition(loan, item.DocumentID)
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			failed = true
+			continue
 		}
 
+		results[i].Status, results[i].Loan = model.BatchSucceeded, loan
+		changed = append(changed, loan)
 	}
 
-	return loan, uc.repo.Update(ctx, loan)
+	if failed {
+		for i := range results {
+			if results[i].Status == model.BatchSucceeded {
+				results[i].Status, results[i].Loan = model.BatchSkipped, nil
+			}
+		}
+		return results, nil
+	}
+	if err := uc.repo.UpdateAll(ctx, changed); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (uc *usecase) DisburseLoan(ctx context.Context, loanID int64, disbursement model.Disbursement) (loan *model.Loan, err error) {
-	loan, err = uc.repo.FindByID(ctx, loanID)
+// publishEvent announces a state change that has already been stored, so a
+// failure is logged rather than failing the operation.
+func (uc *usecase) publishEvent(ctx context.Context, eventType model.LoanEventType, loan *model.Loan) {
+	data, err := json.Marshal(model.LoanEvent{
+		ID:         uc.events.Generate().Int64(),
+		Type:       eventType,
+		Loan:       loan,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err == nil {
+		err = uc.pubsub.Publish(ctx, model.TopicLoanEvents, data, nil)
+	}
 	if err != nil {
-		return nil, err
+		uc.log.WarnContext(ctx, "publish loan event failed", "event_type", eventType, "error", err)
 	}
+}
 
-	if err := loan.Disburse(disbursement); err != nil {
-		return nil, fmt.Errorf("disburse failed: %w", err)
+// requireDocument checks that an uploaded document exists and may be attached to the loan.
+func (uc *usecase) requireDocument(ctx context.Context, loanID, documentID int64, kind model.DocumentKind) error {
+	doc, err := uc.documents.FindByID(ctx, documentID)
+	if err != nil {
+		return err
 	}
 
-	return loan, uc.repo.Update(ctx, loan)
+	return doc.AttachableTo(loanID, kind)
 }
@@ -2,14 +2,26 @@ package loan_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"loan_system/internal/model"
+	"loan_system/internal/pkg/database"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/pkg/metrics"
+	metricsmock "loan_system/internal/pkg/metrics/mock"
+	"loan_system/internal/pkg/migration"
+	documentrepo "loan_system/internal/repository/document/mock"
+	loanrepository "loan_system/internal/repository/loan"
 	loanrepo "loan_system/internal/repository/loan/mock"
 	pubsubrepo "loan_system/internal/repository/pubsub"
+	agreementmock "loan_system/internal/usecase/agreement/mock"
 	"loan_system/internal/usecase/loan"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -18,8 +30,13 @@ func TestLoanUsecase(t *testing.T) {
 	defer ctrl.Finish()
 
 	repoMock := loanrepo.NewMockRepository(ctrl)
-	pubsubMock := pubsubrepo.NewMock()
-	uc := loan.NewUsecase(repoMock, pubsubMock)
+	documentMock := documentrepo.NewMockRepository(ctrl)
+	pubsubMock := pubsubrepo.NewMock(logger.Discard())
+	agreementMock := agreementmock.NewMockUsecase(ctrl)
+	uc := loan.NewUsecase(repoMock, documentMock, pubsubMock, agreementMock, metrics.NewNoop(), logger.Discard())
+
+	proof := &model.Document{ID: 10, LoanID: 1, Kind: model.DocumentKindApprovalProof}
+	signedAgreement := &model.Document{ID: 11, LoanID: 3, Kind: model.DocumentKindSignedAgreement}
 
 	t.Run("FindAll", func(t *testing.T) {
 		repoMock.EXPECT().FindAll(gomock.Any()).Return([]*model.Loan{{ID: 1}, {ID: 2}}, nil)
@@ -37,29 +54,82 @@ func TestLoanUsecase(t *testing.T) {
 		assert.Equal(t, int64(1), loan.ID)
 	})
 
+	t.Run("Find", func(t *testing.T) {
+		query := model.LoanQuery{State: model.StateApproved, Limit: 10}
+		repoMock.EXPECT().Find(gomock.Any(), query).Return(model.LoanPage{Loans: []*model.Loan{{ID: 1}}, NextCursor: "next"}, nil)
+
+		page, err := uc.Find(context.Background(), query)
+		assert.NoError(t, err)
+		assert.Len(t, page.Loans, 1)
+		assert.Equal(t, "next", page.NextCursor)
+	})
+
 	t.Run("CreateLoan", func(t *testing.T) {
 		repoMock.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
-		err := uc.CreateLoan(context.Background(), &model.Loan{})
+		l := &model.Loan{}
+		err := uc.CreateLoan(context.Background(), l)
 		assert.NoError(t, err)
+		assert.Equal(t, model.StateProposed, l.State)
+		assert.False(t, l.CreatedAt.IsZero())
+		assert.Equal(t, model.DefaultTenorMonths, l.TenorMonths)
+	})
+
+	t.Run("CreateLoans", func(t *testing.T) {
+		loans := []*model.Loan{{TenorMonths: 6}, {}}
+		repoMock.EXPECT().SaveAll(gomock.Any(), loans).Return(nil)
+
+		assert.NoError(t, uc.CreateLoans(context.Background(), loans))
+		for _, l := range loans {
+			assert.Equal(t, model.StateProposed, l.State)
+			assert.False(t, l.CreatedAt.IsZero())
+		}
+		assert.Equal(t, 6, loans[0].TenorMonths)
+		assert.Equal(t, model.DefaultTenorMonths, loans[1].TenorMonths)
+	})
+
+	t.Run("CreateLoans Error", func(t *testing.T) {
+		repoMock.EXPECT().SaveAll(gomock.Any(), gomock.Any()).Return(errors.New("loan already exists"))
+
+		assert.ErrorContains(t, uc.CreateLoans(context.Background(), []*model.Loan{{}}), "loan already exists")
 	})
 
 	t.Run("ApproveLoan Success", func(t *testing.T) {
 		mockLoan := &model.Loan{ID: 1, State: model.StateProposed}
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(mockLoan, nil)
-		repoMock.EXPECT().Update(gomock.Any(), mockLoan).Return(nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
 
-		_, err := uc.ApproveLoan(context.Background(), 1, model.Approval{})
+		got, err := uc.ApproveLoan(context.Background(), 1, model.Approval{ProofDocumentID: 10})
 		assert.NoError(t, err)
+		assert.False(t, got.Approval.ApprovedAt.IsZero())
+		assert.Equal(t, model.StateProposed, mockLoan.State, "the stored loan is only changed through Update")
 	})
 
 	t.Run("ApproveLoan InvalidState", func(t *testing.T) {
 		mockLoan := &model.Loan{ID: 1, State: model.StateApproved}
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(mockLoan, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
 
-		_, err := uc.ApproveLoan(context.Background(), 1, model.Approval{})
+		_, err := uc.ApproveLoan(context.Background(), 1, model.Approval{ProofDocumentID: 10})
 		assert.ErrorContains(t, err, "can only approve")
 	})
 
+	t.Run("ApproveLoan proof not found", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(99)).Return(nil, errors.New("document not found"))
+
+		_, err := uc.ApproveLoan(context.Background(), 1, model.Approval{ProofDocumentID: 99})
+		assert.ErrorContains(t, err, "document not found")
+	})
+
+	t.Run("ApproveLoan proof of another loan", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(3)).Return(&model.Loan{ID: 3, State: model.StateProposed}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+
+		_, err := uc.ApproveLoan(context.Background(), 3, model.Approval{ProofDocumentID: 10})
+		assert.ErrorContains(t, err, "does not belong to loan 3")
+	})
+
 	t.Run("ApproveLoan loan not exist", func(t *testing.T) {
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(nil, errors.New("loan not found"))
 
@@ -67,6 +137,128 @@ func TestLoanUsecase(t *testing.T) {
 		assert.ErrorContains(t, err, "loan not found")
 	})
 
+	t.Run("ApproveLoans atomic", func(t *testing.T) {
+		first := &model.Loan{ID: 1, State: model.StateProposed}
+		second := &model.Loan{ID: 2, State: model.StateProposed}
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(first, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(second, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(20)).Return(&model.Document{ID: 20, LoanID: 2, Kind: model.DocumentKindApprovalProof}, nil)
+		repoMock.EXPECT().UpdateAll(gomock.Any(), gomock.Len(2)).Return(nil)
+
+		items := []model.BatchItem{{LoanID: 1, DocumentID: 10}, {LoanID: 2, DocumentID: 20}}
+		results, err := uc.ApproveLoans(context.Background(), items, model.Approval{ValidatorID: 42}, true)
+		assert.NoError(t, err)
+		for i, result := range results {
+			assert.Equal(t, model.BatchSucceeded, result.Status)
+			assert.Equal(t, model.StateApproved, result.Loan.State)
+			assert.Equal(t, int64(42), result.Loan.Approval.ValidatorID)
+			assert.Equal(t, items[i].DocumentID, result.Loan.Approval.ProofDocumentID)
+			assert.False(t, result.Loan.Approval.ApprovedAt.IsZero())
+		}
+		// the stored loans are only changed through UpdateAll
+		assert.Equal(t, model.StateProposed, first.State)
+	})
+
+	t.Run("ApproveLoans atomic changes nothing when one loan fails", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(&model.Loan{ID: 2, State: model.StateApproved}, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(5)).Return(nil, errors.New("loan not found"))
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(20)).Return(&model.Document{ID: 20, LoanID: 2, Kind: model.DocumentKindApprovalProof}, nil)
+
+		items := []model.BatchItem{{LoanID: 1, DocumentID: 10}, {LoanID: 2, DocumentID: 20}, {LoanID: 5, DocumentID: 50}}
+		results, err := uc.ApproveLoans(context.Background(), items, model.Approval{}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.BatchResult{
+			{LoanID: 1, Status: model.BatchSkipped},
+			{LoanID: 2, Status: model.BatchFailed, Error: "approval failed: can only approve when loan is proposed"},
+			{LoanID: 5, Status: model.BatchFailed, Error: "loan not found"},
+		}, results)
+	})
+
+	t.Run("ApproveLoans atomic storage failure", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+		repoMock.EXPECT().UpdateAll(gomock.Any(), gomock.Any()).Return(errors.New("database is locked"))
+
+		_, err := uc.ApproveLoans(context.Background(), []model.BatchItem{{LoanID: 1, DocumentID: 10}}, model.Approval{}, true)
+		assert.ErrorContains(t, err, "database is locked")
+	})
+
+	t.Run("ApproveLoans atomic starts over on a conflict", func(t *testing.T) {
+		gomock.InOrder(
+			repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil),
+			repoMock.EXPECT().UpdateAll(gomock.Any(), gomock.Any()).Return(loanrepository.ErrConflict),
+			// approved by another request in between
+			repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateApproved, Version: 1}, nil),
+		)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil).Times(2)
+
+		results, err := uc.ApproveLoans(context.Background(), []model.BatchItem{{LoanID: 1, DocumentID: 10}}, model.Approval{}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.BatchResult{
+			{LoanID: 1, Status: model.BatchFailed, Error: "approval failed: can only approve when loan is proposed"},
+		}, results)
+	})
+
+	t.Run("ApproveLoans best effort", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(&model.Loan{ID: 2, State: model.StateApproved}, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(4)).Return(&model.Loan{ID: 4, State: model.StateProposed}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(20)).Return(&model.Document{ID: 20, LoanID: 2, Kind: model.DocumentKindApprovalProof}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(40)).Return(&model.Document{ID: 40, LoanID: 4, Kind: model.DocumentKindApprovalProof}, nil)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Cond(func(l *model.Loan) bool { return l.ID == 1 })).Return(nil)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Cond(func(l *model.Loan) bool { return l.ID == 4 })).Return(errors.New("database is locked"))
+
+		items := []model.BatchItem{{LoanID: 1, DocumentID: 10}, {LoanID: 2, DocumentID: 20}, {LoanID: 4, DocumentID: 40}}
+		results, err := uc.ApproveLoans(context.Background(), items, model.Approval{}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, model.BatchSucceeded, results[0].Status)
+		assert.Equal(t, model.BatchFailed, results[1].Status)
+		assert.Equal(t, model.BatchResult{LoanID: 4, Status: model.BatchFailed, Error: "database is locked"}, results[2])
+	})
+
+	t.Run("ApproveLoans proof of another loan", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(&model.Loan{ID: 2, State: model.StateProposed}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil).Times(2)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Cond(func(l *model.Loan) bool { return l.ID == 1 })).Return(nil)
+
+		// loan 1's proof does not approve loan 2 as well
+		items := []model.BatchItem{{LoanID: 1, DocumentID: 10}, {LoanID: 2, DocumentID: 10}}
+		results, err := uc.ApproveLoans(context.Background(), items, model.Approval{}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, model.BatchSucceeded, results[0].Status)
+		assert.Equal(t, model.BatchResult{LoanID: 2, Status: model.BatchFailed, Error: "approval failed: document 10 does not belong to loan 2"}, results[1])
+	})
+
+	t.Run("DisburseLoans", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(3)).Return(&model.Loan{ID: 3, State: model.StateInvested}, nil)
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(4)).Return(&model.Loan{ID: 4, State: model.StateInvested}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(11)).Return(signedAgreement, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(41)).Return(&model.Document{ID: 41, LoanID: 4, Kind: model.DocumentKindSignedAgreement}, nil)
+		repoMock.EXPECT().UpdateAll(gomock.Any(), gomock.Len(2)).Return(nil)
+
+		items := []model.BatchItem{{LoanID: 3, DocumentID: 11}, {LoanID: 4, DocumentID: 41}}
+		results, err := uc.DisburseLoans(context.Background(), items, model.Disbursement{OfficerID: 101}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, model.StateDisbursed, results[1].Loan.State)
+		assert.Equal(t, int64(101), results[1].Loan.Disbursement.OfficerID)
+		assert.Equal(t, int64(41), results[1].Loan.Disbursement.AgreementDocumentID)
+	})
+
+	t.Run("DisburseLoans wrong document kind", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateInvested}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+
+		results, err := uc.DisburseLoans(context.Background(), []model.BatchItem{{LoanID: 1, DocumentID: 10}}, model.Disbursement{}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, model.BatchFailed, results[0].Status)
+		assert.Contains(t, results[0].Error, "is not of kind signed_agreement")
+	})
+
 	t.Run("AddInvestment FullFunding", func(t *testing.T) {
 		loan := &model.Loan{
 			ID:          2,
@@ -74,13 +266,60 @@ func TestLoanUsecase(t *testing.T) {
 			Investments: []model.Investment{{Amount: 900}},
 			State:       model.StateApproved,
 		}
+		invested := gomock.Cond(func(l *model.Loan) bool { return l.ID == 2 && l.State == model.StateInvested })
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(loan, nil)
+		gomock.InOrder(
+			repoMock.EXPECT().Update(gomock.Any(), invested).Return(nil),
+			agreementMock.EXPECT().Generate(gomock.Any(), invested).Return([]*model.Agreement{{LoanID: 2}}, nil),
+		)
 
-		repoMock.EXPECT().Update(gomock.Any(), loan).Return(nil)
+		got, err := uc.AddInvestment(context.Background(), 2, model.Investment{Amount: 100})
+		assert.NoError(t, err)
+		assert.Equal(t, model.StateInvested, got.State)
+	})
+
+	t.Run("AddInvestment update failed", func(t *testing.T) {
+		loan := &model.Loan{
+			ID:          2,
+			Principal:   1000,
+			Investments: []model.Investment{{Amount: 900}},
+			State:       model.StateApproved,
+		}
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(loan, nil)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("database is locked"))
 
 		_, err := uc.AddInvestment(context.Background(), 2, model.Investment{Amount: 100})
-		assert.Equal(t, loan.State, model.StateInvested)
+		assert.ErrorContains(t, err, "database is locked")
+		assert.Equal(t, model.StateApproved, loan.State, "the stored loan is not changed")
+		assert.Len(t, loan.Investments, 1)
+	})
+
+	t.Run("AddInvestment retries on a conflict", func(t *testing.T) {
+		gomock.InOrder(
+			repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(&model.Loan{ID: 2, Principal: 1000, State: model.StateApproved}, nil),
+			repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(loanrepository.ErrConflict),
+			repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(&model.Loan{ID: 2, Principal: 1000, Investments: []model.Investment{{Amount: 300}}, State: model.StateApproved, Version: 1}, nil),
+			repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil),
+		)
+
+		got, err := uc.AddInvestment(context.Background(), 2, model.Investment{Amount: 100})
 		assert.NoError(t, err)
+		assert.Len(t, got.Investments, 2, "the investment stored in between is kept")
+	})
+
+	t.Run("AddInvestment agreement generation failed", func(t *testing.T) {
+		loan := &model.Loan{
+			ID:        2,
+			Principal: 1000,
+			State:     model.StateApproved,
+		}
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(loan, nil)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+		agreementMock.EXPECT().Generate(gomock.Any(), gomock.Any()).Return(nil, errors.New("disk full"))
+
+		got, err := uc.AddInvestment(context.Background(), 2, model.Investment{Amount: 1000})
+		assert.NoError(t, err, "the investment is stored, so it must not be retried")
+		assert.Equal(t, model.StateInvested, got.State)
 	})
 
 	t.Run("AddInvestment InvalidState", func(t *testing.T) {
@@ -101,18 +340,28 @@ func TestLoanUsecase(t *testing.T) {
 	t.Run("DisburseLoan Success", func(t *testing.T) {
 		loan := &model.Loan{ID: 3, State: model.StateInvested}
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(3)).Return(loan, nil)
-		repoMock.EXPECT().Update(gomock.Any(), loan).Return(nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(11)).Return(signedAgreement, nil)
+		repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
 
-		_, err := uc.DisburseLoan(context.Background(), 3, model.Disbursement{})
+		got, err := uc.DisburseLoan(context.Background(), 3, model.Disbursement{AgreementDocumentID: 11})
 		assert.NoError(t, err)
+		assert.Equal(t, model.StateDisbursed, got.State)
 	})
 
 	t.Run("DisburseLoan InvalidState", func(t *testing.T) {
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(3)).Return(&model.Loan{State: model.StateApproved}, nil)
-		_, err := uc.DisburseLoan(context.Background(), 3, model.Disbursement{})
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(11)).Return(signedAgreement, nil)
+		_, err := uc.DisburseLoan(context.Background(), 3, model.Disbursement{AgreementDocumentID: 11})
 		assert.ErrorContains(t, err, "can only disburse")
 	})
 
+	t.Run("DisburseLoan wrong document kind", func(t *testing.T) {
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateInvested}, nil)
+		documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(proof, nil)
+		_, err := uc.DisburseLoan(context.Background(), 1, model.Disbursement{AgreementDocumentID: 10})
+		assert.ErrorContains(t, err, "is not of kind signed_agreement")
+	})
+
 	t.Run("DisburseLoan loan not found", func(t *testing.T) {
 		repoMock.EXPECT().FindByID(gomock.Any(), int64(3)).Return(nil, errors.New("loan not found"))
 
@@ -120,3 +369,120 @@ func TestLoanUsecase(t *testing.T) {
 		assert.ErrorContains(t, err, "loan not found")
 	})
 }
+
+func TestLoanUsecaseMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := loanrepo.NewMockRepository(ctrl)
+	metricsMock := metricsmock.NewMockMetrics(ctrl)
+	uc := loan.NewUsecase(repoMock, documentrepo.NewMockRepository(ctrl), pubsubrepo.NewMock(logger.Discard()), agreementmock.NewMockUsecase(ctrl), metricsMock, logger.Discard())
+
+	t.Run("success", func(t *testing.T) {
+		repoMock.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+		metricsMock.EXPECT().ObserveOperation("create_loan", nil)
+
+		assert.NoError(t, uc.CreateLoan(context.Background(), &model.Loan{}))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		notFound := errors.New("loan not found")
+		repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(nil, notFound)
+		metricsMock.EXPECT().ObserveOperation("approve_loan", notFound)
+
+		_, err := uc.ApproveLoan(context.Background(), 1, model.Approval{})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoanUsecaseEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := loanrepo.NewMockRepository(ctrl)
+	documentMock := documentrepo.NewMockRepository(ctrl)
+	broker := pubsubrepo.NewBroker(pubsubrepo.NewMock(logger.Discard()))
+	uc := loan.NewUsecase(repoMock, documentMock, broker, agreementmock.NewMockUsecase(ctrl), metrics.NewNoop(), logger.Discard())
+
+	var events []model.LoanEvent
+	broker.Subscribe(model.TopicLoanEvents, func(_ context.Context, data []byte, _ map[string]string) {
+		var event model.LoanEvent
+		assert.NoError(t, json.Unmarshal(data, &event))
+		events = append(events, event)
+	})
+
+	repoMock.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, l *model.Loan) error {
+		l.ID = 1
+		return nil
+	})
+	assert.NoError(t, uc.CreateLoan(context.Background(), &model.Loan{PartnerID: 9}))
+
+	repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed, PartnerID: 9}, nil)
+	documentMock.EXPECT().FindByID(gomock.Any(), int64(10)).Return(&model.Document{ID: 10, LoanID: 1, Kind: model.DocumentKindApprovalProof}, nil)
+	repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+	_, err := uc.ApproveLoan(context.Background(), 1, model.Approval{ProofDocumentID: 10})
+	assert.NoError(t, err)
+
+	// a partial investment leaves the loan approved
+	repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateApproved, Principal: 100}, nil)
+	repoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+	_, err = uc.AddInvestment(context.Background(), 1, model.Investment{InvestorID: 7, Amount: 40})
+	assert.NoError(t, err)
+
+	// a failed transition announces nothing
+	repoMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1, State: model.StateProposed}, nil)
+	documentMock.EXPECT().FindByID(gomock.Any(), int64(11)).Return(&model.Document{ID: 11, LoanID: 1, Kind: model.DocumentKindSignedAgreement}, nil)
+	_, err = uc.DisburseLoan(context.Background(), 1, model.Disbursement{AgreementDocumentID: 11})
+	assert.Error(t, err)
+
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, model.LoanEventProposed, events[0].Type)
+		assert.Equal(t, model.LoanEventApproved, events[1].Type)
+		assert.Equal(t, model.LoanEventInvestmentAdded, events[2].Type)
+		assert.Len(t, events[2].Loan.Investments, 1)
+		assert.Equal(t, model.StateApproved, events[1].Loan.State)
+		assert.Equal(t, int64(9), events[1].Loan.PartnerID)
+		assert.Less(t, events[0].ID, events[1].ID)
+		assert.False(t, events[1].OccurredAt.IsZero())
+	}
+}
+
+func TestAddInvestmentConcurrently(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "loan.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := migration.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.TODO())
+	require.NoError(t, err)
+
+	repos := map[string]loanrepository.Repository{
+		"memory": loanrepository.NewRepository(logger.Discard()),
+		"sqlite": loanrepository.NewSQLRepository(db, logger.Discard()),
+	}
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			uc := loan.NewUsecase(repo, documentrepo.NewMockRepository(ctrl), pubsubrepo.NewMock(logger.Discard()), agreementmock.NewMockUsecase(ctrl), metrics.NewNoop(), logger.Discard())
+
+			approved := &model.Loan{Principal: 1000, State: model.StateApproved}
+			require.NoError(t, repo.Save(context.Background(), approved))
+
+			const investors = 100
+			var wg sync.WaitGroup
+			for i := range investors {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := uc.AddInvestment(context.Background(), approved.ID, model.Investment{InvestorID: int64(i + 1), Amount: 1})
+					assert.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			stored, err := repo.FindByID(context.Background(), approved.ID)
+			require.NoError(t, err)
+			assert.Len(t, stored.Investments, investors, "every investment that succeeded is stored")
+		})
+	}
+}
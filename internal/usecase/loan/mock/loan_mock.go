@@ -71,6 +71,21 @@ func (mr *MockUsecaseMockRecorder) ApproveLoan(ctx, loanID, approval any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveLoan", reflect.TypeOf((*MockUsecase)(nil).ApproveLoan), ctx, loanID, approval)
 }
 
+// ApproveLoans mocks base method.
+func (m *MockUsecase) ApproveLoans(ctx context.Context, items []model.BatchItem, approval model.Approval, atomic bool) ([]model.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveLoans", ctx, items, approval, atomic)
+	ret0, _ := ret[0].([]model.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveLoans indicates an expected call of ApproveLoans.
+func (mr *MockUsecaseMockRecorder) ApproveLoans(ctx, items, approval, atomic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveLoans", reflect.TypeOf((*MockUsecase)(nil).ApproveLoans), ctx, items, approval, atomic)
+}
+
 // CreateLoan mocks base method.
 func (m *MockUsecase) CreateLoan(ctx context.Context, loan *model.Loan) error {
 	m.ctrl.T.Helper()
@@ -85,6 +100,20 @@ func (mr *MockUsecaseMockRecorder) CreateLoan(ctx, loan any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoan", reflect.TypeOf((*MockUsecase)(nil).CreateLoan), ctx, loan)
 }
 
+// CreateLoans mocks base method.
+func (m *MockUsecase) CreateLoans(ctx context.Context, loans []*model.Loan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoans", ctx, loans)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLoans indicates an expected call of CreateLoans.
+func (mr *MockUsecaseMockRecorder) CreateLoans(ctx, loans any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoans", reflect.TypeOf((*MockUsecase)(nil).CreateLoans), ctx, loans)
+}
+
 // DisburseLoan mocks base method.
 func (m *MockUsecase) DisburseLoan(ctx context.Context, loanID int64, disbursement model.Disbursement) (*model.Loan, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +129,36 @@ func (mr *MockUsecaseMockRecorder) DisburseLoan(ctx, loanID, disbursement any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisburseLoan", reflect.TypeOf((*MockUsecase)(nil).DisburseLoan), ctx, loanID, disbursement)
 }
 
+// DisburseLoans mocks base method.
+func (m *MockUsecase) DisburseLoans(ctx context.Context, items []model.BatchItem, disbursement model.Disbursement, atomic bool) ([]model.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisburseLoans", ctx, items, disbursement, atomic)
+	ret0, _ := ret[0].([]model.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisburseLoans indicates an expected call of DisburseLoans.
+func (mr *MockUsecaseMockRecorder) DisburseLoans(ctx, items, disbursement, atomic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisburseLoans", reflect.TypeOf((*MockUsecase)(nil).DisburseLoans), ctx, items, disbursement, atomic)
+}
+
+// Find mocks base method.
+func (m *MockUsecase) Find(ctx context.Context, query model.LoanQuery) (model.LoanPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, query)
+	ret0, _ := ret[0].(model.LoanPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockUsecaseMockRecorder) Find(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUsecase)(nil).Find), ctx, query)
+}
+
 // FindAll mocks base method.
 func (m *MockUsecase) FindAll(ctx context.Context) ([]*model.Loan, error) {
 	m.ctrl.T.Helper()
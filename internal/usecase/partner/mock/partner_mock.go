@@ -0,0 +1,101 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: partner.go
+//
+// Generated by this command:
+//
+//	mockgen -source=partner.go -destination=mock/partner_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "loan_system/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsecase is a mock of Usecase interface.
+type MockUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockUsecaseMockRecorder is the mock recorder for MockUsecase.
+type MockUsecaseMockRecorder struct {
+	mock *MockUsecase
+}
+
+// NewMockUsecase creates a new mock instance.
+func NewMockUsecase(ctrl *gomock.Controller) *MockUsecase {
+	mock := &MockUsecase{ctrl: ctrl}
+	mock.recorder = &MockUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsecase) EXPECT() *MockUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUsecase) Create(ctx context.Context, partner *model.Partner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, partner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUsecaseMockRecorder) Create(ctx, partner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUsecase)(nil).Create), ctx, partner)
+}
+
+// IssueKey mocks base method.
+func (m *MockUsecase) IssueKey(ctx context.Context, partnerID int64, scopes []model.PartnerScope) (*model.PartnerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueKey", ctx, partnerID, scopes)
+	ret0, _ := ret[0].(*model.PartnerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueKey indicates an expected call of IssueKey.
+func (mr *MockUsecaseMockRecorder) IssueKey(ctx, partnerID, scopes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueKey", reflect.TypeOf((*MockUsecase)(nil).IssueKey), ctx, partnerID, scopes)
+}
+
+// Keys mocks base method.
+func (m *MockUsecase) Keys(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keys", ctx, partnerID)
+	ret0, _ := ret[0].([]*model.PartnerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Keys indicates an expected call of Keys.
+func (mr *MockUsecaseMockRecorder) Keys(ctx, partnerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*MockUsecase)(nil).Keys), ctx, partnerID)
+}
+
+// RevokeKey mocks base method.
+func (m *MockUsecase) RevokeKey(ctx context.Context, partnerID int64, keyID string) (*model.PartnerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeKey", ctx, partnerID, keyID)
+	ret0, _ := ret[0].(*model.PartnerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeKey indicates an expected call of RevokeKey.
+func (mr *MockUsecaseMockRecorder) RevokeKey(ctx, partnerID, keyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeKey", reflect.TypeOf((*MockUsecase)(nil).RevokeKey), ctx, partnerID, keyID)
+}
@@ -0,0 +1,121 @@
+package partner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/partner"
+)
+
+//go:generate mockgen -source=partner.go -destination=mock/partner_mock.go -package=mock
+type Usecase interface {
+	Create(ctx context.Context, partner *model.Partner) error
+	IssueKey(ctx context.Context, partnerID int64, scopes []model.PartnerScope) (*model.PartnerKey, error)
+	Keys(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error)
+	RevokeKey(ctx context.Context, partnerID int64, keyID string) (*model.PartnerKey, error)
+}
+
+type usecase struct {
+	repo partner.Repository
+	now  func() time.Time
+}
+
+func NewUsecase(repo partner.Repository) Usecase {
+	return &usecase{repo: repo, now: time.Now}
+}
+
+func (uc *usecase) Create(ctx context.Context, partner *model.Partner) error {
+	partner.CreatedAt = uc.now().UTC()
+	return uc.repo.Save(ctx, partner)
+}
+
+// IssueKey creates a key with a fresh random secret. The returned key is the
+// only place the secret is ever shown.
+func (uc *usecase) IssueKey(ctx context.Context, partnerID int64, scopes []model.PartnerScope) (*model.PartnerKey, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(model.PartnerScopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	if _, err := uc.repo.FindByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &model.PartnerKey{
+		ID:        "pk_" + id,
+		PartnerID: partnerID,
+		Secret:    secret,
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(scopes))),
+		CreatedAt: uc.now().UTC(),
+	}
+	if err := uc.repo.SaveKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Keys lists the partner's keys without their secrets.
+func (uc *usecase) Keys(ctx context.Context, partnerID int64) ([]*model.PartnerKey, error) {
+	if _, err := uc.repo.FindByID(ctx, partnerID); err != nil {
+		return nil, err
+	}
+
+	keys, err := uc.repo.FindKeysByPartnerID(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		key.Secret = ""
+	}
+
+	return keys, nil
+}
+
+func (uc *usecase) RevokeKey(ctx context.Context, partnerID int64, keyID string) (*model.PartnerKey, error) {
+	key, err := uc.repo.FindKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.PartnerID != partnerID {
+		return nil, errors.New("partner key not found")
+	}
+
+	if key.Active() {
+		revokedAt := uc.now().UTC()
+		key.RevokedAt = &revokedAt
+		if err := uc.repo.UpdateKey(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	key.Secret = ""
+	return key, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
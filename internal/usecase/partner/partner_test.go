@@ -0,0 +1,69 @@
+package partner_test
+
+import (
+	"context"
+	"testing"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	partnerRepository "loan_system/internal/repository/partner"
+	"loan_system/internal/usecase/partner"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartnerUsecase(t *testing.T) {
+	repo := partnerRepository.NewRepository(logger.Discard())
+	uc := partner.NewUsecase(repo)
+
+	p := &model.Partner{Name: "Koperasi Maju"}
+	assert.NoError(t, uc.Create(context.Background(), p))
+	assert.False(t, p.CreatedAt.IsZero())
+
+	var issued *model.PartnerKey
+
+	t.Run("IssueKey", func(t *testing.T) {
+		var err error
+		issued, err = uc.IssueKey(context.Background(), p.ID, []model.PartnerScope{model.PartnerScopeLoansInvest, model.PartnerScopeLoansCreate, model.PartnerScopeLoansInvest})
+		assert.NoError(t, err)
+		assert.Regexp(t, `^pk_[0-9a-f]{16}$`, issued.ID)
+		assert.Len(t, issued.Secret, 64)
+		assert.Equal(t, []model.PartnerScope{model.PartnerScopeLoansCreate, model.PartnerScopeLoansInvest}, issued.Scopes)
+
+		stored, err := repo.FindKey(context.Background(), issued.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, issued.Secret, stored.Secret)
+	})
+
+	t.Run("IssueKey rejects bad input", func(t *testing.T) {
+		_, err := uc.IssueKey(context.Background(), p.ID, nil)
+		assert.ErrorContains(t, err, "at least one scope")
+
+		_, err = uc.IssueKey(context.Background(), p.ID, []model.PartnerScope{"loans:delete"})
+		assert.ErrorContains(t, err, "unknown scope")
+
+		_, err = uc.IssueKey(context.Background(), 999999, []model.PartnerScope{model.PartnerScopeLoansCreate})
+		assert.ErrorContains(t, err, "partner not found")
+	})
+
+	t.Run("Keys hides secrets", func(t *testing.T) {
+		keys, err := uc.Keys(context.Background(), p.ID)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.Empty(t, keys[0].Secret)
+	})
+
+	t.Run("RevokeKey", func(t *testing.T) {
+		_, err := uc.RevokeKey(context.Background(), 999999, issued.ID)
+		assert.ErrorContains(t, err, "partner key not found")
+
+		revoked, err := uc.RevokeKey(context.Background(), p.ID, issued.ID)
+		assert.NoError(t, err)
+		assert.False(t, revoked.Active())
+		assert.Empty(t, revoked.Secret)
+
+		stored, err := repo.FindKey(context.Background(), issued.ID)
+		assert.NoError(t, err)
+		assert.False(t, stored.Active())
+	})
+}
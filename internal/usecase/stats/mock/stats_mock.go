@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stats.go
+//
+// Generated by this command:
+//
+//	mockgen -source=stats.go -destination=mock/stats_mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	model "loan_system/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsecase is a mock of Usecase interface.
+type MockUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockUsecaseMockRecorder is the mock recorder for MockUsecase.
+type MockUsecaseMockRecorder struct {
+	mock *MockUsecase
+}
+
+// NewMockUsecase creates a new mock instance.
+func NewMockUsecase(ctrl *gomock.Controller) *MockUsecase {
+	mock := &MockUsecase{ctrl: ctrl}
+	mock.recorder = &MockUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsecase) EXPECT() *MockUsecaseMockRecorder {
+	return m.recorder
+}
+
+// LoanStats mocks base method.
+func (m *MockUsecase) LoanStats(ctx context.Context, from, to time.Time, groupBy model.StatsGrouping) (*model.LoanStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoanStats", ctx, from, to, groupBy)
+	ret0, _ := ret[0].(*model.LoanStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoanStats indicates an expected call of LoanStats.
+func (mr *MockUsecaseMockRecorder) LoanStats(ctx, from, to, groupBy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoanStats", reflect.TypeOf((*MockUsecase)(nil).LoanStats), ctx, from, to, groupBy)
+}
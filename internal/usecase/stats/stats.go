@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/loan"
+)
+
+//go:generate mockgen -source=stats.go -destination=mock/stats_mock.go -package=mock
+type Usecase interface {
+	LoanStats(ctx context.Context, from, to time.Time, groupBy model.StatsGrouping) (*model.LoanStats, error)
+}
+
+type usecase struct {
+	loans loan.Repository
+}
+
+func NewUsecase(loans loan.Repository) Usecase {
+	return &usecase{loans: loans}
+}
+
+// LoanStats aggregates the loans created between from and to, both inclusive
+// and either left zero for an open range, one loan at a time.
+func (uc *usecase) LoanStats(ctx context.Context, from, to time.Time, groupBy model.StatsGrouping) (*model.LoanStats, error) {
+	query := model.LoanQuery{CreatedFrom: from, CreatedTo: to}
+
+	builder := model.NewLoanStatsBuilder(from, to, groupBy)
+	err := uc.loans.Each(ctx, query, func(loan *model.Loan) error {
+		builder.Add(loan)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.Stats(), nil
+}
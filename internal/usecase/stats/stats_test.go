@@ -0,0 +1,47 @@
+package stats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/repository/loan"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/usecase/stats"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatsUsecase(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("LoanStats reads every loan", func(t *testing.T) {
+		repo := loan.NewRepository(logger.Discard())
+		for i := 0; i < model.MaxLoanQueryLimit+5; i++ {
+			assert.NoError(t, repo.Save(context.Background(), &model.Loan{Principal: 100, State: model.StateProposed, CreatedAt: from.AddDate(0, 0, i)}))
+		}
+		assert.NoError(t, repo.Save(context.Background(), &model.Loan{Principal: 100, State: model.StateProposed, CreatedAt: to.AddDate(0, 0, 1)}))
+
+		result, err := stats.NewUsecase(repo).LoanStats(context.Background(), from, to, model.GroupByMonth)
+		assert.NoError(t, err)
+		assert.Equal(t, model.MaxLoanQueryLimit+5, result.Total.LoanCount)
+		assert.Equal(t, model.GroupByMonth, result.GroupBy)
+		assert.Len(t, result.Groups, 4)
+	})
+
+	t.Run("LoanStats repository error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repoMock := loanrepo.NewMockRepository(ctrl)
+		repoMock.EXPECT().Each(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+
+		_, err := stats.NewUsecase(repoMock).LoanStats(context.Background(), from, to, "")
+		assert.EqualError(t, err, "boom")
+	})
+}
@@ -0,0 +1,164 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/loan"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// it is dropped. Dropped subscribers reconnect and resume from their last
+// event ID.
+const subscriberBuffer = 64
+
+//go:generate mockgen -source=stream.go -destination=mock/stream_mock.go -package=mock
+type Usecase interface {
+	// Publish keeps event for replay and sends it to the subscribers
+	// watching its loan.
+	Publish(event model.LoanEvent)
+	// Subscribe watches the loan, or every loan when loanID is 0. When
+	// lastEventID is set, the buffered events after it are replayed first.
+	Subscribe(ctx context.Context, loanID, lastEventID int64) (*Subscription, error)
+	// Close ends every subscription and refuses new ones.
+	Close()
+}
+
+// Subscription delivers Replay and then Events, oldest first. Events is
+// closed when the subscriber falls too far behind or the stream is closed.
+type Subscription struct {
+	Replay []model.LoanEvent
+	Events <-chan model.LoanEvent
+	close  func()
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.close()
+}
+
+type subscriber struct {
+	loanID int64
+	events chan model.LoanEvent
+}
+
+type usecase struct {
+	loans loan.Repository
+	// replaySize caps the events kept for Last-Event-ID resume
+	replaySize int
+
+	mu          sync.Mutex
+	replay      []model.LoanEvent
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+func NewUsecase(loans loan.Repository, replaySize int) Usecase {
+	return &usecase{loans: loans, replaySize: replaySize, subscribers: make(map[*subscriber]struct{})}
+}
+
+func (uc *usecase) Publish(event model.LoanEvent) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.closed {
+		return
+	}
+	uc.replay = append(uc.replay, event)
+	if len(uc.replay) > uc.replaySize {
+		uc.replay = uc.replay[len(uc.replay)-uc.replaySize:]
+	}
+
+	for sub := range uc.subscribers {
+		if !sub.watches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// too slow to keep up; it resumes from its last event ID
+			uc.drop(sub)
+		}
+	}
+}
+
+func (uc *usecase) Subscribe(ctx context.Context, loanID, lastEventID int64) (*Subscription, error) {
+	if loanID != 0 {
+		if _, err := uc.loans.FindByID(ctx, loanID); err != nil {
+			return nil, err
+		}
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.closed {
+		return nil, errors.New("loan stream is closed")
+	}
+
+	sub := &subscriber{loanID: loanID, events: make(chan model.LoanEvent, subscriberBuffer)}
+	uc.subscribers[sub] = struct{}{}
+
+	return &Subscription{
+		Replay: uc.replayAfter(sub, lastEventID),
+		Events: sub.events,
+		close: func() {
+			uc.mu.Lock()
+			defer uc.mu.Unlock()
+			uc.drop(sub)
+		},
+	}, nil
+}
+
+func (uc *usecase) Close() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	uc.closed = true
+	for sub := range uc.subscribers {
+		uc.drop(sub)
+	}
+}
+
+// replayAfter returns the buffered events sub watches that were published
+// after lastEventID. Events are buffered in publish order, which IDs only
+// roughly follow, so the buffer is cut at that event while it is still
+// buffered, and by ID once it has been evicted.
+func (uc *usecase) replayAfter(sub *subscriber, lastEventID int64) []model.LoanEvent {
+	if lastEventID == 0 {
+		return nil
+	}
+
+	after := func(event model.LoanEvent) bool { return event.ID > lastEventID }
+	events := uc.replay
+	for i, event := range uc.replay {
+		if event.ID == lastEventID {
+			after = func(model.LoanEvent) bool { return true }
+			events = uc.replay[i+1:]
+			break
+		}
+	}
+
+	var replay []model.LoanEvent
+	for _, event := range events {
+		if after(event) && sub.watches(event) {
+			replay = append(replay, event)
+		}
+	}
+	return replay
+}
+
+// drop removes sub and closes its channel. The caller holds uc.mu.
+func (uc *usecase) drop(sub *subscriber) {
+	if _, ok := uc.subscribers[sub]; !ok {
+		return
+	}
+	delete(uc.subscribers, sub)
+	close(sub.events)
+}
+
+func (s *subscriber) watches(event model.LoanEvent) bool {
+	return s.loanID == 0 || (event.Loan != nil && event.Loan.ID == s.loanID)
+}
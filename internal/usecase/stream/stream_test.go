@@ -0,0 +1,122 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"loan_system/internal/model"
+	loanrepo "loan_system/internal/repository/loan/mock"
+	"loan_system/internal/usecase/stream"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func event(id, loanID int64) model.LoanEvent {
+	return model.LoanEvent{ID: id, Type: model.LoanEventInvestmentAdded, Loan: &model.Loan{ID: loanID}}
+}
+
+func ids(events []model.LoanEvent) []int64 {
+	var ids []int64
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestStreamUsecase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loanMock := loanrepo.NewMockRepository(ctrl)
+	uc := stream.NewUsecase(loanMock, 3)
+	ctx := context.Background()
+
+	t.Run("Subscribe filters by loan", func(t *testing.T) {
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(1)).Return(&model.Loan{ID: 1}, nil)
+
+		all, err := uc.Subscribe(ctx, 0, 0)
+		require.NoError(t, err)
+		defer all.Close()
+		one, err := uc.Subscribe(ctx, 1, 0)
+		require.NoError(t, err)
+		defer one.Close()
+		assert.Empty(t, all.Replay)
+
+		uc.Publish(event(10, 1))
+		uc.Publish(event(11, 2))
+
+		assert.Equal(t, int64(10), (<-all.Events).ID)
+		assert.Equal(t, int64(11), (<-all.Events).ID)
+		assert.Equal(t, int64(10), (<-one.Events).ID)
+		assert.Empty(t, one.Events)
+	})
+
+	t.Run("Subscribe unknown loan", func(t *testing.T) {
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(9)).Return(nil, errors.New("loan not found"))
+
+		_, err := uc.Subscribe(ctx, 9, 0)
+		assert.ErrorContains(t, err, "loan not found")
+	})
+
+	t.Run("Subscribe replays after the last event", func(t *testing.T) {
+		uc.Publish(event(12, 1))
+		uc.Publish(event(13, 2))
+		// the buffer now holds 11, 12 and 13
+
+		sub, err := uc.Subscribe(ctx, 0, 11)
+		require.NoError(t, err)
+		sub.Close()
+		assert.Equal(t, []int64{12, 13}, ids(sub.Replay))
+
+		loanMock.EXPECT().FindByID(gomock.Any(), int64(2)).Return(&model.Loan{ID: 2}, nil)
+		sub, err = uc.Subscribe(ctx, 2, 11)
+		require.NoError(t, err)
+		sub.Close()
+		assert.Equal(t, []int64{13}, ids(sub.Replay))
+
+		// 10 was evicted, so everything newer is replayed
+		sub, err = uc.Subscribe(ctx, 0, 10)
+		require.NoError(t, err)
+		sub.Close()
+		assert.Equal(t, []int64{11, 12, 13}, ids(sub.Replay))
+
+		// publish order wins over ID order while the event is buffered
+		uc.Publish(event(5, 1))
+		sub, err = uc.Subscribe(ctx, 0, 13)
+		require.NoError(t, err)
+		sub.Close()
+		assert.Equal(t, []int64{5}, ids(sub.Replay))
+	})
+
+	t.Run("Publish drops slow subscribers", func(t *testing.T) {
+		sub, err := uc.Subscribe(ctx, 0, 0)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		for i := int64(0); i < 100; i++ {
+			uc.Publish(event(100+i, 1))
+		}
+
+		received := 0
+		for range sub.Events {
+			received++
+		}
+		assert.Less(t, received, 100)
+	})
+
+	t.Run("Close ends subscriptions", func(t *testing.T) {
+		sub, err := uc.Subscribe(ctx, 0, 0)
+		require.NoError(t, err)
+
+		uc.Close()
+		_, open := <-sub.Events
+		assert.False(t, open)
+		sub.Close()
+
+		_, err = uc.Subscribe(ctx, 0, 0)
+		assert.ErrorContains(t, err, "closed")
+	})
+}
@@ -0,0 +1,365 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/repository/webhook"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256,
+// keyed with the subscription secret, of the timestamp, a dot and the body.
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// dispatchBatch caps how many due deliveries one Dispatch sends.
+const dispatchBatch = 100
+
+//go:generate mockgen -source=webhook.go -destination=mock/webhook_mock.go -package=mock
+type Usecase interface {
+	// Subscribe stores the subscription with a fresh secret. The returned
+	// subscription is the only place the secret is ever shown.
+	Subscribe(ctx context.Context, subscription *model.WebhookSubscription) error
+	// The methods below act on the partner's subscriptions and deliveries
+	// only, or on every partner's when partnerID is 0.
+	Subscriptions(ctx context.Context, partnerID int64) ([]*model.WebhookSubscription, error)
+	Subscription(ctx context.Context, partnerID, id int64) (*model.WebhookSubscription, error)
+	Unsubscribe(ctx context.Context, partnerID, id int64) error
+	Deliveries(ctx context.Context, query model.WebhookDeliveryQuery) ([]*model.WebhookDelivery, error)
+	Delivery(ctx context.Context, partnerID, id int64) (*model.WebhookDelivery, error)
+	// Redeliver queues a delivery to be sent again right away with a fresh
+	// set of attempts, such as one taken from the dead-letter list.
+	Redeliver(ctx context.Context, partnerID, id int64) (*model.WebhookDelivery, error)
+	// Enqueue queues a delivery of event for every subscription that wants it.
+	Enqueue(ctx context.Context, event model.LoanEvent) error
+	// Dispatch sends the deliveries that are due once.
+	Dispatch(ctx context.Context) error
+	// Run dispatches until ctx is done, whenever deliveries are queued and
+	// every poll interval for retries.
+	Run(ctx context.Context)
+}
+
+// Options sets how failed deliveries are retried. The first retry waits
+// Backoff, and each one after that twice as long as the last, up to
+// MaxBackoff. After MaxAttempts a delivery is dead-lettered. Up to
+// Concurrency deliveries are sent at once, so a slow receiver does not hold
+// up the others. AllowInsecure lets partners subscribe http URLs and
+// internal hosts, for local development.
+type Options struct {
+	MaxAttempts   int
+	Backoff       time.Duration
+	MaxBackoff    time.Duration
+	PollInterval  time.Duration
+	Concurrency   int
+	AllowInsecure bool
+}
+
+type usecase struct {
+	repo   webhook.Repository
+	sender webhook.Sender
+	opts   Options
+	log    *slog.Logger
+	now    func() time.Time
+	// wake nudges Run to dispatch without waiting for the next poll
+	wake chan struct{}
+}
+
+func NewUsecase(repo webhook.Repository, sender webhook.Sender, opts Options, log *slog.Logger) Usecase {
+	opts.Concurrency = max(opts.Concurrency, 1)
+	return &usecase{repo: repo, sender: sender, opts: opts, log: log, now: time.Now, wake: make(chan struct{}, 1)}
+}
+
+func (uc *usecase) Subscribe(ctx context.Context, subscription *model.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return fmt.Errorf("invalid webhook url %q", subscription.URL)
+	}
+	if !uc.opts.AllowInsecure {
+		if target.Scheme != "https" {
+			return fmt.Errorf("webhook url %q must use https", subscription.URL)
+		}
+		// the sender checks every address it dials; this only turns away
+		// what is plainly internal up front
+		host := target.Hostname()
+		addr, err := netip.ParseAddr(host)
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") || (err == nil && !webhook.IsPublic(addr)) {
+			return fmt.Errorf("webhook url %q must point to a public host", subscription.URL)
+		}
+	}
+	if len(subscription.EventTypes) == 0 {
+		return errors.New("at least one event type is required")
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !slices.Contains(model.LoanEventTypes, eventType) {
+			return fmt.Errorf("unknown event type %q", eventType)
+		}
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return err
+	}
+	subscription.EventTypes = slices.Compact(slices.Sorted(slices.Values(subscription.EventTypes)))
+	subscription.Secret = "whsec_" + secret
+	subscription.CreatedAt = uc.now().UTC()
+	return uc.repo.SaveSubscription(ctx, subscription)
+}
+
+// Subscriptions lists subscriptions without their secrets.
+func (uc *usecase) Subscriptions(ctx context.Context, partnerID int64) ([]*model.WebhookSubscription, error) {
+	subscriptions, err := uc.repo.FindSubscriptions(ctx, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (uc *usecase) Subscription(ctx context.Context, partnerID, id int64) (*model.WebhookSubscription, error) {
+	subscription, err := uc.repo.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if partnerID != 0 && subscription.PartnerID != partnerID {
+		return nil, errors.New("webhook subscription not found")
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (uc *usecase) Unsubscribe(ctx context.Context, partnerID, id int64) error {
+	if _, err := uc.Subscription(ctx, partnerID, id); err != nil {
+		return err
+	}
+	return uc.repo.DeleteSubscription(ctx, id)
+}
+
+func (uc *usecase) Deliveries(ctx context.Context, query model.WebhookDeliveryQuery) ([]*model.WebhookDelivery, error) {
+	return uc.repo.FindDeliveries(ctx, query)
+}
+
+func (uc *usecase) Delivery(ctx context.Context, partnerID, id int64) (*model.WebhookDelivery, error) {
+	delivery, err := uc.repo.FindDeliveryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if partnerID != 0 && delivery.PartnerID != partnerID {
+		return nil, errors.New("webhook delivery not found")
+	}
+
+	return delivery, nil
+}
+
+func (uc *usecase) Redeliver(ctx context.Context, partnerID, id int64) (*model.WebhookDelivery, error) {
+	delivery, err := uc.Delivery(ctx, partnerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == model.WebhookDeliveryPending {
+		return nil, errors.New("webhook delivery is already queued")
+	}
+
+	now := uc.now().UTC()
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.AttemptCount = 0
+	delivery.NextAttemptAt = &now
+	delivery.DeliveredAt = nil
+	if err := uc.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	uc.notify()
+	return delivery, nil
+}
+
+func (uc *usecase) Enqueue(ctx context.Context, event model.LoanEvent) error {
+	subscriptions, err := uc.repo.FindSubscriptions(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := uc.now().UTC()
+	queued := 0
+	for _, subscription := range subscriptions {
+		if !subscription.Wants(event) {
+			continue
+		}
+		delivery := &model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			PartnerID:      subscription.PartnerID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  &now,
+			CreatedAt:      now,
+		}
+		if err := uc.repo.SaveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		uc.notify()
+	}
+	return nil
+}
+
+func (uc *usecase) Dispatch(ctx context.Context) error {
+	due, err := uc.repo.FindDueDeliveries(ctx, uc.now().UTC(), dispatchBatch)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	slots := make(chan struct{}, uc.opts.Concurrency)
+	for _, delivery := range due {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := uc.attempt(ctx, delivery); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (uc *usecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := uc.Dispatch(ctx); err != nil {
+			uc.log.ErrorContext(ctx, "dispatch webhooks failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-uc.wake:
+		}
+	}
+}
+
+// attempt sends delivery once and records the outcome, scheduling a retry or
+// dead-lettering it when the receiver does not answer with a 2xx status.
+func (uc *usecase) attempt(ctx context.Context, delivery *model.WebhookDelivery) error {
+	now := uc.now().UTC()
+	attempt := model.WebhookAttempt{At: now}
+
+	subscription, err := uc.repo.FindSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		// nowhere left to send it
+		attempt.Error = err.Error()
+		delivery.AttemptCount = uc.opts.MaxAttempts
+	} else {
+		timestamp := now.Unix()
+		header := http.Header{}
+		header.Set("Content-Type", "application/json")
+		header.Set(HeaderID, strconv.FormatInt(delivery.ID, 10))
+		header.Set(HeaderEvent, string(delivery.EventType))
+		header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+		attempt.StatusCode, err = uc.sender.Send(ctx, subscription.URL, header, delivery.Payload)
+		switch {
+		case err != nil:
+			attempt.Error = err.Error()
+		case attempt.StatusCode < 200 || attempt.StatusCode > 299:
+			attempt.Error = fmt.Sprintf("receiver responded with status %d", attempt.StatusCode)
+		}
+		delivery.AttemptCount++
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.AttemptCount >= uc.opts.MaxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		uc.log.WarnContext(ctx, "webhook delivery dead-lettered", "delivery_id", delivery.ID, "attempts", delivery.AttemptCount, "error", attempt.Error)
+	default:
+		next := now.Add(uc.backoff(delivery.AttemptCount))
+		delivery.NextAttemptAt = &next
+	}
+
+	return uc.repo.UpdateDelivery(ctx, delivery)
+}
+
+// backoff is the wait after the given number of failed attempts.
+func (uc *usecase) backoff(attempts int) time.Duration {
+	wait := uc.opts.Backoff
+	for i := 1; i < attempts && wait < uc.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, uc.opts.MaxBackoff)
+}
+
+func (uc *usecase) notify() {
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Sign returns the signature receivers compare with the X-Webhook-Signature
+// header, computed from the X-Webhook-Timestamp header and the raw body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"loan_system/internal/model"
+	"loan_system/internal/pkg/logger"
+	"loan_system/internal/repository/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the requests it gets and answers with the queued status
+// codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhookUsecase(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	repo := webhook.NewRepository(logger.Discard())
+	// the receiver is a local http server
+	uc := NewUsecase(repo, webhook.NewHTTPSender(time.Second, true), Options{
+		MaxAttempts:   3,
+		Backoff:       10 * time.Second,
+		MaxBackoff:    15 * time.Second,
+		PollInterval:  time.Second,
+		AllowInsecure: true,
+	}, logger.Discard()).(*usecase)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	subscription := &model.WebhookSubscription{
+		PartnerID:  9,
+		URL:        server.URL,
+		EventTypes: []model.LoanEventType{model.LoanEventInvested, model.LoanEventApproved, model.LoanEventInvested},
+	}
+
+	t.Run("Subscribe", func(t *testing.T) {
+		assert.NoError(t, uc.Subscribe(ctx, subscription))
+		assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, subscription.Secret)
+		assert.Equal(t, []model.LoanEventType{model.LoanEventApproved, model.LoanEventInvested}, subscription.EventTypes)
+
+		listed, err := uc.Subscriptions(ctx, 9)
+		assert.NoError(t, err)
+		assert.Len(t, listed, 1)
+		assert.Empty(t, listed[0].Secret)
+
+		_, err = uc.Subscription(ctx, 8, subscription.ID)
+		assert.ErrorContains(t, err, "webhook subscription not found")
+	})
+
+	t.Run("Subscribe rejects bad input", func(t *testing.T) {
+		err := uc.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 9, URL: "ftp://partner.example", EventTypes: []model.LoanEventType{model.LoanEventApproved}})
+		assert.ErrorContains(t, err, "invalid webhook url")
+
+		err = uc.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 9, URL: server.URL})
+		assert.ErrorContains(t, err, "at least one event type")
+
+		err = uc.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 9, URL: server.URL, EventTypes: []model.LoanEventType{"loan.deleted"}})
+		assert.ErrorContains(t, err, "unknown event type")
+	})
+
+	t.Run("Subscribe requires a public https url", func(t *testing.T) {
+		secure := NewUsecase(repo, webhook.NewHTTPSender(time.Second, false), Options{MaxAttempts: 1}, logger.Discard())
+		events := []model.LoanEventType{model.LoanEventApproved}
+
+		for url, want := range map[string]string{
+			"http://partner.example/hooks":     "must use https",
+			"https://localhost/hooks":          "must point to a public host",
+			"https://10.0.0.5/hooks":           "must point to a public host",
+			"https://169.254.169.254/latest":   "must point to a public host",
+			"https://[::1]:8443/hooks":         "must point to a public host",
+			"https://api.localhost:8443/hooks": "must point to a public host",
+		} {
+			err := secure.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 9, URL: url, EventTypes: events})
+			assert.ErrorContains(t, err, want, url)
+		}
+
+		assert.NoError(t, secure.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 10, URL: "https://partner.example/hooks", EventTypes: events}))
+	})
+
+	event := model.LoanEvent{ID: 42, Type: model.LoanEventApproved, Loan: &model.Loan{ID: 7, PartnerID: 9}, OccurredAt: now}
+
+	t.Run("Enqueue skips unwanted events", func(t *testing.T) {
+		assert.NoError(t, uc.Enqueue(ctx, model.LoanEvent{ID: 40, Type: model.LoanEventDisbursed, Loan: &model.Loan{ID: 7, PartnerID: 9}}))
+		assert.NoError(t, uc.Enqueue(ctx, model.LoanEvent{ID: 41, Type: model.LoanEventApproved, Loan: &model.Loan{ID: 8, PartnerID: 8}}))
+
+		deliveries, err := uc.Deliveries(ctx, model.WebhookDeliveryQuery{})
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+
+	var delivery *model.WebhookDelivery
+
+	t.Run("Dispatch sends signed payload", func(t *testing.T) {
+		require.NoError(t, uc.Enqueue(ctx, event))
+		require.NoError(t, uc.Dispatch(ctx))
+
+		require.Len(t, rc.requests, 1)
+		request, body := rc.requests[0], rc.bodies[0]
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		assert.Equal(t, string(model.LoanEventApproved), request.Header.Get(HeaderEvent))
+		timestamp, err := strconv.ParseInt(request.Header.Get(HeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+		assert.Equal(t, now.Unix(), timestamp)
+		assert.Equal(t, Sign(subscription.Secret, timestamp, body), request.Header.Get(HeaderSignature))
+
+		var received model.LoanEvent
+		assert.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, int64(42), received.ID)
+		assert.Equal(t, int64(7), received.Loan.ID)
+
+		deliveries, err := uc.Deliveries(ctx, model.WebhookDeliveryQuery{PartnerID: 9})
+		assert.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		delivery = deliveries[0]
+		assert.Equal(t, strconv.FormatInt(delivery.ID, 10), request.Header.Get(HeaderID))
+		assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.AttemptCount)
+		assert.Equal(t, &now, delivery.DeliveredAt)
+		assert.Nil(t, delivery.NextAttemptAt)
+	})
+
+	t.Run("Dispatch retries with backoff then dead-letters", func(t *testing.T) {
+		rc.statuses = []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusBadGateway}
+		start := now
+
+		redelivered, err := uc.Redeliver(ctx, 9, delivery.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.WebhookDeliveryPending, redelivered.Status)
+		assert.Zero(t, redelivered.AttemptCount)
+
+		_, err = uc.Redeliver(ctx, 9, delivery.ID)
+		assert.ErrorContains(t, err, "already queued")
+
+		require.NoError(t, uc.Dispatch(ctx))
+		stored, _ := uc.Delivery(ctx, 9, delivery.ID)
+		assert.Equal(t, model.WebhookDeliveryPending, stored.Status)
+		assert.Equal(t, start.Add(10*time.Second), *stored.NextAttemptAt)
+		assert.Equal(t, "receiver responded with status 500", stored.Attempts[1].Error)
+
+		// not due yet
+		now = start.Add(5 * time.Second)
+		require.NoError(t, uc.Dispatch(ctx))
+		assert.Len(t, rc.requests, 2)
+
+		now = start.Add(10 * time.Second)
+		require.NoError(t, uc.Dispatch(ctx))
+		stored, _ = uc.Delivery(ctx, 9, delivery.ID)
+		// doubled backoff is capped at MaxBackoff
+		assert.Equal(t, now.Add(15*time.Second), *stored.NextAttemptAt)
+
+		now = now.Add(15 * time.Second)
+		require.NoError(t, uc.Dispatch(ctx))
+		stored, _ = uc.Delivery(ctx, 9, delivery.ID)
+		assert.Equal(t, model.WebhookDeliveryFailed, stored.Status)
+		assert.Equal(t, 3, stored.AttemptCount)
+		assert.Nil(t, stored.NextAttemptAt)
+		assert.Len(t, stored.Attempts, 4)
+		assert.Len(t, rc.requests, 4)
+
+		deadLetters, err := uc.Deliveries(ctx, model.WebhookDeliveryQuery{Status: model.WebhookDeliveryFailed})
+		assert.NoError(t, err)
+		assert.Len(t, deadLetters, 1)
+	})
+
+	t.Run("Redeliver from dead letters", func(t *testing.T) {
+		_, err := uc.Redeliver(ctx, 8, delivery.ID)
+		assert.ErrorContains(t, err, "webhook delivery not found")
+
+		_, err = uc.Redeliver(ctx, 0, delivery.ID)
+		require.NoError(t, err)
+		require.NoError(t, uc.Dispatch(ctx))
+
+		stored, _ := uc.Delivery(ctx, 9, delivery.ID)
+		assert.Equal(t, model.WebhookDeliverySucceeded, stored.Status)
+		assert.Len(t, stored.Attempts, 5)
+	})
+
+	t.Run("Unsubscribe dead-letters queued deliveries", func(t *testing.T) {
+		assert.ErrorContains(t, uc.Unsubscribe(ctx, 8, subscription.ID), "webhook subscription not found")
+
+		require.NoError(t, uc.Enqueue(ctx, model.LoanEvent{ID: 43, Type: model.LoanEventInvested, Loan: &model.Loan{ID: 7, PartnerID: 9}}))
+		require.NoError(t, uc.Unsubscribe(ctx, 9, subscription.ID))
+		require.NoError(t, uc.Dispatch(ctx))
+
+		deliveries, err := uc.Deliveries(ctx, model.WebhookDeliveryQuery{Status: model.WebhookDeliveryFailed})
+		assert.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, int64(43), deliveries[0].EventID)
+		assert.Len(t, rc.requests, 5)
+	})
+}
+
+// TestDispatchConcurrently has the receiver hold every request until all of
+// them have arrived, which only happens if they are sent at once.
+func TestDispatchConcurrently(t *testing.T) {
+	const deliveries = 3
+	var arrived sync.WaitGroup
+	arrived.Add(deliveries)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		arrived.Wait()
+	}))
+	defer server.Close()
+
+	repo := webhook.NewRepository(logger.Discard())
+	uc := NewUsecase(repo, webhook.NewHTTPSender(5*time.Second, true), Options{MaxAttempts: 1, Concurrency: deliveries, AllowInsecure: true}, logger.Discard())
+	ctx := context.Background()
+
+	for range deliveries {
+		assert.NoError(t, uc.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 9, URL: server.URL, EventTypes: []model.LoanEventType{model.LoanEventDisbursed}}))
+	}
+	assert.NoError(t, uc.Enqueue(ctx, model.LoanEvent{ID: 1, Type: model.LoanEventDisbursed, Loan: &model.Loan{ID: 7, PartnerID: 9}}))
+
+	assert.NoError(t, uc.Dispatch(ctx))
+	sent, err := uc.Deliveries(ctx, model.WebhookDeliveryQuery{Status: model.WebhookDeliverySucceeded})
+	assert.NoError(t, err)
+	assert.Len(t, sent, deliveries)
+}
+
+func TestRun(t *testing.T) {
+	delivered := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- r.Header.Get(HeaderEvent)
+	}))
+	defer server.Close()
+
+	repo := webhook.NewRepository(logger.Discard())
+	// a poll interval this long means only Enqueue can wake the worker
+	uc := NewUsecase(repo, webhook.NewHTTPSender(time.Second, true), Options{MaxAttempts: 1, PollInterval: time.Hour, AllowInsecure: true}, logger.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		uc.Run(ctx)
+		close(done)
+	}()
+
+	assert.NoError(t, uc.Subscribe(ctx, &model.WebhookSubscription{PartnerID: 9, URL: server.URL, EventTypes: []model.LoanEventType{model.LoanEventDisbursed}}))
+	assert.NoError(t, uc.Enqueue(ctx, model.LoanEvent{ID: 1, Type: model.LoanEventDisbursed, Loan: &model.Loan{ID: 7, PartnerID: 9}}))
+
+	select {
+	case event := <-delivered:
+		assert.Equal(t, string(model.LoanEventDisbursed), event)
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery was not sent")
+	}
+
+	cancel()
+	<-done
+}
@@ -1,20 +1,35 @@
 package main
 
 import (
+	"os"
+
+	"loan_system/cmd/client"
 	"loan_system/cmd/loan"
+	"loan_system/cmd/migrate"
+	"loan_system/cmd/token"
+	"loan_system/internal/pkg/config"
 
 	"github.com/spf13/cobra"
 )
 
 func main() {
+	var configFlags config.Flags
 	var rootCmd = &cobra.Command{
 		Use:   "Amartha Loan Service",
 		Short: "Amartha Loan Service",
 		Long:  `Backend Service for Amartha Loan Service Project`,
-		Run: func(cmd *cobra.Command, args []string) {
-			loan.Execute()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return loan.Execute(configFlags.Sources())
 		},
 	}
+	configFlags.Register(rootCmd.Flags())
+
+	rootCmd.AddCommand(client.NewCommand())
+	rootCmd.AddCommand(migrate.NewCommand())
+	rootCmd.AddCommand(token.NewCommand())
 
-	_ = rootCmd.Execute()
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }